@@ -0,0 +1,240 @@
+package accounts
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+)
+
+var (
+	// ErrUTXOFields is returned for a tx setting the inputs or outputs of the
+	// UTXO ledger, which the value and recipient take the place of
+	ErrUTXOFields = errors.New("tx sets inputs or outputs")
+	// ErrNoRecipient is returned for a tx paying nobody
+	ErrNoRecipient = errors.New("tx has no recipient")
+	// ErrZeroValue is returned for a tx moving no value
+	ErrZeroValue = errors.New("tx has no value")
+	// ErrNonce is returned for a tx not using the next nonce of its sender
+	ErrNonce = errors.New("tx does not use the next nonce of the sender")
+	// ErrInsufficientCredit is returned when the sender cannot cover the value
+	// plus fee of a tx with mature credit
+	ErrInsufficientCredit = errors.New("sender has insufficient credit")
+	// ErrOverflow is returned when the amounts of a tx cannot be summed
+	ErrOverflow = errors.New("tx amounts overflow")
+)
+
+// State holds the balance and next nonce of every address, as of the txs
+// applied so far. Credit paid by a block solve reward can only be spent once
+// mature. It is safe for concurrent use.
+type State interface {
+	// Balance returns the mature credit of the address as of the height
+	Balance(addr string, height uint64) uint64
+	// Immature returns the credit paid to the address by block solve rewards
+	// not yet mature as of the height
+	Immature(addr string, height uint64) uint64
+	// Nonce returns the nonce expected of the next tx sent by the address
+	Nonce(addr string) uint64
+	// CheckTx ensures the tx can be included in the block at the height: it
+	// pays somebody some value, uses the next nonce of its sender and is
+	// covered by the sender's mature credit. The state is left unchanged.
+	CheckTx(tx *pb.Tx, height uint64) error
+	// ApplyTx moves the value and fee of the tx, included in the block at the
+	// height. Txs must be applied in the order of the chain. Nothing is
+	// changed should the tx be invalid.
+	ApplyTx(tx *pb.Tx, height uint64) error
+}
+
+// New instantiates an empty State, in which the credit paid by block solve
+// rewards can only be spent coinbaseMaturity blocks after the one paying it
+func New(coinbaseMaturity uint64) State {
+	s := state{
+		balances:         make(map[string]uint64),
+		nonces:           make(map[string]uint64),
+		coinbaseMaturity: coinbaseMaturity,
+	}
+
+	return &s
+}
+
+// Restore instantiates a State holding the credit and next nonce of every
+// address, as counted over the blocks of a chain, without applying their txs
+// again. The credits count every block solve reward, so the rewards given,
+// those of the blocks too recent to have matured, are taken back out of them
+// to be held until mature, as when applied.
+func Restore(coinbaseMaturity uint64, credits map[string]uint64, nonces map[string]uint64, rewards []*pb.Tx) State {
+	s := state{
+		balances:         make(map[string]uint64, len(credits)),
+		nonces:           make(map[string]uint64, len(nonces)),
+		coinbaseMaturity: coinbaseMaturity,
+	}
+
+	for addr, credit := range credits {
+		s.balances[addr] = credit
+	}
+
+	for addr, nonce := range nonces {
+		s.nonces[addr] = nonce
+	}
+
+	for _, tx := range rewards {
+		s.balances[tx.GetRecipient()] -= tx.GetValue()
+		s.rewards = append(s.rewards, reward{
+			recipient: tx.GetRecipient(),
+			value:     tx.GetValue(),
+			height:    tx.GetHeight(),
+		})
+	}
+
+	sort.SliceStable(s.rewards, func(i, j int) bool {
+		return s.rewards[i].height < s.rewards[j].height
+	})
+
+	return &s
+}
+
+type state struct {
+	balances map[string]uint64
+	nonces   map[string]uint64
+	// rewards holds the credit paid by block solve rewards not yet counted in
+	// balances, lowest height first
+	rewards          []reward
+	coinbaseMaturity uint64
+	mu               sync.RWMutex
+}
+
+type reward struct {
+	recipient string
+	value     uint64
+	height    uint64
+}
+
+// IsCoinbase reports whether the tx is a block solve reward, creating credit
+// from thin air
+func IsCoinbase(tx *pb.Tx) bool {
+	return tx.GetSender() == ""
+}
+
+func (s *state) isMature(r reward, height uint64) bool {
+	return height >= r.height && height-r.height >= s.coinbaseMaturity
+}
+
+func (s *state) Balance(addr string, height uint64) uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	balance := s.balances[addr]
+	for _, r := range s.rewards {
+		if r.recipient == addr && s.isMature(r, height) {
+			balance += r.value
+		}
+	}
+
+	return balance
+}
+
+func (s *state) Immature(addr string, height uint64) uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var immature uint64
+	for _, r := range s.rewards {
+		if r.recipient == addr && !s.isMature(r, height) {
+			immature += r.value
+		}
+	}
+
+	return immature
+}
+
+func (s *state) Nonce(addr string) uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.nonces[addr]
+}
+
+func (s *state) CheckTx(tx *pb.Tx, height uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mature(height)
+
+	return s.checkTx(tx)
+}
+
+// mature moves the credit of the rewards mature as of the height into balances
+func (s *state) mature(height uint64) {
+	for len(s.rewards) > 0 && s.isMature(s.rewards[0], height) {
+		r := s.rewards[0]
+		s.balances[r.recipient] += r.value
+		s.rewards = s.rewards[1:]
+	}
+}
+
+func (s *state) checkTx(tx *pb.Tx) error {
+	if len(tx.GetInputs()) > 0 || len(tx.GetOutputs()) > 0 {
+		return ErrUTXOFields
+	}
+
+	if tx.GetRecipient() == "" {
+		return ErrNoRecipient
+	}
+
+	if IsCoinbase(tx) {
+		return nil
+	}
+
+	if tx.GetValue() == 0 {
+		return ErrZeroValue
+	}
+
+	if next := s.nonces[tx.GetSender()]; tx.GetNonce() != next {
+		return fmt.Errorf("%w: got %d, expected %d", ErrNonce, tx.GetNonce(), next)
+	}
+
+	total := tx.GetValue() + tx.GetFee()
+	if total < tx.GetValue() {
+		return ErrOverflow
+	}
+
+	if credit := s.balances[tx.GetSender()]; total > credit {
+		return fmt.Errorf("%w: spends %d, owns %d", ErrInsufficientCredit, total, credit)
+	}
+
+	if s.balances[tx.GetRecipient()]+tx.GetValue() < s.balances[tx.GetRecipient()] {
+		return ErrOverflow
+	}
+
+	return nil
+}
+
+func (s *state) ApplyTx(tx *pb.Tx, height uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mature(height)
+
+	if err := s.checkTx(tx); err != nil {
+		return err
+	}
+
+	if IsCoinbase(tx) {
+		s.rewards = append(s.rewards, reward{
+			recipient: tx.GetRecipient(),
+			value:     tx.GetValue(),
+			height:    height,
+		})
+		s.mature(height)
+
+		return nil
+	}
+
+	s.balances[tx.GetSender()] -= tx.GetValue() + tx.GetFee()
+	s.balances[tx.GetRecipient()] += tx.GetValue()
+	s.nonces[tx.GetSender()]++
+
+	return nil
+}
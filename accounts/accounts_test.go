@@ -0,0 +1,187 @@
+package accounts
+
+import (
+	"errors"
+	"testing"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+)
+
+// funded returns a state in which Gob was paid 100 at height 1, spendable from
+// height 2, and has sent one tx of 20 to Buster
+func funded(t *testing.T) State {
+	s := New(1)
+
+	if err := s.ApplyTx(&pb.Tx{Recipient: "Gob", Value: 100}, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.ApplyTx(&pb.Tx{Sender: "Gob", Recipient: "Buster", Value: 20}, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestCheckTx(t *testing.T) {
+	cases := []struct {
+		name string
+		tx   *pb.Tx
+		err  error
+	}{
+		{
+			name: "Spending owned credit with the next nonce is valid",
+			tx:   &pb.Tx{Sender: "Gob", Recipient: "Michael", Value: 70, Fee: 10, Nonce: 1},
+			err:  nil,
+		},
+		{
+			name: "Paying nobody is invalid",
+			tx:   &pb.Tx{Sender: "Gob", Value: 70, Nonce: 1},
+			err:  ErrNoRecipient,
+		},
+		{
+			name: "Paying no value is invalid",
+			tx:   &pb.Tx{Sender: "Gob", Recipient: "Michael", Fee: 1, Nonce: 1},
+			err:  ErrZeroValue,
+		},
+		{
+			name: "Reusing a nonce is invalid",
+			tx:   &pb.Tx{Sender: "Gob", Recipient: "Michael", Value: 70, Nonce: 0},
+			err:  ErrNonce,
+		},
+		{
+			name: "Skipping a nonce is invalid",
+			tx:   &pb.Tx{Sender: "Gob", Recipient: "Michael", Value: 70, Nonce: 2},
+			err:  ErrNonce,
+		},
+		{
+			name: "Spending more than is owned is invalid",
+			tx:   &pb.Tx{Sender: "Gob", Recipient: "Michael", Value: 80, Fee: 1, Nonce: 1},
+			err:  ErrInsufficientCredit,
+		},
+		{
+			name: "Spending credit of nobody is invalid",
+			tx:   &pb.Tx{Sender: "Lucille", Recipient: "Michael", Value: 1},
+			err:  ErrInsufficientCredit,
+		},
+		{
+			name: "A value and fee overflowing when summed are invalid",
+			tx:   &pb.Tx{Sender: "Gob", Recipient: "Michael", Value: ^uint64(0), Fee: 1, Nonce: 1},
+			err:  ErrOverflow,
+		},
+		{
+			name: "Setting outputs is invalid",
+			tx: &pb.Tx{
+				Sender:    "Gob",
+				Recipient: "Michael",
+				Value:     70,
+				Nonce:     1,
+				Outputs:   []*pb.TxOut{{Recipient: "Michael", Value: 70}},
+			},
+			err: ErrUTXOFields,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := funded(t)
+
+			err := s.CheckTx(c.tx, 3)
+
+			if !errors.Is(err, c.err) {
+				t.Errorf("expected %v, got %v", c.err, err)
+			}
+
+			if s.Balance("Gob", 3) != 80 || s.Nonce("Gob") != 1 {
+				t.Errorf("expected state to be unchanged")
+			}
+		})
+	}
+}
+
+func TestApplyTx(t *testing.T) {
+	s := funded(t)
+
+	spend := &pb.Tx{Sender: "Gob", Recipient: "Michael", Value: 70, Fee: 10, Nonce: 1}
+	if err := s.ApplyTx(spend, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	balances := map[string]uint64{
+		"Gob":     0,
+		"Michael": 70,
+		"Buster":  20,
+	}
+	for addr, expected := range balances {
+		if got := s.Balance(addr, 3); got != expected {
+			t.Errorf("expected %s to own %d, got %d", addr, expected, got)
+		}
+	}
+
+	if s.Nonce("Gob") != 2 {
+		t.Errorf("expected next nonce of 2, got %d", s.Nonce("Gob"))
+	}
+
+	if err := s.ApplyTx(spend, 3); !errors.Is(err, ErrNonce) {
+		t.Errorf("expected replayed tx to be refused, got %v", err)
+	}
+}
+
+func TestMaturity(t *testing.T) {
+	s := New(2)
+
+	if err := s.ApplyTx(&pb.Tx{Recipient: "Gob", Value: 100}, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	spend := &pb.Tx{Sender: "Gob", Recipient: "Michael", Value: 50}
+
+	if err := s.ApplyTx(spend, 2); !errors.Is(err, ErrInsufficientCredit) {
+		t.Errorf("expected reward to be immature in the next block, got %v", err)
+	}
+
+	if s.Balance("Gob", 2) != 0 || s.Immature("Gob", 2) != 100 {
+		t.Errorf("expected credit to be immature, got %d mature", s.Balance("Gob", 2))
+	}
+
+	if s.Balance("Gob", 3) != 100 || s.Immature("Gob", 3) != 0 {
+		t.Errorf("expected credit to be mature from height 3, got %d", s.Balance("Gob", 3))
+	}
+
+	if err := s.ApplyTx(spend, 3); err != nil {
+		t.Errorf("expected reward to be mature two blocks on, got %v", err)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	// Gob was paid 100 at height 1 and 50 at height 3, and has sent 20 to
+	// Buster at height 3. Counted over the three blocks, the reward of height
+	// 3 is still immature.
+	credits := map[string]uint64{"Gob": 130, "Buster": 20}
+	nonces := map[string]uint64{"Gob": 1}
+	rewards := []*pb.Tx{{Recipient: "Gob", Value: 50, Height: 3}}
+
+	s := Restore(2, credits, nonces, rewards)
+
+	if s.Balance("Gob", 4) != 80 || s.Immature("Gob", 4) != 50 {
+		t.Errorf("expected 80 mature and 50 immature, got %d and %d", s.Balance("Gob", 4), s.Immature("Gob", 4))
+	}
+
+	if s.Balance("Gob", 5) != 130 {
+		t.Errorf("expected the reward to be mature from height 5, got %d", s.Balance("Gob", 5))
+	}
+
+	spend := &pb.Tx{Sender: "Gob", Recipient: "Michael", Value: 100, Nonce: 1}
+
+	if err := s.ApplyTx(spend, 4); !errors.Is(err, ErrInsufficientCredit) {
+		t.Errorf("expected the immature reward to be unspendable, got %v", err)
+	}
+
+	if err := s.ApplyTx(spend, 5); err != nil {
+		t.Errorf("expected the reward to be spendable once mature, got %v", err)
+	}
+
+	if s.Nonce("Gob") != 2 {
+		t.Errorf("expected next nonce of 2, got %d", s.Nonce("Gob"))
+	}
+}
@@ -0,0 +1,230 @@
+package address
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Version identifies what the payload of an address commits to
+type Version byte
+
+const (
+	// VersionPubkey addresses carry an Ed25519 public key as their payload
+	VersionPubkey Version = 0
+	// VersionMultisig addresses carry the hash of an M-of-N set of public keys
+	VersionMultisig Version = 5
+)
+
+// MaxMultisigKeys is the largest number of keys a multisig address can be made of
+const MaxMultisigKeys = 16
+
+const checksumLen = 4
+
+var (
+	// ErrUnknownNetwork is returned for addresses without a recognised network prefix
+	ErrUnknownNetwork = errors.New("address has unknown network prefix")
+	// ErrChecksum is returned when an address has been mistyped or corrupted
+	ErrChecksum = errors.New("address checksum mismatch")
+	// ErrWrongNetwork is returned when an address belongs to a different network
+	ErrWrongNetwork = errors.New("address belongs to a different network")
+)
+
+// Network distinguishes addresses of separate blockchains, so that credit
+// cannot be sent to an address on the wrong one
+type Network struct {
+	Name   string
+	Prefix string
+}
+
+var (
+	// Mainnet is the network of real credit
+	Mainnet = &Network{
+		Name:   "mainnet",
+		Prefix: "blk",
+	}
+	// Testnet is for experimenting without consequence
+	Testnet = &Network{
+		Name:   "testnet",
+		Prefix: "tblk",
+	}
+	// Utxonet is a test network whose ledger is made of unspent tx outputs
+	Utxonet = &Network{
+		Name:   "utxonet",
+		Prefix: "ublk",
+	}
+)
+
+var networks = []*Network{Mainnet, Testnet, Utxonet}
+
+// NetworkByName looks up a network by its name, as given on the command line
+func NetworkByName(name string) (*Network, error) {
+	for _, net := range networks {
+		if net.Name == name {
+			return net, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown network %q", name)
+}
+
+// Address is the decoded form of an address string
+type Address struct {
+	Network *Network
+	Version Version
+	Payload []byte
+}
+
+// String encodes the address as its network prefix followed by the base58
+// encoding of the version, payload and checksum
+func (a Address) String() string {
+	b := append([]byte{byte(a.Version)}, a.Payload...)
+	b = append(b, checksum(a.Network.Prefix, b)...)
+
+	return a.Network.Prefix + base58Encode(b)
+}
+
+// Pubkey returns the public key carried by the address
+func (a Address) Pubkey() (ed25519.PublicKey, error) {
+	if a.Version != VersionPubkey {
+		return nil, fmt.Errorf("address version %d does not carry a public key", a.Version)
+	}
+
+	return ed25519.PublicKey(a.Payload), nil
+}
+
+// FromMultisig returns the address of the multisig account requiring threshold
+// signatures from the public keys
+func FromMultisig(threshold int, pubkeys []ed25519.PublicKey, net *Network) (string, error) {
+	hash, err := MultisigHash(threshold, pubkeys)
+	if err != nil {
+		return "", err
+	}
+
+	return Address{
+		Network: net,
+		Version: VersionMultisig,
+		Payload: hash,
+	}.String(), nil
+}
+
+// MultisigHash commits to the threshold and the set of public keys of a
+// multisig account. The keys are sorted first, so their order does not matter.
+func MultisigHash(threshold int, pubkeys []ed25519.PublicKey) ([]byte, error) {
+	if len(pubkeys) == 0 || len(pubkeys) > MaxMultisigKeys {
+		return nil, fmt.Errorf("multisig must have between 1 and %d keys, got %d", MaxMultisigKeys, len(pubkeys))
+	}
+
+	if threshold < 1 || threshold > len(pubkeys) {
+		return nil, fmt.Errorf("multisig threshold must be between 1 and %d, got %d", len(pubkeys), threshold)
+	}
+
+	sorted := make([]ed25519.PublicKey, len(pubkeys))
+	copy(sorted, pubkeys)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i], sorted[j]) < 0
+	})
+
+	preimage := []byte{byte(threshold)}
+	for i, pubkey := range sorted {
+		if len(pubkey) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("multisig key %d must be %d bytes, got %d", i, ed25519.PublicKeySize, len(pubkey))
+		}
+
+		if i > 0 && bytes.Equal(pubkey, sorted[i-1]) {
+			return nil, errors.New("multisig keys must be distinct")
+		}
+
+		preimage = append(preimage, pubkey...)
+	}
+
+	hash := sha256.Sum256(preimage)
+
+	return hash[:], nil
+}
+
+// FromPubkey returns the address for a public key on a network
+func FromPubkey(pubkey ed25519.PublicKey, net *Network) string {
+	return Address{
+		Network: net,
+		Version: VersionPubkey,
+		Payload: pubkey,
+	}.String()
+}
+
+// Decode parses an address string, verifying its checksum
+func Decode(s string) (Address, error) {
+	var net *Network
+	for _, n := range networks {
+		if strings.HasPrefix(s, n.Prefix) {
+			net = n
+			break
+		}
+	}
+
+	if net == nil {
+		return Address{}, ErrUnknownNetwork
+	}
+
+	b, err := base58Decode(strings.TrimPrefix(s, net.Prefix))
+	if err != nil {
+		return Address{}, fmt.Errorf("malformed address: %w", err)
+	}
+
+	if len(b) < 1+checksumLen {
+		return Address{}, errors.New("address too short")
+	}
+
+	body, sum := b[:len(b)-checksumLen], b[len(b)-checksumLen:]
+	if !bytes.Equal(sum, checksum(net.Prefix, body)) {
+		return Address{}, ErrChecksum
+	}
+
+	a := Address{
+		Network: net,
+		Version: Version(body[0]),
+		Payload: body[1:],
+	}
+
+	switch a.Version {
+	case VersionPubkey:
+		if len(a.Payload) != ed25519.PublicKeySize {
+			return Address{}, fmt.Errorf("pubkey address payload must be %d bytes, got %d", ed25519.PublicKeySize, len(a.Payload))
+		}
+	case VersionMultisig:
+		if len(a.Payload) != sha256.Size {
+			return Address{}, fmt.Errorf("multisig address payload must be %d bytes, got %d", sha256.Size, len(a.Payload))
+		}
+	default:
+		return Address{}, fmt.Errorf("unknown address version %d", a.Version)
+	}
+
+	return a, nil
+}
+
+// Validate decodes an address and checks that it belongs to the network
+func Validate(s string, net *Network) (Address, error) {
+	a, err := Decode(s)
+	if err != nil {
+		return Address{}, fmt.Errorf("invalid address %q: %w", s, err)
+	}
+
+	if a.Network != net {
+		return Address{}, fmt.Errorf("invalid address %q: %w (expected %s, got %s)", s, ErrWrongNetwork, net.Name, a.Network.Name)
+	}
+
+	return a, nil
+}
+
+// checksum commits to the network prefix as well as the body, so an address
+// can't be moved to another network by swapping its prefix
+func checksum(prefix string, body []byte) []byte {
+	first := sha256.Sum256(append([]byte(prefix), body...))
+	second := sha256.Sum256(first[:])
+
+	return second[:checksumLen]
+}
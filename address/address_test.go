@@ -0,0 +1,209 @@
+package address
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+)
+
+var pubkey = ed25519.PublicKey(bytes.Repeat([]byte{0xab}, ed25519.PublicKeySize))
+
+func TestBase58(t *testing.T) {
+	cases := []struct {
+		name    string
+		in      []byte
+		encoded string
+	}{
+		{
+			name:    "Empty input encodes to empty string",
+			in:      []byte{},
+			encoded: "",
+		},
+		{
+			name:    "Leading zero bytes are kept as leading ones",
+			in:      []byte{0, 0, 1},
+			encoded: "112",
+		},
+		{
+			name:    "Hello World",
+			in:      []byte("Hello World!"),
+			encoded: "2NEpo7TZRRrLZSi2U",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := base58Encode(c.in)
+			if got != c.encoded {
+				t.Errorf("expected %s, got %s", c.encoded, got)
+			}
+
+			decoded, err := base58Decode(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(decoded, c.in) {
+				t.Errorf("expected %v, got %v", c.in, decoded)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	valid := FromPubkey(pubkey, Mainnet)
+
+	// Swap a single character to emulate a typo
+	typo := []byte(valid)
+	if typo[10] == 'a' {
+		typo[10] = 'b'
+	} else {
+		typo[10] = 'a'
+	}
+
+	cases := []struct {
+		name     string
+		in       string
+		expected error
+	}{
+		{
+			name:     "An encoded pubkey decodes",
+			in:       valid,
+			expected: nil,
+		},
+		{
+			name:     "A single mistyped character fails the checksum",
+			in:       string(typo),
+			expected: ErrChecksum,
+		},
+		{
+			name:     "Swapping the network prefix fails the checksum",
+			in:       Testnet.Prefix + valid[len(Mainnet.Prefix):],
+			expected: ErrChecksum,
+		},
+		{
+			name:     "A bare hex pubkey has no network",
+			in:       "abababababababababababababababababababababababababababababababab",
+			expected: ErrUnknownNetwork,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a, err := Decode(c.in)
+			if !errors.Is(err, c.expected) {
+				t.Fatalf("expected %v, got %v", c.expected, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			got, err := a.Pubkey()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(got, pubkey) {
+				t.Errorf("expected %x, got %x", pubkey, got)
+			}
+		})
+	}
+}
+
+func TestDecodeBadPayloads(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+	}{
+		{
+			name: "Characters outside the base58 alphabet are rejected",
+			in:   "blk0OIl",
+		},
+		{
+			name: "An address too short for a checksum is rejected",
+			in:   "blk1",
+		},
+		{
+			name: "A pubkey payload of the wrong length is rejected",
+			in: Address{
+				Network: Mainnet,
+				Version: VersionPubkey,
+				Payload: []byte{1, 2, 3},
+			}.String(),
+		},
+		{
+			name: "An unknown version is rejected",
+			in: Address{
+				Network: Mainnet,
+				Version: Version(99),
+				Payload: pubkey,
+			}.String(),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := Decode(c.in); err == nil {
+				t.Errorf("expected error decoding %s", c.in)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if _, err := Validate(FromPubkey(pubkey, Mainnet), Mainnet); err != nil {
+		t.Errorf("expected mainnet address to validate on mainnet, got %v", err)
+	}
+
+	if _, err := Validate(FromPubkey(pubkey, Testnet), Mainnet); !errors.Is(err, ErrWrongNetwork) {
+		t.Errorf("expected %v, got %v", ErrWrongNetwork, err)
+	}
+}
+
+func TestFromMultisig(t *testing.T) {
+	a := ed25519.PublicKey(bytes.Repeat([]byte{1}, ed25519.PublicKeySize))
+	b := ed25519.PublicKey(bytes.Repeat([]byte{2}, ed25519.PublicKeySize))
+	c := ed25519.PublicKey(bytes.Repeat([]byte{3}, ed25519.PublicKeySize))
+
+	ordered, err := FromMultisig(2, []ed25519.PublicKey{a, b, c}, Mainnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shuffled, err := FromMultisig(2, []ed25519.PublicKey{c, a, b}, Mainnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ordered != shuffled {
+		t.Errorf("expected key order not to matter, got %s and %s", ordered, shuffled)
+	}
+
+	other, err := FromMultisig(1, []ed25519.PublicKey{a, b, c}, Mainnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if other == ordered {
+		t.Error("expected a different threshold to give a different address")
+	}
+
+	decoded, err := Decode(ordered)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Version != VersionMultisig {
+		t.Errorf("expected version %d, got %d", VersionMultisig, decoded.Version)
+	}
+
+	if _, err := FromMultisig(4, []ed25519.PublicKey{a, b, c}, Mainnet); err == nil {
+		t.Error("expected error for threshold above number of keys")
+	}
+
+	if _, err := FromMultisig(1, []ed25519.PublicKey{a, a}, Mainnet); err == nil {
+		t.Error("expected error for repeated keys")
+	}
+}
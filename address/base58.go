@@ -0,0 +1,66 @@
+package address
+
+import (
+	"errors"
+	"math/big"
+)
+
+// alphabet omits characters which are easily mistaken for one another: 0, O, I and l
+const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var bigRadix = big.NewInt(58)
+
+var decodeMap = func() [256]int {
+	var m [256]int
+	for i := range m {
+		m[i] = -1
+	}
+	for i, c := range alphabet {
+		m[c] = i
+	}
+	return m
+}()
+
+func base58Encode(b []byte) string {
+	x := new(big.Int).SetBytes(b)
+	mod := new(big.Int)
+
+	out := make([]byte, 0, len(b)*138/100+1)
+	for x.Sign() > 0 {
+		x.DivMod(x, bigRadix, mod)
+		out = append(out, alphabet[mod.Int64()])
+	}
+
+	// Leading zero bytes carry no numeric value, so are kept as leading '1's
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		out = append(out, alphabet[0])
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+
+	return string(out)
+}
+
+func base58Decode(s string) ([]byte, error) {
+	x := new(big.Int)
+	for i := 0; i < len(s); i++ {
+		d := decodeMap[s[i]]
+		if d < 0 {
+			return nil, errors.New("invalid base58 character")
+		}
+		x.Mul(x, bigRadix)
+		x.Add(x, big.NewInt(int64(d)))
+	}
+
+	var zeros int
+	for zeros < len(s) && s[zeros] == alphabet[0] {
+		zeros++
+	}
+
+	return append(make([]byte, zeros), x.Bytes()...), nil
+}
@@ -0,0 +1,154 @@
+package canonical
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+const (
+	// TxVersion is the version of the encoding written for new txs
+	TxVersion uint32 = 1
+	// HeaderVersion is the version of the encoding written for new block headers
+	HeaderVersion uint32 = 1
+)
+
+// Kinds of value encoded, written after the version so that no tx encoding
+// can be mistaken for a block header encoding
+const (
+	kindTx     byte = 't'
+	kindHeader byte = 'h'
+)
+
+// ErrUnknownVersion is returned for a tx or block header of an encoding
+// version this node does not know
+var ErrUnknownVersion = errors.New("unknown encoding version")
+
+// EncodeTx serializes the fields of the tx covered by its hash. Every field is
+// written at a fixed width, or prefixed by its length, in a fixed order, so no
+// two txs share an encoding. The hash and signatures are excluded, as they are
+// made over the encoding.
+//
+// Version 1 is laid out, with integers big-endian, as:
+//
+//	version   uint32
+//	kind      byte 't'
+//	timestamp timestamp
+//	sender    string
+//	recipient string
+//	value     uint64
+//	fee       uint64
+//	nonce     uint64
+//	message   string
+//	lock      uint64
+//	height    uint64
+//	inputs    uint32 count, then per input: txHash bytes, index uint32
+//	outputs   uint32 count, then per output: recipient string, value uint64
+//
+// where bytes and strings are a uint32 length followed by their contents, and
+// a timestamp is a presence byte followed, when 1, by seconds int64 and nanos
+// int32.
+func EncodeTx(tx *pb.Tx) ([]byte, error) {
+	if tx.GetVersion() != TxVersion {
+		return nil, fmt.Errorf("tx version %d: %w", tx.GetVersion(), ErrUnknownVersion)
+	}
+
+	var e encoder
+	e.uint32(tx.GetVersion())
+	e.byte(kindTx)
+	e.timestamp(tx.GetTimestamp())
+	e.string(tx.GetSender())
+	e.string(tx.GetRecipient())
+	e.uint64(tx.GetValue())
+	e.uint64(tx.GetFee())
+	e.uint64(tx.GetNonce())
+	e.string(tx.GetMessage())
+	e.uint64(tx.GetLock())
+	e.uint64(tx.GetHeight())
+
+	e.uint32(uint32(len(tx.GetInputs())))
+	for _, in := range tx.GetInputs() {
+		e.bytes(in.GetTxHash())
+		e.uint32(in.GetIndex())
+	}
+
+	e.uint32(uint32(len(tx.GetOutputs())))
+	for _, out := range tx.GetOutputs() {
+		e.string(out.GetRecipient())
+		e.uint64(out.GetValue())
+	}
+
+	return e.buf, nil
+}
+
+// EncodeHeader serializes the header of the block: every field but its txs,
+// which the merkle root covers.
+//
+// Version 1 is laid out, in the types of EncodeTx, as:
+//
+//	version    uint32
+//	kind       byte 'h'
+//	timestamp  timestamp
+//	prevhash   bytes
+//	nonce      uint64
+//	target     bytes
+//	merkleRoot bytes
+func EncodeHeader(b *pb.Block) ([]byte, error) {
+	if b.GetVersion() != HeaderVersion {
+		return nil, fmt.Errorf("block version %d: %w", b.GetVersion(), ErrUnknownVersion)
+	}
+
+	var e encoder
+	e.uint32(b.GetVersion())
+	e.byte(kindHeader)
+	e.timestamp(b.GetTimestamp())
+	e.bytes(b.GetPrevhash())
+	e.uint64(b.GetNonce())
+	e.bytes(b.GetTarget())
+	e.bytes(b.GetMerkleRoot())
+
+	return e.buf, nil
+}
+
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) byte(b byte) {
+	e.buf = append(e.buf, b)
+}
+
+func (e *encoder) uint32(v uint32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	e.buf = append(e.buf, b[:]...)
+}
+
+func (e *encoder) uint64(v uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	e.buf = append(e.buf, b[:]...)
+}
+
+func (e *encoder) bytes(v []byte) {
+	e.uint32(uint32(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+func (e *encoder) string(v string) {
+	e.bytes([]byte(v))
+}
+
+func (e *encoder) timestamp(ts *timestamp.Timestamp) {
+	if ts == nil {
+		e.byte(0)
+		return
+	}
+
+	e.byte(1)
+	e.uint64(uint64(ts.GetSeconds()))
+	e.uint32(uint32(ts.GetNanos()))
+}
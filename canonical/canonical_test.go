@@ -0,0 +1,174 @@
+package canonical
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+// The vectors below pin version 1 of the encoding. Any change to them breaks
+// the hash of every tx and block already made, so the encoding must instead
+// evolve under a new version.
+
+func TestEncodeTx(t *testing.T) {
+	cases := []struct {
+		name     string
+		tx       *pb.Tx
+		encoding string
+		hash     string
+	}{
+		{
+			name:     "A tx of no fields encodes its version and kind",
+			tx:       &pb.Tx{Version: 1},
+			encoding: "00000001" + "74" + "00" + "00000000" + "00000000" + "0000000000000000" + "0000000000000000" + "0000000000000000" + "00000000" + "0000000000000000" + "0000000000000000" + "00000000" + "00000000",
+			hash:     "76094573e6019884752ac3fae6dfb90ac32f0833633cc0c5454d1fff2502afd5",
+		},
+		{
+			name: "An account tx encodes its fields in order",
+			tx: &pb.Tx{
+				Version:   1,
+				Timestamp: &timestamp.Timestamp{Seconds: 646459200},
+				Sender:    "ab",
+				Recipient: "c",
+				Value:     100000000,
+				Fee:       2,
+				Nonce:     3,
+				Message:   "Her?",
+			},
+			encoding: "00000001" + "74" + "01" + "0000000026882f40" + "00000000" + "00000002" + "6162" + "00000001" + "63" + "0000000005f5e100" + "0000000000000002" + "0000000000000003" + "00000004" + "4865723f" + "0000000000000000" + "0000000000000000" + "00000000" + "00000000",
+			hash:     "eb7ba91e10b517fb6914caee285b77f135b528e5cf4989fe786dc9b2c5a165bc",
+		},
+		{
+			name: "A UTXO tx encodes its inputs and outputs",
+			tx: &pb.Tx{
+				Version:   1,
+				Timestamp: &timestamp.Timestamp{Seconds: 646459200, Nanos: 5},
+				Sender:    "Gob",
+				Inputs:    []*pb.OutPoint{{TxHash: []byte{0xbe, 0xef}, Index: 1}},
+				Outputs:   []*pb.TxOut{{Recipient: "Tobias", Value: 7}},
+				Lock:      10,
+			},
+			encoding: "00000001" + "74" + "01" + "0000000026882f40" + "00000005" + "00000003" + "476f62" + "00000000" + "0000000000000000" + "0000000000000000" + "0000000000000000" + "00000000" + "000000000000000a" + "0000000000000000" + "00000001" + "00000002" + "beef" + "00000001" + "00000001" + "00000006" + "546f62696173" + "0000000000000007",
+			hash:     "973847e11e3008342702b74c2ee4de5e880e59b52887578c6175e17c2146d4f8",
+		},
+		{
+			name: "A reward encodes its height",
+			tx: &pb.Tx{
+				Version:   1,
+				Recipient: "Gob",
+				Value:     100,
+				Height:    2,
+			},
+			encoding: "00000001" + "74" + "00" + "00000000" + "00000003" + "476f62" + "0000000000000064" + "0000000000000000" + "0000000000000000" + "00000000" + "0000000000000000" + "0000000000000002" + "00000000" + "00000000",
+			hash:     "a6a590a8ae78bfcceedc7d1692705d497bb798f4ac905ad94956033436707fbf",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := EncodeTx(c.tx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if hex.EncodeToString(got) != c.encoding {
+				t.Errorf("expected encoding %s, got %x", c.encoding, got)
+			}
+
+			if hash := sha256.Sum256(got); hex.EncodeToString(hash[:]) != c.hash {
+				t.Errorf("expected hash %s, got %x", c.hash, hash)
+			}
+		})
+	}
+}
+
+func TestEncodeHeader(t *testing.T) {
+	block := &pb.Block{
+		Version:    1,
+		Timestamp:  &timestamp.Timestamp{Seconds: 646459200},
+		Prevhash:   []byte{1, 2},
+		Nonce:      42,
+		Target:     []byte{0xff},
+		MerkleRoot: []byte{3},
+		// Txs are covered by the merkle root, so are not encoded
+		Txs: []*pb.Tx{{Version: 1}},
+	}
+
+	expected := "00000001" + "68" + "01" + "0000000026882f40" + "00000000" + "00000002" + "0102" + "000000000000002a" + "00000001" + "ff" + "00000001" + "03"
+
+	got, err := EncodeHeader(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hex.EncodeToString(got) != expected {
+		t.Errorf("expected encoding %s, got %x", expected, got)
+	}
+
+	if hash := sha256.Sum256(got); hex.EncodeToString(hash[:]) != "f9018ae0547e144c515676deac45974fa593fa82ce13a164ef3821778c50dad2" {
+		t.Errorf("unexpected hash %x", hash)
+	}
+}
+
+func TestEncodeTxUnambiguous(t *testing.T) {
+	cases := []struct {
+		name string
+		a, b *pb.Tx
+	}{
+		{
+			name: "Moving characters between sender and recipient changes the encoding",
+			a:    &pb.Tx{Version: 1, Sender: "ab", Recipient: "c"},
+			b:    &pb.Tx{Version: 1, Sender: "a", Recipient: "bc"},
+		},
+		{
+			name: "Moving characters between recipient and message changes the encoding",
+			a:    &pb.Tx{Version: 1, Recipient: "Ann", Message: "Egg"},
+			b:    &pb.Tx{Version: 1, Recipient: "AnnEgg"},
+		},
+		{
+			name: "Moving bytes between an input hash and the next changes the encoding",
+			a:    &pb.Tx{Version: 1, Inputs: []*pb.OutPoint{{TxHash: []byte{1, 2}}, {TxHash: []byte{3}}}},
+			b:    &pb.Tx{Version: 1, Inputs: []*pb.OutPoint{{TxHash: []byte{1}}, {TxHash: []byte{2, 3}}}},
+		},
+		{
+			name: "A missing timestamp differs from the zero time",
+			a:    &pb.Tx{Version: 1},
+			b:    &pb.Tx{Version: 1, Timestamp: &timestamp.Timestamp{}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a, err := EncodeTx(c.a)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := EncodeTx(c.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if bytes.Equal(a, b) {
+				t.Errorf("expected encodings to differ, both are %x", a)
+			}
+		})
+	}
+}
+
+func TestUnknownVersion(t *testing.T) {
+	for _, version := range []uint32{0, 2} {
+		if _, err := EncodeTx(&pb.Tx{Version: version}); !errors.Is(err, ErrUnknownVersion) {
+			t.Errorf("expected tx version %d to be unknown, got %v", version, err)
+		}
+
+		if _, err := EncodeHeader(&pb.Block{Version: version}); !errors.Is(err, ErrUnknownVersion) {
+			t.Errorf("expected block version %d to be unknown, got %v", version, err)
+		}
+	}
+}
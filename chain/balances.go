@@ -0,0 +1,226 @@
+package chain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"sync"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/golang/protobuf/proto"
+)
+
+// Balances holds the credit and next nonce of every address of a chain, kept
+// in line as blocks are connected to and disconnected from its tip, so reading
+// them does not rescan the chain. It is safe for concurrent use.
+type Balances interface {
+	// Credit returns the credit held by the address, in base units. As with
+	// GetCreditFor, an address having spent more than it received holds none.
+	Credit(addr string) uint64
+	// Nonce returns the nonce expected of the next tx sent by the address
+	Nonce(addr string) uint64
+	// Height returns the number of blocks connected
+	Height() uint64
+	// Tip returns the hash of the block connected last
+	Tip() []byte
+	// Addrs returns every address holding credit or having sent txs
+	Addrs() []string
+	// ConnectBlock counts the txs of the block, with the hash, on top of
+	// those of the blocks connected before it
+	ConnectBlock(block *Block, hash []byte)
+	// DisconnectBlock uncounts the txs of the block connected last, leaving
+	// the block before it as the tip
+	DisconnectBlock(block *Block) error
+	// Store writes the balances alongside the chain stored with the prefix
+	Store(filesPrefix string) error
+}
+
+// NewBalances instantiates Balances with no blocks connected
+func NewBalances() Balances {
+	return &balances{
+		pbb: &pb.Balances{
+			Balances: make(map[string]*pb.Balance),
+		},
+	}
+}
+
+// InitBalances returns the balances stored with the prefix, so long as they
+// were counted over the blocks of the chain up to their tip; otherwise none
+// are. The blocks of the chain beyond are left to be connected once validated.
+func InitBalances(c *Chain, filesPrefix string) Balances {
+	b, err := loadBalances(filesPrefix)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("counting balances from genesis: %s", err)
+		}
+		b = NewBalances()
+	}
+
+	if height := b.Height(); height > uint64(c.Length()) || (height > 0 && !bytes.Equal(c.Hash(height-1), b.Tip())) {
+		log.Println("stored balances are not of the chain, counting them from genesis")
+		b = NewBalances()
+	}
+
+	return b
+}
+
+type balances struct {
+	pbb *pb.Balances
+	mu  sync.RWMutex
+}
+
+func (b *balances) Credit(addr string) uint64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	balance := b.pbb.Balances[addr]
+	if balance.GetDebits() > balance.GetCredits() {
+		return 0
+	}
+
+	return balance.GetCredits() - balance.GetDebits()
+}
+
+func (b *balances) Nonce(addr string) uint64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.pbb.Balances[addr].GetSent()
+}
+
+func (b *balances) Height() uint64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.pbb.Height
+}
+
+func (b *balances) Tip() []byte {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.pbb.Tip
+}
+
+func (b *balances) Addrs() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	addrs := make([]string, 0, len(b.pbb.Balances))
+	for addr := range b.pbb.Balances {
+		addrs = append(addrs, addr)
+	}
+
+	return addrs
+}
+
+// balanceOf returns the balance of the address, adding it should it have none
+func (b *balances) balanceOf(addr string) *pb.Balance {
+	balance, ok := b.pbb.Balances[addr]
+	if !ok {
+		balance = &pb.Balance{}
+		b.pbb.Balances[addr] = balance
+	}
+
+	return balance
+}
+
+func (b *balances) ConnectBlock(block *Block, hash []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Applied as by validation: the sender pays the value and fee, and the
+	// recipient is credited the value, even should the two be one address
+	for _, tx := range block.ToProto().GetTxs() {
+		if tx.GetSender() != "" {
+			sender := b.balanceOf(tx.GetSender())
+			sender.Debits += tx.GetValue() + tx.GetFee()
+			sender.Sent++
+		}
+
+		if tx.GetRecipient() != "" {
+			b.balanceOf(tx.GetRecipient()).Credits += tx.GetValue()
+		}
+	}
+
+	b.pbb.Height++
+	b.pbb.Tip = hash
+}
+
+func (b *balances) DisconnectBlock(block *Block) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.pbb.Height == 0 {
+		return errors.New("no block is connected")
+	}
+
+	txs := block.ToProto().GetTxs()
+	for i := len(txs) - 1; i >= 0; i-- {
+		tx := txs[i]
+
+		if tx.GetRecipient() != "" {
+			b.balanceOf(tx.GetRecipient()).Credits -= tx.GetValue()
+			b.forgetEmpty(tx.GetRecipient())
+		}
+
+		if tx.GetSender() != "" {
+			sender := b.balanceOf(tx.GetSender())
+			sender.Debits -= tx.GetValue() + tx.GetFee()
+			sender.Sent--
+			b.forgetEmpty(tx.GetSender())
+		}
+	}
+
+	b.pbb.Height--
+	b.pbb.Tip = block.ToProto().GetPrevhash()
+
+	return nil
+}
+
+// forgetEmpty removes the balance of the address should nothing be left of it
+func (b *balances) forgetEmpty(addr string) {
+	if balance := b.pbb.Balances[addr]; proto.Equal(balance, &pb.Balance{}) {
+		delete(b.pbb.Balances, addr)
+	}
+}
+
+func (b *balances) Store(filesPrefix string) error {
+	b.mu.RLock()
+	pbb, err := proto.Marshal(b.pbb)
+	b.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("could not marshal balances: %w", err)
+	}
+
+	if err := writeFile(getStorageFnameBalances(filesPrefix), pbb); err != nil {
+		return fmt.Errorf("could not write to file: %w", err)
+	}
+
+	return nil
+}
+
+func loadBalances(filesPrefix string) (Balances, error) {
+	raw, err := ioutil.ReadFile(getStorageFnameBalances(filesPrefix))
+	if err != nil {
+		return nil, err
+	}
+
+	var pbb pb.Balances
+	if err := proto.Unmarshal(raw, &pbb); err != nil {
+		return nil, fmt.Errorf("could not unmarshal balances: %w", err)
+	}
+
+	if pbb.Balances == nil {
+		pbb.Balances = make(map[string]*pb.Balance)
+	}
+
+	return &balances{pbb: &pbb}, nil
+}
+
+func getStorageFnameBalances(filesPrefix string) string {
+	return fmt.Sprintf("/storage/%s_balances.proto", filesPrefix)
+}
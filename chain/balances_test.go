@@ -0,0 +1,101 @@
+package chain
+
+import (
+	"testing"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+)
+
+func TestBalances(t *testing.T) {
+	blocks := []*pb.Block{
+		{},
+		{
+			Txs: []*pb.Tx{
+				{Recipient: "Gob", Value: 50},
+			},
+		},
+		{
+			Txs: []*pb.Tx{
+				{Recipient: "Michael", Value: 50},
+				{Sender: "Gob", Recipient: "Lindsay", Value: 20, Fee: 2, Nonce: 0},
+				{Sender: "Gob", Recipient: "Gob", Value: 5, Fee: 3, Nonce: 1},
+			},
+		},
+		{
+			Txs: []*pb.Tx{
+				{Sender: "Lindsay", Recipient: "Maeby", Value: 30, Nonce: 0},
+				{Sender: "Michael", Recipient: "Gob", Value: 10, Fee: 1, Nonce: 0},
+			},
+		},
+	}
+
+	// credits are those held once each number of the blocks are connected. A
+	// sender pays the value and fee of its tx even when sending to itself, and
+	// one having spent more than it received holds none
+	credits := []map[string]uint64{
+		{},
+		{},
+		{"Gob": 50},
+		{"Gob": 25, "Michael": 50, "Lindsay": 20},
+		{"Gob": 35, "Michael": 39, "Maeby": 30},
+	}
+
+	addrs := []string{"Gob", "Michael", "Lindsay", "Maeby", "Buster"}
+	hasher := NewHasher()
+
+	// check compares the balances with the credits expected, and the nonces
+	// with a rescan of the chain of the blocks
+	check := func(t *testing.T, b Balances, blocks []*pb.Block) {
+		c := &Chain{Pbc: &pb.Chain{Blocks: blocks}}
+
+		if b.Height() != uint64(len(blocks)) {
+			t.Errorf("expected height %d, got %d", len(blocks), b.Height())
+		}
+
+		for _, addr := range addrs {
+			if expected, got := credits[len(blocks)][addr], b.Credit(addr); got != expected {
+				t.Errorf("expected credit of %s to be %d, got %d", addr, expected, got)
+			}
+
+			if expected, got := c.GetNonceFor(addr), b.Nonce(addr); got != expected {
+				t.Errorf("expected nonce of %s to be %d, got %d", addr, expected, got)
+			}
+		}
+	}
+
+	b := NewBalances()
+	for height, block := range blocks {
+		b.ConnectBlock((*Block)(block), hasher.Hash((*Block)(block)))
+		check(t, b, blocks[:height+1])
+	}
+
+	for height := len(blocks) - 1; height >= 0; height-- {
+		if err := b.DisconnectBlock((*Block)(blocks[height])); err != nil {
+			t.Fatal(err)
+		}
+		check(t, b, blocks[:height])
+	}
+
+	if err := b.DisconnectBlock((*Block)(blocks[0])); err == nil {
+		t.Errorf("expected no block to disconnect once all are")
+	}
+}
+
+func TestBalancesForgetEmpty(t *testing.T) {
+	block := &Block{
+		Txs: []*pb.Tx{
+			{Sender: "Tobias", Recipient: "Lucille", Value: 1},
+		},
+	}
+
+	b := NewBalances()
+	b.ConnectBlock(block, []byte("Buster"))
+
+	if err := b.DisconnectBlock(block); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := len(b.(*balances).pbb.GetBalances()); got != 0 {
+		t.Errorf("expected no balances left once the block is disconnected, got %d", got)
+	}
+}
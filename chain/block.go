@@ -1,10 +1,10 @@
 package chain
 
 import (
-	"crypto/sha256"
-
 	"github.com/golang/protobuf/ptypes"
 
+	"github.com/asgaines/blockchain/canonical"
+	"github.com/asgaines/blockchain/merkle"
 	pb "github.com/asgaines/blockchain/protogo/blockchain"
 )
 
@@ -13,37 +13,58 @@ type Block pb.Block
 
 // NewBlock instantiates a Block from a payload
 func NewBlock(hasher Hasher, prevHash []byte, txs []*pb.Tx, nonce uint64, target []byte, pubkey string) *Block {
-	txHashes := []byte{}
-	for _, tx := range txs {
-		txHashes = append(txHashes, tx.Hash...)
-	}
-
-	// A simplified merkle root of all transactions
-	merkleRoot := sha256.Sum256(txHashes)
-
 	b := &Block{
+		Version:    canonical.HeaderVersion,
 		Timestamp:  ptypes.TimestampNow(),
 		Prevhash:   prevHash,
 		Nonce:      nonce,
 		Target:     target,
-		MerkleRoot: merkleRoot[:],
+		MerkleRoot: merkle.Root(txs),
 		Txs:        txs,
 	}
 
 	return b
 }
 
+// GetMinerPubkey returns the address paid the block solve reward, the first tx
+// of the block
 func (b *Block) GetMinerPubkey() string {
-	var pubkey string
+	if len(b.Txs) == 0 || b.Txs[0].GetSender() != "" {
+		return ""
+	}
 
-	for _, tx := range b.Txs {
-		if tx.GetSender() == "" {
-			pubkey = tx.GetRecipient()
-			break
-		}
+	reward := b.Txs[0]
+	if len(reward.GetOutputs()) > 0 {
+		return reward.GetOutputs()[0].GetRecipient()
 	}
 
-	return pubkey
+	return reward.GetRecipient()
+}
+
+// Header returns the header of the block at the height: every field but its txs
+func (b *Block) Header(height uint64) *pb.BlockHeader {
+	return &pb.BlockHeader{
+		Version:    b.Version,
+		Height:     height,
+		Prevhash:   b.Prevhash,
+		MerkleRoot: b.MerkleRoot,
+		Timestamp:  b.Timestamp,
+		Target:     b.Target,
+		Nonce:      b.Nonce,
+	}
+}
+
+// FromHeader returns the block of the header, without its txs. It hashes to
+// the hash of the full block.
+func FromHeader(h *pb.BlockHeader) *Block {
+	return &Block{
+		Version:    h.GetVersion(),
+		Prevhash:   h.GetPrevhash(),
+		MerkleRoot: h.GetMerkleRoot(),
+		Timestamp:  h.GetTimestamp(),
+		Target:     h.GetTarget(),
+		Nonce:      h.GetNonce(),
+	}
 }
 
 func (b *Block) ToProto() *pb.Block {
@@ -4,12 +4,23 @@ import (
 	"encoding/json"
 	"log"
 
+	"github.com/asgaines/blockchain/canonical"
 	"github.com/asgaines/blockchain/protogo/blockchain"
 	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/asgaines/blockchain/transactions"
 )
 
 type Chain struct {
 	Pbc *pb.Chain
+	// Hasher hashes the blocks of the chain to index them by hash. The default
+	// hasher is used when none is set
+	Hasher Hasher `json:"-"`
+	// LegacyHeight is the height of the last block of legacy form, as found by
+	// MarkLegacy in a stored chain. It is 0 for chains never stored, in which
+	// no block may be of legacy form.
+	LegacyHeight uint64 `json:"-"`
+
+	index index
 }
 
 func NewChain(hasher Hasher) *Chain {
@@ -28,6 +39,7 @@ func NewChain(hasher Hasher) *Chain {
 				genesis.ToProto(),
 			},
 		},
+		Hasher: hasher,
 	}
 
 	return &chain
@@ -37,27 +49,34 @@ func (bc *Chain) ToProto() *pb.Chain {
 	return bc.Pbc
 }
 
-func (bc Chain) WithBlock(block *Block) *Chain {
+// WithBlock returns the chain extended by the block. The blocks of the chain
+// already indexed stay indexed in the new one. The chain itself is left as it
+// is, so it can be extended by another block as a branch of its own.
+func (bc *Chain) WithBlock(block *Block) *Chain {
+	blocks := make([]*pb.Block, 0, len(bc.Pbc.Blocks)+1)
+
 	return &Chain{
 		Pbc: &blockchain.Chain{
-			Blocks: append(bc.Pbc.Blocks, block.ToProto()),
+			Blocks: append(append(blocks, bc.Pbc.Blocks...), block.ToProto()),
 		},
+		Hasher: bc.Hasher,
+		index:  bc.index.copy(),
 	}
 }
 
-func (bc Chain) BlockByIdx(idx int) *Block {
+func (bc *Chain) BlockByIdx(idx int) *Block {
 	return (*Block)(bc.Pbc.Blocks[idx])
 }
 
-func (bc Chain) LastLink() *Block {
+func (bc *Chain) LastLink() *Block {
 	return bc.BlockByIdx(len(bc.Pbc.Blocks) - 1)
 }
 
-func (bc Chain) Length() int {
+func (bc *Chain) Length() int {
 	return len(bc.Pbc.Blocks)
 }
 
-func (bc Chain) ToJSON() []byte {
+func (bc *Chain) ToJSON() []byte {
 	j, err := json.Marshal(bc)
 	if err != nil {
 		log.Fatal(err)
@@ -66,18 +85,93 @@ func (bc Chain) ToJSON() []byte {
 	return j
 }
 
-func (bc *Chain) GetCreditFor(pubkey string) float64 {
-	credit := float64(0)
+// GetCreditFor returns the credit held by the address, in base units. Should the
+// address have spent more than it received, it is left with no credit.
+func (bc *Chain) GetCreditFor(pubkey string) uint64 {
+	var credits, debits uint64
 
 	for _, block := range bc.Pbc.GetBlocks() {
 		for _, tx := range block.GetTxs() {
+			// A tx sent to its own sender still pays its fee
 			if tx.GetRecipient() == pubkey {
-				credit += tx.GetValue()
-			} else if tx.GetSender() == pubkey {
-				credit -= tx.GetValue()
+				credits += tx.GetValue()
+			}
+			if tx.GetSender() == pubkey {
+				debits += tx.GetValue() + tx.GetFee()
+			}
+		}
+	}
+
+	if debits > credits {
+		return 0
+	}
+
+	return credits - debits
+}
+
+// GetImmatureFor returns the credit paid to the address by the block solve
+// rewards of the blocks from the height since onwards, in base units
+func (bc *Chain) GetImmatureFor(addr string, since uint64) uint64 {
+	var immature uint64
+
+	blocks := bc.Pbc.GetBlocks()
+	for height := since; height < uint64(len(blocks)); height++ {
+		for _, tx := range blocks[height].GetTxs() {
+			if tx.GetSender() == "" && tx.GetRecipient() == addr {
+				immature += tx.GetValue()
+			}
+		}
+	}
+
+	return immature
+}
+
+// MigrateAmounts converts the amounts of txs recorded in whole coins, by chains
+// stored before amounts were counted in base units. It returns the number of
+// txs migrated.
+func (bc *Chain) MigrateAmounts() int {
+	migrated := 0
+
+	for _, block := range bc.Pbc.GetBlocks() {
+		for _, tx := range block.GetTxs() {
+			if transactions.Migrate(tx) {
+				migrated++
+			}
+		}
+	}
+
+	return migrated
+}
+
+// MarkLegacy records as LegacyHeight the height of the last block written in a
+// form from before the current ones: a header of version 0, hashed without
+// committing to its txs, or txs of version 0 or of whole coin amounts.
+func (bc *Chain) MarkLegacy() {
+	for height, block := range bc.Pbc.GetBlocks() {
+		if height > 0 && block.GetVersion() < canonical.HeaderVersion {
+			bc.LegacyHeight = uint64(height)
+		}
+
+		for _, tx := range block.GetTxs() {
+			if transactions.IsLegacy(tx) || tx.GetVersion() < canonical.TxVersion {
+				bc.LegacyHeight = uint64(height)
+			}
+		}
+	}
+}
+
+// GetNonceFor returns the nonce expected of the next tx sent by the address:
+// the number of txs it has sent within the chain
+func (bc *Chain) GetNonceFor(addr string) uint64 {
+	var nonce uint64
+
+	for _, block := range bc.Pbc.GetBlocks() {
+		for _, tx := range block.GetTxs() {
+			if tx.GetSender() == addr {
+				nonce++
 			}
 		}
 	}
 
-	return credit
+	return nonce
 }
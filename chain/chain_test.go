@@ -4,7 +4,9 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/asgaines/blockchain/canonical"
 	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/asgaines/blockchain/transactions"
 )
 
 func TestLastLink(t *testing.T) {
@@ -121,7 +123,7 @@ func TestGetCreditFor(t *testing.T) {
 		name     string
 		chain    *Chain
 		pubkey   string
-		expected float64
+		expected uint64
 	}{
 		{
 			name: "An empty chain will have no credit",
@@ -142,7 +144,7 @@ func TestGetCreditFor(t *testing.T) {
 							Txs: []*pb.Tx{
 								{
 									Recipient: "SteveHolt",
-									Value:     5.5,
+									Value:     550,
 								},
 							},
 						},
@@ -150,7 +152,7 @@ func TestGetCreditFor(t *testing.T) {
 				},
 			},
 			pubkey:   "SteveHolt",
-			expected: 5.5,
+			expected: 550,
 		},
 		{
 			name: "A single tx with different pubkey does not credit the key",
@@ -161,7 +163,7 @@ func TestGetCreditFor(t *testing.T) {
 							Txs: []*pb.Tx{
 								{
 									Recipient: "GeorgeMichael",
-									Value:     12.5,
+									Value:     1250,
 								},
 							},
 						},
@@ -180,11 +182,11 @@ func TestGetCreditFor(t *testing.T) {
 							Txs: []*pb.Tx{
 								{
 									Recipient: "LucilleBluth",
-									Value:     12.5,
+									Value:     1250,
 								},
 								{
 									Recipient: "LucilleBluth",
-									Value:     100,
+									Value:     10000,
 								},
 							},
 						},
@@ -192,7 +194,7 @@ func TestGetCreditFor(t *testing.T) {
 				},
 			},
 			pubkey:   "LucilleBluth",
-			expected: 112.5,
+			expected: 11250,
 		},
 		{
 			name: "Credits and debits are balanced against one another for the final difference",
@@ -203,7 +205,7 @@ func TestGetCreditFor(t *testing.T) {
 							Txs: []*pb.Tx{
 								{
 									Recipient: "Rita",
-									Value:     70,
+									Value:     7000,
 								},
 							},
 						},
@@ -211,7 +213,7 @@ func TestGetCreditFor(t *testing.T) {
 							Txs: []*pb.Tx{
 								{
 									Sender: "Rita",
-									Value:  50,
+									Value:  5000,
 								},
 							},
 						},
@@ -219,7 +221,31 @@ func TestGetCreditFor(t *testing.T) {
 				},
 			},
 			pubkey:   "Rita",
-			expected: 20,
+			expected: 2000,
+		},
+		{
+			name: "Fees are debited from the sender along with the value",
+			chain: &Chain{
+				Pbc: &pb.Chain{
+					Blocks: []*pb.Block{
+						{
+							Txs: []*pb.Tx{
+								{
+									Recipient: "Rita",
+									Value:     7000,
+								},
+								{
+									Sender: "Rita",
+									Value:  5000,
+									Fee:    500,
+								},
+							},
+						},
+					},
+				},
+			},
+			pubkey:   "Rita",
+			expected: 1500,
 		},
 		{
 			name: "Two matching transactions in 2 different blocks are added together for matching key",
@@ -230,7 +256,7 @@ func TestGetCreditFor(t *testing.T) {
 							Txs: []*pb.Tx{
 								{
 									Recipient: "MrF",
-									Value:     2.5,
+									Value:     250,
 								},
 							},
 						},
@@ -238,7 +264,7 @@ func TestGetCreditFor(t *testing.T) {
 							Txs: []*pb.Tx{
 								{
 									Recipient: "MrF",
-									Value:     5,
+									Value:     500,
 								},
 							},
 						},
@@ -246,7 +272,7 @@ func TestGetCreditFor(t *testing.T) {
 				},
 			},
 			pubkey:   "MrF",
-			expected: 7.5,
+			expected: 750,
 		},
 		{
 			name: "Many blocks with many transactions only return the accumulation of the matching key",
@@ -257,11 +283,11 @@ func TestGetCreditFor(t *testing.T) {
 							Txs: []*pb.Tx{
 								{
 									Recipient: "LindsayBluth",
-									Value:     100,
+									Value:     10000,
 								},
 								{
 									Recipient: "DirtyEarsBill",
-									Value:     8,
+									Value:     800,
 								},
 							},
 						},
@@ -269,7 +295,7 @@ func TestGetCreditFor(t *testing.T) {
 							Txs: []*pb.Tx{
 								{
 									Recipient: "LindsayBluth",
-									Value:     5,
+									Value:     500,
 								},
 							},
 						},
@@ -277,7 +303,7 @@ func TestGetCreditFor(t *testing.T) {
 							Txs: []*pb.Tx{
 								{
 									Recipient: "Gob",
-									Value:     35,
+									Value:     3500,
 								},
 							},
 						},
@@ -285,11 +311,11 @@ func TestGetCreditFor(t *testing.T) {
 							Txs: []*pb.Tx{
 								{
 									Recipient: "Hermano",
-									Value:     7,
+									Value:     700,
 								},
 								{
 									Sender: "LindsayBluth",
-									Value:  35,
+									Value:  3500,
 								},
 							},
 						},
@@ -297,7 +323,36 @@ func TestGetCreditFor(t *testing.T) {
 				},
 			},
 			pubkey:   "LindsayBluth",
-			expected: 70,
+			expected: 7000,
+		},
+		{
+			name: "A tx sent to its own sender pays its fee",
+			chain: &Chain{
+				Pbc: &pb.Chain{
+					Blocks: []*pb.Block{
+						{
+							Txs: []*pb.Tx{
+								{
+									Recipient: "Tobias",
+									Value:     1000,
+								},
+							},
+						},
+						{
+							Txs: []*pb.Tx{
+								{
+									Sender:    "Tobias",
+									Recipient: "Tobias",
+									Value:     300,
+									Fee:       10,
+								},
+							},
+						},
+					},
+				},
+			},
+			pubkey:   "Tobias",
+			expected: 990,
 		},
 	}
 
@@ -311,3 +366,153 @@ func TestGetCreditFor(t *testing.T) {
 		})
 	}
 }
+
+func TestGetNonceFor(t *testing.T) {
+	cases := []struct {
+		name     string
+		chain    *Chain
+		addr     string
+		expected uint64
+	}{
+		{
+			name: "An address which has sent nothing expects nonce 0",
+			chain: &Chain{
+				Pbc: &pb.Chain{
+					Blocks: []*pb.Block{
+						{
+							Txs: []*pb.Tx{
+								{
+									Recipient: "Kitty",
+									Value:     10,
+								},
+							},
+						},
+					},
+				},
+			},
+			addr:     "Kitty",
+			expected: 0,
+		},
+		{
+			name: "Each tx sent across blocks increments the expected nonce",
+			chain: &Chain{
+				Pbc: &pb.Chain{
+					Blocks: []*pb.Block{
+						{
+							Txs: []*pb.Tx{
+								{
+									Sender: "Kitty",
+									Nonce:  0,
+								},
+								{
+									Sender: "Barry",
+									Nonce:  0,
+								},
+							},
+						},
+						{
+							Txs: []*pb.Tx{
+								{
+									Sender: "Kitty",
+									Nonce:  1,
+								},
+							},
+						},
+					},
+				},
+			},
+			addr:     "Kitty",
+			expected: 2,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := c.chain.GetNonceFor(c.addr)
+
+			if got != c.expected {
+				t.Errorf("expected %v, got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestMigrateAmounts(t *testing.T) {
+	legacy := &pb.Tx{LegacyValue: 12.5, LegacyFee: 0.1}
+	current := &pb.Tx{Value: 7}
+
+	c := &Chain{
+		Pbc: &pb.Chain{
+			Blocks: []*pb.Block{
+				{
+					Txs: []*pb.Tx{legacy, current},
+				},
+			},
+		},
+	}
+
+	if migrated := c.MigrateAmounts(); migrated != 1 {
+		t.Errorf("expected 1 tx migrated, got %v", migrated)
+	}
+
+	if legacy.GetValue() != 1250000000 || legacy.GetFee() != 10000000 {
+		t.Errorf("expected legacy amounts converted to base units, got %v", legacy)
+	}
+
+	if current.GetValue() != 7 {
+		t.Errorf("expected current tx untouched, got %v", current)
+	}
+
+	if migrated := c.MigrateAmounts(); migrated != 0 {
+		t.Errorf("expected migration to be idempotent, got %v migrated", migrated)
+	}
+}
+
+func TestMarkLegacy(t *testing.T) {
+	current := func(txs ...*pb.Tx) *pb.Block {
+		return &pb.Block{Version: canonical.HeaderVersion, Txs: txs}
+	}
+
+	cases := []struct {
+		name     string
+		blocks   []*pb.Block
+		expected uint64
+	}{
+		{
+			name:     "A chain of only current blocks has no legacy blocks",
+			blocks:   []*pb.Block{{}, current(), current()},
+			expected: 0,
+		},
+		{
+			name:     "A block of a version 0 header is of legacy form",
+			blocks:   []*pb.Block{{}, {}, current()},
+			expected: 1,
+		},
+		{
+			name:     "A block of txs of whole coin amounts, migrated or not, is of legacy form",
+			blocks:   []*pb.Block{{}, current(&pb.Tx{LegacyValue: 1, Value: transactions.Coin}), current()},
+			expected: 1,
+		},
+		{
+			name:     "A block of a version 0 tx is of legacy form",
+			blocks:   []*pb.Block{{}, current(&pb.Tx{Version: canonical.TxVersion}), current(&pb.Tx{})},
+			expected: 2,
+		},
+		{
+			name:     "Legacy blocks are marked up to the last of them",
+			blocks:   []*pb.Block{{}, {}, current(), current(&pb.Tx{Version: canonical.TxVersion, LegacyFee: 0.5}), current()},
+			expected: 3,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			chain := &Chain{Pbc: &pb.Chain{Blocks: c.blocks}}
+			chain.MarkLegacy()
+
+			if chain.LegacyHeight != c.expected {
+				t.Errorf("expected legacy blocks up to height %d, got %d", c.expected, chain.LegacyHeight)
+			}
+		})
+	}
+}
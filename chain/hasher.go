@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"encoding/binary"
 
+	"github.com/asgaines/blockchain/canonical"
 	"github.com/golang/protobuf/ptypes"
 )
 
@@ -18,7 +19,26 @@ func NewHasher() Hasher {
 	return &hasher{}
 }
 
+// Hash computes the sha256 hash of the canonical encoding of the block header.
+// Blocks of version 0 keep the hash they were mined with before the encoding
+// existed, and blocks of an unknown version have no hash.
 func (h *hasher) Hash(b *Block) []byte {
+	if b.Version == 0 {
+		return unversionedHash(b)
+	}
+
+	headerB, err := canonical.EncodeHeader(b.ToProto())
+	if err != nil {
+		return nil
+	}
+
+	hb := sha256.Sum256(headerB)
+
+	return hb[:]
+}
+
+// unversionedHash is the hash of blocks from before the canonical encoding
+func unversionedHash(b *Block) []byte {
 	nonceB := make([]byte, 8)
 	binary.BigEndian.PutUint64(nonceB, b.Nonce)
 
@@ -0,0 +1,51 @@
+package chain
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+func TestHash(t *testing.T) {
+	newBlock := func(version uint32) *Block {
+		return &Block{
+			Version:    version,
+			Timestamp:  &timestamp.Timestamp{Seconds: 646459200},
+			Prevhash:   []byte{1, 2},
+			Nonce:      42,
+			Target:     []byte{0xff},
+			MerkleRoot: []byte{3},
+		}
+	}
+
+	cases := []struct {
+		name     string
+		block    *Block
+		expected string
+	}{
+		{
+			name:     "A block of version 0 keeps the hash of its concatenated header",
+			block:    newBlock(0),
+			expected: "7fadae9eb1cea6d094776e32e7e801c11d4628ad60a78b588e0b0a112108afcf",
+		},
+		{
+			name:     "A block of version 1 is hashed over its canonical header encoding",
+			block:    newBlock(1),
+			expected: "f9018ae0547e144c515676deac45974fa593fa82ce13a164ef3821778c50dad2",
+		},
+		{
+			name:     "A block of an unknown version has no hash",
+			block:    newBlock(2),
+			expected: "",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := hex.EncodeToString(NewHasher().Hash(c.block)); got != c.expected {
+				t.Errorf("expected %s, got %s", c.expected, got)
+			}
+		})
+	}
+}
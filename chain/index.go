@@ -0,0 +1,109 @@
+package chain
+
+import (
+	"math/big"
+	"sync"
+)
+
+// index caches the hash and chainwork of each block of a chain, and the height
+// of each hash. Blocks are indexed on first lookup, from the lowest not yet
+// indexed, so blocks appended to the chain are picked up as they are needed.
+type index struct {
+	hashes  [][]byte
+	works   []*big.Int
+	heights map[string]uint64
+	mu      sync.Mutex
+}
+
+// copy returns an index of the blocks indexed so far, safe to extend apart
+func (idx *index) copy() index {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	heights := make(map[string]uint64, len(idx.heights))
+	for hash, height := range idx.heights {
+		heights[hash] = height
+	}
+
+	return index{
+		hashes:  append([][]byte{}, idx.hashes...),
+		works:   append([]*big.Int{}, idx.works...),
+		heights: heights,
+	}
+}
+
+// indexed indexes the blocks of the chain not yet indexed, then calls f with
+// the index held
+func (bc *Chain) indexed(f func(idx *index)) {
+	bc.index.mu.Lock()
+	defer bc.index.mu.Unlock()
+
+	hasher := bc.Hasher
+	if hasher == nil {
+		hasher = NewHasher()
+	}
+
+	if bc.index.heights == nil {
+		bc.index.heights = make(map[string]uint64)
+	}
+
+	for height := len(bc.index.hashes); height < bc.Length(); height++ {
+		block := bc.BlockByIdx(height)
+		hash := hasher.Hash(block)
+		bc.index.hashes = append(bc.index.hashes, hash)
+
+		work := BlockWork(block.Target)
+		if height > 0 {
+			work.Add(work, bc.index.works[height-1])
+		}
+		bc.index.works = append(bc.index.works, work)
+
+		// Should two blocks share a hash, the lowest is kept
+		if _, ok := bc.index.heights[string(hash)]; !ok {
+			bc.index.heights[string(hash)] = uint64(height)
+		}
+	}
+
+	f(&bc.index)
+}
+
+// Hash returns the hash of the block at the height, hashing it only once
+func (bc *Chain) Hash(height uint64) []byte {
+	var hash []byte
+
+	bc.indexed(func(idx *index) {
+		if height < uint64(len(idx.hashes)) {
+			hash = idx.hashes[height]
+		}
+	})
+
+	return hash
+}
+
+// HeightOf returns the height of the block of the chain with the hash
+func (bc *Chain) HeightOf(hash []byte) (uint64, bool) {
+	var height uint64
+	var ok bool
+
+	bc.indexed(func(idx *index) {
+		height, ok = idx.heights[string(hash)]
+	})
+
+	return height, ok
+}
+
+// BlockByHash returns the block of the chain with the hash
+func (bc *Chain) BlockByHash(hash []byte) (*Block, bool) {
+	height, ok := bc.HeightOf(hash)
+	if !ok {
+		return nil, false
+	}
+
+	return bc.BlockByIdx(int(height)), true
+}
+
+// Contains reports whether the chain has a block with the hash
+func (bc *Chain) Contains(hash []byte) bool {
+	_, ok := bc.HeightOf(hash)
+	return ok
+}
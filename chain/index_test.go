@@ -0,0 +1,119 @@
+package chain
+
+import (
+	"bytes"
+	"testing"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+)
+
+// countingHasher hashes as the default hasher, counting the blocks hashed
+type countingHasher struct {
+	Hasher
+	hashed int
+}
+
+func (h *countingHasher) Hash(block *Block) []byte {
+	h.hashed++
+	return h.Hasher.Hash(block)
+}
+
+func indexedChain(hasher Hasher, length int) *Chain {
+	c := NewChain(hasher)
+
+	for height := 1; height < length; height++ {
+		block := NewBlock(hasher, hasher.Hash(c.LastLink()), []*pb.Tx{}, uint64(height), []byte{0xff}, "")
+		c = c.WithBlock(block)
+	}
+
+	return c
+}
+
+func TestIndex(t *testing.T) {
+	hasher := NewHasher()
+	c := indexedChain(hasher, 4)
+
+	cases := []struct {
+		name   string
+		hash   []byte
+		height uint64
+		found  bool
+	}{
+		{
+			name:   "The genesis block is found at height 0",
+			hash:   hasher.Hash(c.BlockByIdx(0)),
+			height: 0,
+			found:  true,
+		},
+		{
+			name:   "The last block is found at the height of the chain",
+			hash:   hasher.Hash(c.LastLink()),
+			height: 3,
+			found:  true,
+		},
+		{
+			name:  "A hash of no block of the chain is not found",
+			hash:  []byte("Gob"),
+			found: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			height, ok := c.HeightOf(tc.hash)
+			if ok != tc.found || height != tc.height {
+				t.Errorf("expected height %d (%t), got %d (%t)", tc.height, tc.found, height, ok)
+			}
+
+			if got := c.Contains(tc.hash); got != tc.found {
+				t.Errorf("expected contains to be %t, got %t", tc.found, got)
+			}
+
+			block, ok := c.BlockByHash(tc.hash)
+			if ok != tc.found {
+				t.Fatalf("expected block to be found: %t, got %t", tc.found, ok)
+			}
+
+			if tc.found && block != c.BlockByIdx(int(tc.height)) {
+				t.Errorf("expected block at height %d", tc.height)
+			}
+
+			if tc.found && !bytes.Equal(c.Hash(tc.height), tc.hash) {
+				t.Errorf("expected hash %x at height %d, got %x", tc.hash, tc.height, c.Hash(tc.height))
+			}
+		})
+	}
+
+	if hash := c.Hash(4); hash != nil {
+		t.Errorf("expected no hash above the chain, got %x", hash)
+	}
+}
+
+func TestIndexHashesOnce(t *testing.T) {
+	hasher := &countingHasher{Hasher: NewHasher()}
+	c := indexedChain(NewHasher(), 3)
+	c.Hasher = hasher
+
+	for i := 0; i < 2; i++ {
+		c.Contains([]byte("Lucille"))
+	}
+
+	if hasher.hashed != 3 {
+		t.Errorf("expected each of 3 blocks hashed once, got %d hashes", hasher.hashed)
+	}
+
+	// Only the appended block is hashed for the extended chain
+	extended := c.WithBlock(NewBlock(NewHasher(), c.Hash(2), []*pb.Tx{}, 3, []byte{0xff}, ""))
+	if _, ok := extended.HeightOf(extended.Hash(3)); !ok {
+		t.Errorf("expected appended block to be indexed")
+	}
+
+	if hasher.hashed != 4 {
+		t.Errorf("expected only the appended block hashed again, got %d hashes", hasher.hashed)
+	}
+
+	// The original chain does not see the block appended to the extended one
+	if c.Contains(extended.Hash(3)) {
+		t.Errorf("expected block appended to another chain not to be indexed")
+	}
+}
@@ -22,46 +22,49 @@ func InitChain(hasher Hasher, filesPrefix string) *Chain {
 		log.Fatalf("could not unmarshal chain: %s", err)
 	}
 
-	return &Chain{
-		Pbc: &bcpb,
+	c := &Chain{
+		Pbc:    &bcpb,
+		Hasher: hasher,
 	}
-}
 
-func (c *Chain) Store(filesPrefix string) error {
-	f, err := os.OpenFile(getStorageFnameProto(filesPrefix), os.O_WRONLY|os.O_CREATE, 0644)
-	if err != nil {
-		return err
+	if migrated := c.MigrateAmounts(); migrated > 0 {
+		log.Printf("migrated amounts of %d txs to base units", migrated)
 	}
-	defer func() {
-		if err := f.Close(); err != nil {
-			log.Println(err)
-		}
-	}()
+	c.MarkLegacy()
+
+	return c
+}
 
+// Store writes the chain to storage, replacing whatever was stored before
+func (c *Chain) Store(filesPrefix string) error {
 	b, err := proto.Marshal(c.ToProto())
 	if err != nil {
 		return fmt.Errorf("could not marshal chain: %w", err)
 	}
 
-	if _, err := f.Write(b); err != nil {
+	if err := writeFile(getStorageFnameProto(filesPrefix), b); err != nil {
 		return fmt.Errorf("could not write to file: %w", err)
 	}
 
-	fj, err := os.OpenFile(getStorageFnameJSON(filesPrefix), os.O_WRONLY|os.O_CREATE, 0644)
+	j, err := new(jsonpb.Marshaler).MarshalToString(c.ToProto())
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if err := fj.Close(); err != nil {
-			log.Println(err)
-		}
-	}()
 
-	if err := new(jsonpb.Marshaler).Marshal(fj, c.ToProto()); err != nil {
+	return writeFile(getStorageFnameJSON(filesPrefix), []byte(j))
+}
+
+// writeFile replaces the file at the path with b. It is written to a temporary
+// file first and renamed into place, so that a shorter write never leaves the
+// stale tail of the last one behind, nor a crash a file half written.
+func writeFile(path string, b []byte) error {
+	tmp := path + ".tmp"
+
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
 		return err
 	}
 
-	return nil
+	return os.Rename(tmp, path)
 }
 
 func getStorageFnameProto(filesPrefix string) string {
@@ -0,0 +1,39 @@
+package chain
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "run.proto")
+
+	// A chain replaced by a shorter one, as after a reorganisation
+	for _, b := range [][]byte{[]byte("the longer chain"), []byte("shorter")} {
+		if err := writeFile(path, b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, []byte("shorter")) {
+		t.Errorf("expected the last write alone, got %q", got)
+	}
+
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("expected no temporary file left behind, got %v", err)
+	}
+}
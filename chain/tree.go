@@ -0,0 +1,239 @@
+package chain
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+)
+
+// ErrUnknownParent is returned for a block linking to a block not in the tree
+var ErrUnknownParent = errors.New("parent of block is not in the tree")
+
+// MaxBranchDepth is how far the tip of a side branch can fall behind the best
+// tip before the branch is pruned from the tree. Without it, forks off old
+// blocks would be held forever.
+const MaxBranchDepth = 100
+
+// Tree holds the blocks of every chain known: the best chain, and the side
+// branches forking from it, so a side branch overtaking the best chain can be
+// switched to. Each block is linked to its parent by its prevhash; a genesis
+// block roots a tree of its own. Side branches falling MaxBranchDepth blocks
+// behind the best tip are pruned. It is safe for concurrent use.
+type Tree interface {
+	// AddChain adds the blocks of the chain not yet in the tree
+	AddChain(c *Chain)
+	// AddBlock adds the block, with the hash, on top of its parent in the
+	// tree. ErrUnknownParent is returned should its parent not be in it.
+	AddBlock(block *Block, hash []byte) error
+	// Contains reports whether the tree has the block with the hash
+	Contains(hash []byte) bool
+	// Block returns the block of the tree with the hash
+	Block(hash []byte) (*Block, bool)
+	// ChainTo returns the chain from genesis up to the block with the hash
+	ChainTo(hash []byte) (*Chain, bool)
+	// Best returns the chain of the most work, ties broken as by HasMoreWork
+	Best() *Chain
+	// Len returns the number of blocks in the tree
+	Len() int
+}
+
+// NewTree instantiates a Tree holding the blocks of the chain
+func NewTree(c *Chain) Tree {
+	t := tree{
+		blocks: make(map[string]*treeBlock),
+		tips:   make(map[string]*treeBlock),
+		hasher: c.Hasher,
+	}
+
+	t.AddChain(c)
+
+	return &t
+}
+
+type tree struct {
+	blocks map[string]*treeBlock
+	// tips are the blocks with no children, of which best is one
+	tips map[string]*treeBlock
+	best *treeBlock
+	// hasher is that of the chains returned
+	hasher Hasher
+	mu     sync.RWMutex
+}
+
+type treeBlock struct {
+	block  *Block
+	hash   []byte
+	height uint64
+	// work is the chainwork up to the block
+	work     *big.Int
+	parent   *treeBlock
+	children int
+}
+
+func (t *tree) AddChain(c *Chain) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Only the blocks above the highest known are new
+	from := 0
+	for height := c.Length() - 1; height >= 0; height-- {
+		if _, ok := t.blocks[string(c.Hash(uint64(height)))]; ok {
+			from = height + 1
+			break
+		}
+	}
+
+	for height := from; height < c.Length(); height++ {
+		var parent *treeBlock
+		if height > 0 {
+			parent = t.blocks[string(c.Hash(uint64(height-1)))]
+		}
+
+		t.add(&treeBlock{
+			block:  c.BlockByIdx(height),
+			hash:   c.Hash(uint64(height)),
+			height: uint64(height),
+			work:   c.Work(uint64(height)),
+			parent: parent,
+		})
+	}
+}
+
+func (t *tree) AddBlock(block *Block, hash []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.blocks[string(hash)]; ok {
+		return nil
+	}
+
+	parent, ok := t.blocks[string(block.Prevhash)]
+	if !ok {
+		return ErrUnknownParent
+	}
+
+	work := BlockWork(block.Target)
+	work.Add(work, parent.work)
+
+	t.add(&treeBlock{
+		block:  block,
+		hash:   hash,
+		height: parent.height + 1,
+		work:   work,
+		parent: parent,
+	})
+
+	return nil
+}
+
+// add adds the block, making it the best tip should it have the most work
+func (t *tree) add(b *treeBlock) {
+	t.blocks[string(b.hash)] = b
+	t.tips[string(b.hash)] = b
+
+	if b.parent != nil {
+		b.parent.children++
+		delete(t.tips, string(b.parent.hash))
+	}
+
+	if t.best == nil || MoreWork(b.work, b.hash, t.best.work, t.best.hash) {
+		t.best = b
+		t.prune()
+	}
+}
+
+// prune drops the side branches whose tips have fallen more than
+// MaxBranchDepth blocks behind the best tip, down to where they fork. Every
+// block of the best chain has a child, or is the best tip, so is never
+// dropped.
+func (t *tree) prune() {
+	for _, tip := range t.tips {
+		if tip.height+MaxBranchDepth >= t.best.height {
+			continue
+		}
+
+		delete(t.tips, string(tip.hash))
+		for b := tip; b != nil && b.children == 0 && b != t.best; b = b.parent {
+			delete(t.blocks, string(b.hash))
+
+			if b.parent != nil {
+				b.parent.children--
+			}
+		}
+	}
+}
+
+func (t *tree) Contains(hash []byte) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	_, ok := t.blocks[string(hash)]
+	return ok
+}
+
+func (t *tree) Block(hash []byte) (*Block, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	b, ok := t.blocks[string(hash)]
+	if !ok {
+		return nil, false
+	}
+
+	return b.block, true
+}
+
+func (t *tree) ChainTo(hash []byte) (*Chain, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	b, ok := t.blocks[string(hash)]
+	if !ok {
+		return nil, false
+	}
+
+	return t.chainTo(b), true
+}
+
+func (t *tree) Best() *Chain {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.chainTo(t.best)
+}
+
+// chainTo returns the chain up to the block, indexed by the hashes and work
+// already known of its blocks
+func (t *tree) chainTo(tip *treeBlock) *Chain {
+	length := tip.height + 1
+
+	c := Chain{
+		Pbc: &pb.Chain{
+			Blocks: make([]*pb.Block, length),
+		},
+		Hasher: t.hasher,
+		index: index{
+			hashes:  make([][]byte, length),
+			works:   make([]*big.Int, length),
+			heights: make(map[string]uint64, length),
+		},
+	}
+
+	for b := tip; b != nil; b = b.parent {
+		c.Pbc.Blocks[b.height] = b.block.ToProto()
+		c.index.hashes[b.height] = b.hash
+		c.index.works[b.height] = b.work
+		c.index.heights[string(b.hash)] = b.height
+	}
+
+	return &c
+}
+
+func (t *tree) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return len(t.blocks)
+}
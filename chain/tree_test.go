@@ -0,0 +1,118 @@
+package chain
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/golang/protobuf/proto"
+)
+
+func TestTree(t *testing.T) {
+	hasher := NewHasher()
+	target := bytes.Repeat([]byte{0xff}, 32)
+
+	extend := func(c *Chain, nonce uint64) *Chain {
+		return c.WithBlock(NewBlock(hasher, c.Hash(uint64(c.Length()-1)), []*pb.Tx{}, nonce, target, ""))
+	}
+
+	base := extend(NewChain(hasher), 1)
+	main := extend(extend(base, 2), 3)
+	side := extend(base, 4)
+
+	tree := NewTree(main)
+	tree.AddChain(side)
+
+	if got := tree.Len(); got != 5 {
+		t.Errorf("expected the 5 blocks of both branches, got %d", got)
+	}
+
+	if best := tree.Best(); !proto.Equal(best.ToProto(), main.ToProto()) {
+		t.Errorf("expected the branch of the most work to be best, got %d blocks", best.Length())
+	}
+
+	// Extending the side branch block by block overtakes the main branch
+	for _, nonce := range []uint64{5, 6} {
+		side = extend(side, nonce)
+
+		if err := tree.AddBlock(side.LastLink(), side.Hash(uint64(side.Length()-1))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	best := tree.Best()
+	if !proto.Equal(best.ToProto(), side.ToProto()) {
+		t.Errorf("expected the overtaking side branch to be best, got %d blocks", best.Length())
+	}
+
+	// The chains of the tree are indexed as if hashed afresh
+	for height := 0; height < best.Length(); height++ {
+		if got := best.Hash(uint64(height)); !bytes.Equal(got, hasher.Hash(best.BlockByIdx(height))) {
+			t.Errorf("expected hash of block %d to be indexed, got %x", height, got)
+		}
+	}
+
+	if best.ChainWork().Cmp(side.ChainWork()) != 0 {
+		t.Errorf("expected chainwork %v, got %v", side.ChainWork(), best.ChainWork())
+	}
+
+	c, ok := tree.ChainTo(main.Hash(uint64(main.Length() - 1)))
+	if !ok || !proto.Equal(c.ToProto(), main.ToProto()) {
+		t.Errorf("expected the chain up to the tip of the main branch")
+	}
+
+	if _, ok := tree.ChainTo([]byte("Gob")); ok {
+		t.Errorf("expected no chain up to a block not in the tree")
+	}
+
+	orphan := NewBlock(hasher, []byte("Buster"), []*pb.Tx{}, 0, target, "")
+	if err := tree.AddBlock(orphan, hasher.Hash(orphan)); !errors.Is(err, ErrUnknownParent) {
+		t.Errorf("expected a block of unknown parent not to be added, got %v", err)
+	}
+
+	if tree.Contains(hasher.Hash(orphan)) {
+		t.Errorf("expected the block of unknown parent not to be in the tree")
+	}
+}
+
+func TestTreePrunes(t *testing.T) {
+	hasher := NewHasher()
+	target := bytes.Repeat([]byte{0xff}, 32)
+
+	extend := func(c *Chain, nonce uint64) *Chain {
+		return c.WithBlock(NewBlock(hasher, c.Hash(uint64(c.Length()-1)), []*pb.Tx{}, nonce, target, ""))
+	}
+
+	base := extend(NewChain(hasher), 1)
+	side := extend(extend(base, 2), 3)
+	main := extend(base, 4)
+
+	tree := NewTree(main)
+	tree.AddChain(side)
+
+	// The main branch overtakes the side branch, until the side tip is as far
+	// behind as a branch can be and still be kept
+	for nonce := uint64(5); uint64(main.Length()-1) < uint64(side.Length()-1)+MaxBranchDepth; nonce++ {
+		main = extend(main, nonce)
+		tree.AddChain(main)
+	}
+
+	sideTip := side.Hash(uint64(side.Length() - 1))
+	if !tree.Contains(sideTip) {
+		t.Fatalf("expected a side branch %d blocks behind to be kept", MaxBranchDepth)
+	}
+
+	main = extend(main, 0)
+	tree.AddChain(main)
+
+	for height := 2; height < side.Length(); height++ {
+		if tree.Contains(side.Hash(uint64(height))) {
+			t.Errorf("expected block %d of the side branch to be pruned", height)
+		}
+	}
+
+	if got := tree.Len(); got != main.Length() {
+		t.Errorf("expected only the %d blocks of the best chain, got %d", main.Length(), got)
+	}
+}
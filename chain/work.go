@@ -0,0 +1,53 @@
+package chain
+
+import (
+	"bytes"
+	"math/big"
+)
+
+// maxHash is one above the highest hash: 2^256
+var maxHash = new(big.Int).Lsh(big.NewInt(1), 256)
+
+// BlockWork returns the work of a block with the target: the number of hashes
+// expected to be tried to find one at or below it, 2^256 / (target + 1)
+func BlockWork(target []byte) *big.Int {
+	denominator := new(big.Int).SetBytes(target)
+	denominator.Add(denominator, big.NewInt(1))
+
+	return denominator.Div(maxHash, denominator)
+}
+
+// Work returns the chainwork up to the block at the height: the sum of the
+// work of every block from genesis to it
+func (bc *Chain) Work(height uint64) *big.Int {
+	work := new(big.Int)
+
+	bc.indexed(func(idx *index) {
+		if height < uint64(len(idx.works)) {
+			work.Set(idx.works[height])
+		}
+	})
+
+	return work
+}
+
+// ChainWork returns the chainwork of the whole chain
+func (bc *Chain) ChainWork() *big.Int {
+	return bc.Work(uint64(bc.Length() - 1))
+}
+
+// HasMoreWork reports whether the chain is preferred over the other: it has
+// more chainwork, or as much and the lower hash of its last block
+func (bc *Chain) HasMoreWork(other *Chain) bool {
+	return MoreWork(bc.ChainWork(), bc.Hash(uint64(bc.Length()-1)), other.ChainWork(), other.Hash(uint64(other.Length()-1)))
+}
+
+// MoreWork reports whether a chain with work a, its last block hashing to
+// aHash, is preferred over one with work b, its last block hashing to bHash
+func MoreWork(a *big.Int, aHash []byte, b *big.Int, bHash []byte) bool {
+	if cmp := a.Cmp(b); cmp != 0 {
+		return cmp > 0
+	}
+
+	return bytes.Compare(aHash, bHash) < 0
+}
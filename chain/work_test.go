@@ -0,0 +1,91 @@
+package chain
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+)
+
+func TestBlockWork(t *testing.T) {
+	cases := []struct {
+		name     string
+		target   []byte
+		expected *big.Int
+	}{
+		{
+			name:     "The highest target is met by any hash",
+			target:   bytes.Repeat([]byte{0xff}, 32),
+			expected: big.NewInt(1),
+		},
+		{
+			name:     "Half the highest target is met by half of hashes",
+			target:   append([]byte{0x7f}, bytes.Repeat([]byte{0xff}, 31)...),
+			expected: big.NewInt(2),
+		},
+		{
+			name:     "A target of zero is met by a single hash",
+			target:   []byte{},
+			expected: new(big.Int).Lsh(big.NewInt(1), 256),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := BlockWork(c.target); got.Cmp(c.expected) != 0 {
+				t.Errorf("expected %v, got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestChainWork(t *testing.T) {
+	hasher := NewHasher()
+	easy := bytes.Repeat([]byte{0xff}, 32)
+	hard := append([]byte{0x0f}, bytes.Repeat([]byte{0xff}, 31)...)
+
+	// chainOf builds a chain on a shared genesis, a block for each target
+	genesis := NewBlock(hasher, []byte{}, []*pb.Tx{}, 0, easy, "")
+	chainOf := func(targets ...[]byte) *Chain {
+		c := &Chain{Pbc: &pb.Chain{Blocks: []*pb.Block{genesis.ToProto()}}}
+		for i, target := range targets {
+			c = c.WithBlock(NewBlock(hasher, c.Hash(uint64(c.Length()-1)), []*pb.Tx{}, uint64(i), target, ""))
+		}
+
+		return c
+	}
+
+	long := chainOf(easy, easy, easy)
+	short := chainOf(hard)
+
+	if got := long.ChainWork(); got.Cmp(big.NewInt(4)) != 0 {
+		t.Errorf("expected chainwork of 4, got %v", got)
+	}
+
+	if got := short.Work(1); got.Cmp(big.NewInt(17)) != 0 {
+		t.Errorf("expected chainwork of 17 up to the hard block, got %v", got)
+	}
+
+	if !short.HasMoreWork(long) || long.HasMoreWork(short) {
+		t.Errorf("expected the shorter chain of more work to be preferred")
+	}
+
+	// Of two chains with as much work, the lower tip hash is preferred
+	a := chainOf(easy)
+	b := chainOf()
+	b = b.WithBlock(NewBlock(hasher, b.Hash(0), []*pb.Tx{}, 42, easy, ""))
+
+	lower, higher := a, b
+	if bytes.Compare(a.Hash(1), b.Hash(1)) > 0 {
+		lower, higher = b, a
+	}
+
+	if !lower.HasMoreWork(higher) || higher.HasMoreWork(lower) {
+		t.Errorf("expected the chain of the lower tip hash to be preferred")
+	}
+
+	if long.HasMoreWork(long) {
+		t.Errorf("expected a chain not to be preferred over itself")
+	}
+}
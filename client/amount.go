@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/asgaines/blockchain/address"
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/asgaines/blockchain/transactions"
+	"github.com/spf13/cobra"
+)
+
+// coinDecimals is the number of decimal places of a coin held by its base units
+const coinDecimals = 8
+
+var amountFromBase bool
+
+var amountCmd = &cobra.Command{
+	Use:   "amount <amount>",
+	Short: "Convert an amount of coins to base units, or back with --from-base",
+	Long: `Convert an amount of coins to base units, or back with --from-base.
+
+Nodes count every amount in base units: one coin is 100000000 base units.`,
+	Example: `  client amount 12.5
+  client amount --from-base 1250000000`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if amountFromBase {
+			units, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid base units: %w", err)
+			}
+
+			fmt.Println(formatAmount(units))
+			return nil
+		}
+
+		units, err := parseAmount(args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(units)
+		return nil
+	},
+}
+
+func init() {
+	amountCmd.Flags().BoolVar(&amountFromBase, "from-base", false, "convert an amount of base units to coins")
+}
+
+// parseAmount converts a decimal amount of coins, such as "12.5", to base units
+// without passing through floating point
+func parseAmount(s string) (uint64, error) {
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	if len(frac) > coinDecimals {
+		return 0, fmt.Errorf("invalid amount %q: at most %d decimal places", s, coinDecimals)
+	}
+
+	frac += strings.Repeat("0", coinDecimals-len(frac))
+
+	var units uint64
+	for _, part := range []string{whole, frac} {
+		if part == "" {
+			continue
+		}
+
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+
+		if part == whole {
+			if n > (^uint64(0))/transactions.Coin {
+				return 0, fmt.Errorf("invalid amount %q: too large", s)
+			}
+			n *= transactions.Coin
+		}
+
+		if units+n < units {
+			return 0, fmt.Errorf("invalid amount %q: too large", s)
+		}
+		units += n
+	}
+
+	return units, nil
+}
+
+// formatAmount writes an amount of base units as a decimal amount of coins
+func formatAmount(units uint64) string {
+	whole := units / transactions.Coin
+	frac := units % transactions.Coin
+
+	if frac == 0 {
+		return strconv.FormatUint(whole, 10)
+	}
+
+	fracS := fmt.Sprintf("%0*d", coinDecimals, frac)
+	return fmt.Sprintf("%d.%s", whole, strings.TrimRight(fracS, "0"))
+}
+
+// txInput is a tx as written by hand, with its amounts in decimal coins
+type txInput struct {
+	pb.Tx
+	Value   json.Number  `json:"value,omitempty"`
+	Fee     json.Number  `json:"fee,omitempty"`
+	Outputs []txOutInput `json:"outputs,omitempty"`
+}
+
+// txOutInput is an output of a tx as written by hand
+type txOutInput struct {
+	Recipient string      `json:"recipient"`
+	Value     json.Number `json:"value"`
+}
+
+// decodeTx reads a tx written by hand, converting its amounts to base units
+func decodeTx(r io.Reader) (*pb.Tx, error) {
+	var in txInput
+	if err := json.NewDecoder(r).Decode(&in); err != nil {
+		return nil, fmt.Errorf("could not decode tx: %w", err)
+	}
+
+	// Txs spending outputs pay through outputs of their own instead
+	if in.Value == "" && len(in.Outputs) == 0 {
+		return nil, errors.New("tx `value` or `outputs` is required")
+	}
+
+	var value uint64
+	var err error
+	if in.Value != "" {
+		if value, err = parseAmount(in.Value.String()); err != nil {
+			return nil, fmt.Errorf("value: %w", err)
+		}
+	}
+
+	var fee uint64
+	if in.Fee != "" {
+		if fee, err = parseAmount(in.Fee.String()); err != nil {
+			return nil, fmt.Errorf("fee: %w", err)
+		}
+	}
+
+	tx := in.Tx
+	tx.Value = value
+	tx.Fee = fee
+
+	for i, out := range in.Outputs {
+		outValue, err := parseAmount(out.Value.String())
+		if err != nil {
+			return nil, fmt.Errorf("output %d value: %w", i, err)
+		}
+
+		tx.Outputs = append(tx.Outputs, &pb.TxOut{
+			Recipient: out.Recipient,
+			Value:     outValue,
+		})
+	}
+
+	return &tx, nil
+}
+
+// encodeTx writes a tx the way decodeTx reads it, with its amounts in decimal coins
+func encodeTx(w io.Writer, tx *pb.Tx) error {
+	out := txInput{
+		Tx: *tx,
+	}
+	out.Tx.Outputs = nil
+
+	if tx.GetValue() > 0 {
+		out.Value = json.Number(formatAmount(tx.GetValue()))
+	}
+
+	if tx.GetFee() > 0 {
+		out.Fee = json.Number(formatAmount(tx.GetFee()))
+	}
+
+	for _, o := range tx.GetOutputs() {
+		out.Outputs = append(out.Outputs, txOutInput{
+			Recipient: o.GetRecipient(),
+			Value:     json.Number(formatAmount(o.GetValue())),
+		})
+	}
+
+	return json.NewEncoder(w).Encode(&out)
+}
+
+// validateRecipients ensures every address the tx pays belongs to the network
+func validateRecipients(tx *pb.Tx, net *address.Network) error {
+	if len(tx.GetOutputs()) == 0 {
+		if _, err := address.Validate(tx.GetRecipient(), net); err != nil {
+			return fmt.Errorf("recipient: %w", err)
+		}
+	}
+
+	for i, out := range tx.GetOutputs() {
+		if _, err := address.Validate(out.GetRecipient(), net); err != nil {
+			return fmt.Errorf("output %d recipient: %w", i, err)
+		}
+	}
+
+	return nil
+}
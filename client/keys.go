@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/asgaines/blockchain/address"
+	"github.com/asgaines/blockchain/transactions"
+	"github.com/spf13/cobra"
+)
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage signing keys",
+}
+
+var keysNewCmd = &cobra.Command{
+	Use:   "new",
+	Short: "Generate a new keypair",
+	Long:  "Generate a new keypair. Keep the private key secret; share the address to receive credit.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		net, err := address.NetworkByName(networkName)
+		if err != nil {
+			return err
+		}
+
+		priv, err := transactions.GenerateKey()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("private key: %s\n", transactions.KeyToHex(priv))
+		fmt.Printf("address:     %s\n", transactions.Address(priv, net))
+
+		return nil
+	},
+}
+
+func init() {
+	keysCmd.AddCommand(keysNewCmd)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/asgaines/blockchain/transactions"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lockHeight uint64
+	lockTime   string
+)
+
+func addLockFlags(c *cobra.Command) {
+	c.Flags().Uint64Var(&lockHeight, "lock-height", 0, "hold the tx back until the block at this height")
+	c.Flags().StringVar(&lockTime, "lock-time", "", "hold the tx back until this time, in RFC 3339 format, e.g. 2019-05-26T00:00:00Z")
+}
+
+// applyLock sets the lock of the tx from the lock flags, when either is given.
+// Otherwise any `lock` read with the tx is kept.
+func applyLock(tx *pb.Tx) error {
+	if lockHeight > 0 && lockTime != "" {
+		return errors.New("only one of --lock-height and --lock-time can be given")
+	}
+
+	if lockHeight > 0 {
+		if lockHeight >= transactions.LockThreshold {
+			return fmt.Errorf("lock height must be below %d", transactions.LockThreshold)
+		}
+
+		tx.Lock = lockHeight
+	}
+
+	if lockTime != "" {
+		t, err := time.Parse(time.RFC3339, lockTime)
+		if err != nil {
+			return fmt.Errorf("invalid lock time: %w", err)
+		}
+
+		if t.Unix() < int64(transactions.LockThreshold) {
+			return fmt.Errorf("lock time must be at or after %s", time.Unix(int64(transactions.LockThreshold), 0).UTC().Format(time.RFC3339))
+		}
+
+		tx.Lock = uint64(t.Unix())
+	}
+
+	return nil
+}
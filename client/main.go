@@ -18,10 +18,13 @@ package main
 import (
 	"log"
 
+	"github.com/asgaines/blockchain/address"
 	pb "github.com/asgaines/blockchain/protogo/blockchain"
 	"github.com/spf13/cobra"
 )
 
+var networkName string
+
 var cmd = &cobra.Command{
 	Use:   "cli",
 	Short: "CLI for Domain Blocklist gRPC service",
@@ -30,6 +33,14 @@ var cmd = &cobra.Command{
 func main() {
 	pb.NodeClientCommand.Short = "Blockchain gRPC client"
 	cmd.AddCommand(pb.NodeClientCommand)
+	cmd.AddCommand(keysCmd)
+	cmd.AddCommand(txCmd)
+	cmd.AddCommand(walletCmd)
+	cmd.AddCommand(multisigCmd)
+	cmd.AddCommand(amountCmd)
+
+	cmd.PersistentFlags().StringVar(&networkName, "network", address.Mainnet.Name, "network addresses are for. One of mainnet/testnet/utxonet")
+	cmd.PersistentFlags().StringVar(&keystoreDir, "keystore", defaultKeystoreDir(), "directory holding the encrypted keystore")
 
 	if err := cmd.Execute(); err != nil {
 		log.Fatalf("Failed running command: %s", err)
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/asgaines/blockchain/address"
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/asgaines/blockchain/transactions"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/spf13/cobra"
+)
+
+var multisigThreshold int
+
+var multisigCmd = &cobra.Command{
+	Use:   "multisig",
+	Short: "Create and spend from M-of-N multisignature accounts",
+	Long: `Create and spend from M-of-N multisignature accounts.
+
+Spending takes several steps, as each key holder signs on their own machine:
+  1. One holder proposes the tx, writing an unsigned request
+  2. Each holder signs the proposal, writing a partially signed request
+  3. The partially signed requests are combined and shared with a node`,
+	Example: `  client multisig propose -m 2 <addr1> <addr2> <addr3> <<< '{"value": 5, "recipient": "<address>"}' > proposal.json
+  client multisig sign --wallet alice < proposal.json > alice.json
+  client multisig sign --wallet bob < proposal.json > bob.json
+  client multisig combine alice.json bob.json | client node sharetx -s <node-ip:port>`,
+}
+
+var multisigAddressCmd = &cobra.Command{
+	Use:   "address <member-address>...",
+	Short: "Print the address of the multisig account requiring threshold signatures of the members",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		net, ms, err := parseMultisig(args)
+		if err != nil {
+			return err
+		}
+
+		pubkeys := make([]ed25519.PublicKey, 0, len(ms.GetPubkeys()))
+		for _, k := range ms.GetPubkeys() {
+			pubkeys = append(pubkeys, k)
+		}
+
+		addr, err := address.FromMultisig(int(ms.GetThreshold()), pubkeys, net)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(addr)
+
+		return nil
+	},
+}
+
+var multisigProposeCmd = &cobra.Command{
+	Use:   "propose <member-address>...",
+	Short: "Propose a tx read from stdin, spending from the multisig account of the members",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		net, ms, err := parseMultisig(args)
+		if err != nil {
+			return err
+		}
+
+		tx, err := decodeTx(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		if err := applyLock(tx); err != nil {
+			return err
+		}
+
+		if err := validateRecipients(tx, net); err != nil {
+			return err
+		}
+
+		if tx.Timestamp == nil {
+			tx.Timestamp = ptypes.TimestampNow()
+		}
+
+		if err := transactions.ProposeMultisig(tx, ms, net); err != nil {
+			return err
+		}
+
+		return json.NewEncoder(os.Stdout).Encode(&pb.ShareTxRequest{
+			Tx: tx,
+		})
+	},
+}
+
+var multisigSignCmd = &cobra.Command{
+	Use:   "sign",
+	Short: "Add a signature to a multisig tx request read from stdin",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req, err := decodeRequest(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		priv, err := signingKey()
+		if err != nil {
+			return err
+		}
+
+		if err := transactions.SignMultisig(req.GetTx(), priv); err != nil {
+			return err
+		}
+
+		return json.NewEncoder(os.Stdout).Encode(req)
+	},
+}
+
+var multisigCombineCmd = &cobra.Command{
+	Use:   "combine <signed-request-file>...",
+	Short: "Combine the signatures of partially signed multisig tx requests",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var combined *pb.ShareTxRequest
+
+		for _, fname := range args {
+			f, err := os.Open(fname)
+			if err != nil {
+				return err
+			}
+
+			req, err := decodeRequest(f)
+			f.Close()
+			if err != nil {
+				return fmt.Errorf("%s: %w", fname, err)
+			}
+
+			if combined == nil {
+				combined = req
+				continue
+			}
+
+			if !bytes.Equal(req.GetTx().GetHash(), combined.GetTx().GetHash()) {
+				return fmt.Errorf("%s: signs a different tx than %s", fname, args[0])
+			}
+
+			for _, sig := range req.GetTx().GetSignatures() {
+				if err := transactions.AddSignature(combined.Tx, sig); err != nil {
+					return fmt.Errorf("%s: %w", fname, err)
+				}
+			}
+		}
+
+		return json.NewEncoder(os.Stdout).Encode(combined)
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{multisigAddressCmd, multisigProposeCmd} {
+		c.Flags().IntVarP(&multisigThreshold, "threshold", "m", 0, "number of member signatures required to spend")
+		c.MarkFlagRequired("threshold")
+	}
+
+	addLockFlags(multisigProposeCmd)
+
+	multisigSignCmd.Flags().StringVarP(&signWallet, "wallet", "w", "", "name of the keystore key to sign with")
+	multisigSignCmd.Flags().Uint32Var(&signAccount, "account", 0, "account to sign with, for HD wallets")
+	multisigSignCmd.Flags().Uint32Var(&signIndex, "index", 0, "index of the key within the account to sign with, for HD wallets")
+
+	multisigCmd.AddCommand(multisigAddressCmd)
+	multisigCmd.AddCommand(multisigProposeCmd)
+	multisigCmd.AddCommand(multisigSignCmd)
+	multisigCmd.AddCommand(multisigCombineCmd)
+}
+
+// parseMultisig builds the multisig of the members' keys, given by their
+// addresses, and the threshold flag
+func parseMultisig(members []string) (*address.Network, *pb.Multisig, error) {
+	net, err := address.NetworkByName(networkName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ms := &pb.Multisig{
+		Threshold: uint32(multisigThreshold),
+	}
+
+	for _, member := range members {
+		a, err := address.Validate(member, net)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		pubkey, err := a.Pubkey()
+		if err != nil {
+			return nil, nil, fmt.Errorf("member %s: %w", member, err)
+		}
+
+		ms.Pubkeys = append(ms.Pubkeys, pubkey)
+	}
+
+	return net, ms, nil
+}
+
+func decodeRequest(r io.Reader) (*pb.ShareTxRequest, error) {
+	var req pb.ShareTxRequest
+	if err := json.NewDecoder(r).Decode(&req); err != nil {
+		return nil, fmt.Errorf("could not decode tx request: %w", err)
+	}
+
+	if req.GetTx().GetMultisig() == nil {
+		return nil, errors.New("request is not for a multisig tx")
+	}
+
+	return &req, nil
+}
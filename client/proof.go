@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/asgaines/blockchain/chain"
+	"github.com/asgaines/blockchain/merkle"
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/asgaines/blockchain/transactions"
+	"github.com/spf13/cobra"
+)
+
+var txVerifyProofCmd = &cobra.Command{
+	Use:   "verify-proof",
+	Short: "Check a proof, read from stdin, that a tx is included in a block",
+	Long: `Check a proof, read from stdin, that a tx is included in a block.
+
+The proof is the response of "node gettxproof". It is checked against the block header alone: the tx must hash
+to its hash, the merkle path must lead from that hash to the merkle root of the header, and the header must hash
+to the block hash. Compare the block hash with one you trust, such as from several nodes, to trust the proof.`,
+	Example: `  client node gettxproof -s <node-ip:port> <<< '{"hash": "<base64-tx-hash>"}' | client tx verify-proof`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		proof, err := decodeProof(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		if err := verifyProof(proof); err != nil {
+			return err
+		}
+
+		fmt.Printf("tx %x is included in block %d (%x)\n", proof.GetTx().GetHash(), proof.GetHeight(), proof.GetBlockHash())
+
+		return nil
+	},
+}
+
+func init() {
+	txCmd.AddCommand(txVerifyProofCmd)
+}
+
+func decodeProof(r io.Reader) (*pb.GetTxProofResponse, error) {
+	var proof pb.GetTxProofResponse
+	if err := json.NewDecoder(r).Decode(&proof); err != nil {
+		return nil, fmt.Errorf("could not decode tx proof: %w", err)
+	}
+
+	return &proof, nil
+}
+
+// verifyProof ensures the tx is included in the block with the header of the
+// proof, using nothing but the header
+func verifyProof(proof *pb.GetTxProofResponse) error {
+	tx, header := proof.GetTx(), proof.GetHeader()
+	if tx == nil || header == nil {
+		return errors.New("proof is missing its tx or header")
+	}
+
+	if !bytes.Equal(tx.GetHash(), transactions.Hash(tx)) {
+		return transactions.ErrHashMismatch
+	}
+
+	if !merkle.Verify(tx.GetHash(), proof.GetPath(), header.GetMerkleRoot()) {
+		return errors.New("merkle path does not lead to the merkle root of the header")
+	}
+
+	if !bytes.Equal(chain.NewHasher().Hash((*chain.Block)(header)), proof.GetBlockHash()) {
+		return errors.New("header does not hash to the block hash")
+	}
+
+	return nil
+}
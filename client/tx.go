@@ -0,0 +1,273 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/asgaines/blockchain/address"
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/asgaines/blockchain/transactions"
+	"github.com/asgaines/blockchain/utxo"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/spf13/cobra"
+)
+
+var txCmd = &cobra.Command{
+	Use:   "tx",
+	Short: "Build transactions",
+}
+
+var (
+	signWallet  string
+	signAccount uint32
+	signIndex   uint32
+)
+
+var txSignCmd = &cobra.Command{
+	Use:   "sign",
+	Short: "Sign a tx read from stdin with a key from the keystore",
+	Long: `Sign a tx read from stdin with a key from the keystore, or the key in BLOCKCHAIN_KEY if no wallet is given.
+
+The signed request is written to stdout, ready to be piped into "node sharetx". A tx locked with --lock-height
+or --lock-time is held back by nodes, and can't be included in a block, until the chain reaches that height or time.`,
+	Example: `  echo '{"value": 5, "recipient": "<address>"}' | client tx sign --wallet <name> | client node sharetx -s <node-ip:port>`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		net, err := address.NetworkByName(networkName)
+		if err != nil {
+			return err
+		}
+
+		priv, err := signingKey()
+		if err != nil {
+			return err
+		}
+
+		tx, err := decodeTx(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		if err := applyLock(tx); err != nil {
+			return err
+		}
+
+		if err := validateRecipients(tx, net); err != nil {
+			return err
+		}
+
+		if tx.Timestamp == nil {
+			tx.Timestamp = ptypes.TimestampNow()
+		}
+
+		transactions.Sign(tx, priv, net)
+
+		return json.NewEncoder(os.Stdout).Encode(&pb.ShareTxRequest{
+			Tx: tx,
+		})
+	},
+}
+
+var (
+	buildUnspent string
+	buildInputs  []string
+	buildTo      string
+	buildValue   string
+	buildFee     string
+	buildChange  string
+	buildMessage string
+)
+
+var txBuildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Build a tx spending unspent outputs, for networks with a UTXO ledger",
+	Long: `Build a tx spending unspent outputs, for networks with a UTXO ledger.
+
+The unspent outputs of an address are read from the file written by "node getunspent". Outputs are spent
+oldest first until they cover the value and fee, unless chosen with --input. Whatever is left over is paid
+back to the --change address, or else to the owner of the outputs spent.
+
+The tx is written to stdout, ready to be piped into "tx sign".`,
+	Example: `  echo '{"address": "<address>"}' | client node getunspent -s <node-ip:port> > unspent.json
+  client tx build --unspent unspent.json --to <address> --value 2.5 --fee 0.001 | client tx sign --wallet <name>`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		net, err := address.NetworkByName(networkName)
+		if err != nil {
+			return err
+		}
+
+		if _, err := address.Validate(buildTo, net); err != nil {
+			return fmt.Errorf("to: %w", err)
+		}
+
+		value, err := parseAmount(buildValue)
+		if err != nil {
+			return fmt.Errorf("value: %w", err)
+		}
+
+		if value == 0 {
+			return errors.New("value must be greater than 0")
+		}
+
+		fee, err := parseAmount(buildFee)
+		if err != nil {
+			return fmt.Errorf("fee: %w", err)
+		}
+
+		if value+fee < value {
+			return errors.New("value plus fee is too large")
+		}
+
+		f, err := os.Open(buildUnspent)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		var unspent pb.GetUnspentResponse
+		if err := json.NewDecoder(f).Decode(&unspent); err != nil {
+			return fmt.Errorf("could not decode unspent outputs: %w", err)
+		}
+
+		selected, err := selectUnspent(unspent.GetUnspent(), buildInputs, value+fee)
+		if err != nil {
+			return err
+		}
+
+		owner := selected[0].GetOutput().GetRecipient()
+		tx := &pb.Tx{
+			Message: buildMessage,
+			Fee:     fee,
+			Outputs: []*pb.TxOut{{Recipient: buildTo, Value: value}},
+		}
+
+		var total uint64
+		for _, u := range selected {
+			if u.GetOutput().GetRecipient() != owner {
+				return errors.New("outputs spent must all be owned by the same address")
+			}
+
+			tx.Inputs = append(tx.Inputs, u.GetOutPoint())
+			total += u.GetOutput().GetValue()
+		}
+
+		if change := total - value - fee; change > 0 {
+			changeAddr := owner
+			if buildChange != "" {
+				if _, err := address.Validate(buildChange, net); err != nil {
+					return fmt.Errorf("change: %w", err)
+				}
+				changeAddr = buildChange
+			}
+
+			tx.Outputs = append(tx.Outputs, &pb.TxOut{Recipient: changeAddr, Value: change})
+		}
+
+		return encodeTx(os.Stdout, tx)
+	},
+}
+
+// selectUnspent picks the outputs to spend: those named by inputs, else the
+// oldest until they add up to at least the amount needed
+func selectUnspent(unspent []*pb.Unspent, inputs []string, needed uint64) ([]*pb.Unspent, error) {
+	byKey := make(map[string]*pb.Unspent, len(unspent))
+	for _, u := range unspent {
+		byKey[utxo.Key(u.GetOutPoint())] = u
+	}
+
+	selected := make([]*pb.Unspent, 0)
+	var total uint64
+
+	if len(inputs) > 0 {
+		for _, input := range inputs {
+			op, err := parseOutPoint(input)
+			if err != nil {
+				return nil, err
+			}
+
+			u, ok := byKey[utxo.Key(op)]
+			if !ok {
+				return nil, fmt.Errorf("input %s is not among the unspent outputs", input)
+			}
+
+			selected = append(selected, u)
+			total += u.GetOutput().GetValue()
+		}
+	} else {
+		for _, u := range unspent {
+			if total >= needed {
+				break
+			}
+
+			selected = append(selected, u)
+			total += u.GetOutput().GetValue()
+		}
+	}
+
+	if len(selected) == 0 || total < needed {
+		return nil, fmt.Errorf("insufficient unspent outputs: have %s, need %s", formatAmount(total), formatAmount(needed))
+	}
+
+	return selected, nil
+}
+
+// parseOutPoint reads an output written as <tx hash in hex>:<index>
+func parseOutPoint(s string) (*pb.OutPoint, error) {
+	i := strings.LastIndexByte(s, ':')
+	if i < 0 {
+		return nil, fmt.Errorf("invalid input %q: expected <tx hash>:<index>", s)
+	}
+
+	hash, err := hex.DecodeString(s[:i])
+	if err != nil {
+		return nil, fmt.Errorf("invalid input %q: %w", s, err)
+	}
+
+	index, err := strconv.ParseUint(s[i+1:], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input %q: %w", s, err)
+	}
+
+	return &pb.OutPoint{
+		TxHash: hash,
+		Index:  uint32(index),
+	}, nil
+}
+
+func init() {
+	txBuildCmd.Flags().StringVar(&buildUnspent, "unspent", "", "file of unspent outputs, as written by \"node getunspent\"")
+	txBuildCmd.Flags().StringArrayVar(&buildInputs, "input", nil, "output to spend, as <tx hash>:<index>; may be repeated")
+	txBuildCmd.Flags().StringVar(&buildTo, "to", "", "address to pay")
+	txBuildCmd.Flags().StringVar(&buildValue, "value", "", "amount of coins to pay")
+	txBuildCmd.Flags().StringVar(&buildFee, "fee", "0", "amount of coins to pay the miner")
+	txBuildCmd.Flags().StringVar(&buildChange, "change", "", "address to pay back what is left over; defaults to the owner of the outputs spent")
+	txBuildCmd.Flags().StringVar(&buildMessage, "message", "", "message to attach to the tx")
+	txBuildCmd.MarkFlagRequired("unspent")
+	txBuildCmd.MarkFlagRequired("to")
+	txBuildCmd.MarkFlagRequired("value")
+	txCmd.AddCommand(txBuildCmd)
+
+	txSignCmd.Flags().StringVarP(&signWallet, "wallet", "w", "", "name of the keystore key to sign with")
+	txSignCmd.Flags().Uint32Var(&signAccount, "account", 0, "account to sign with, for HD wallets")
+	txSignCmd.Flags().Uint32Var(&signIndex, "index", 0, "index of the key within the account to sign with, for HD wallets")
+	addLockFlags(txSignCmd)
+	txCmd.AddCommand(txSignCmd)
+}
+
+func signingKey() (ed25519.PrivateKey, error) {
+	if signWallet != "" {
+		return loadKey(signWallet, signAccount, signIndex)
+	}
+
+	key := os.Getenv("BLOCKCHAIN_KEY")
+	if key == "" {
+		return nil, errors.New("please pass --wallet or set BLOCKCHAIN_KEY env variable")
+	}
+
+	return transactions.KeyFromHex(key)
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/asgaines/blockchain/address"
+	"github.com/asgaines/blockchain/keystore"
+	"github.com/asgaines/blockchain/transactions"
+	"github.com/asgaines/blockchain/wallet"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+var keystoreDir string
+
+var (
+	newHD          bool
+	derivedAccount uint32
+)
+
+var walletCmd = &cobra.Command{
+	Use:   "wallet",
+	Short: "Manage keys held in the encrypted keystore",
+	Long: `Manage keys held in the encrypted keystore.
+
+Keys are encrypted with a passphrase, read from BLOCKCHAIN_PASSPHRASE if set or prompted for otherwise.`,
+}
+
+var walletNewCmd = &cobra.Command{
+	Use:   "new <name>",
+	Short: "Generate a new key, or HD wallet with --hd, and store it under name",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		net, err := address.NetworkByName(networkName)
+		if err != nil {
+			return err
+		}
+
+		passphrase, err := readPassphrase(true)
+		if err != nil {
+			return err
+		}
+
+		ks := keystore.New(keystoreDir)
+
+		if !newHD {
+			priv, err := ks.Create(args[0], passphrase)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("address: %s\n", transactions.Address(priv, net))
+
+			return nil
+		}
+
+		mnemonic, err := wallet.NewMnemonic()
+		if err != nil {
+			return err
+		}
+
+		if err := ks.ImportMnemonic(args[0], mnemonic, passphrase); err != nil {
+			return err
+		}
+
+		fmt.Printf("Write down this seed phrase and keep it safe. It recovers every key of the wallet:\n\n%s\n\n", mnemonic)
+
+		return printDerived(mnemonic, 0, 0, net)
+	},
+}
+
+var walletRecoverCmd = &cobra.Command{
+	Use:   "recover <name>",
+	Short: "Restore an HD wallet from its seed phrase, read from stdin, under name",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		net, err := address.NetworkByName(networkName)
+		if err != nil {
+			return err
+		}
+
+		b, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("could not read seed phrase from stdin: %w", err)
+		}
+		mnemonic := string(b)
+
+		passphrase, err := readPassphrase(true)
+		if err != nil {
+			return err
+		}
+
+		if err := keystore.New(keystoreDir).ImportMnemonic(args[0], mnemonic, passphrase); err != nil {
+			return err
+		}
+
+		return printDerived(mnemonic, 0, 0, net)
+	},
+}
+
+var walletDeriveCmd = &cobra.Command{
+	Use:   "derive <name> <index>",
+	Short: "Print the address at index within an account of an HD wallet",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		net, err := address.NetworkByName(networkName)
+		if err != nil {
+			return err
+		}
+
+		index, err := strconv.ParseUint(args[1], 10, 31)
+		if err != nil {
+			return fmt.Errorf("invalid index: %w", err)
+		}
+
+		passphrase, err := readPassphrase(false)
+		if err != nil {
+			return err
+		}
+
+		mnemonic, err := keystore.New(keystoreDir).LoadMnemonic(args[0], passphrase)
+		if err != nil {
+			return err
+		}
+
+		return printDerived(mnemonic, derivedAccount, uint32(index), net)
+	},
+}
+
+var walletListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stored keys",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		net, err := address.NetworkByName(networkName)
+		if err != nil {
+			return err
+		}
+
+		entries, err := keystore.New(keystoreDir).List()
+		if err != nil {
+			return err
+		}
+
+		for _, e := range entries {
+			pubkey, err := hex.DecodeString(e.Pubkey)
+			if err != nil {
+				return fmt.Errorf("malformed pubkey for %s: %w", e.Name, err)
+			}
+
+			kind := "key"
+			if e.HD {
+				kind = "hd"
+			}
+
+			fmt.Printf("%s\t%s\t%s\n", e.Name, kind, address.FromPubkey(pubkey, net))
+		}
+
+		return nil
+	},
+}
+
+var walletExportCmd = &cobra.Command{
+	Use:   "export <name>",
+	Short: "Print the unencrypted private key, or seed phrase of an HD wallet, stored under name",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		passphrase, err := readPassphrase(false)
+		if err != nil {
+			return err
+		}
+
+		ks := keystore.New(keystoreDir)
+
+		priv, err := ks.Load(args[0], passphrase)
+		if errors.Is(err, keystore.ErrWrongType) {
+			mnemonic, err := ks.LoadMnemonic(args[0], passphrase)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(mnemonic)
+
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		fmt.Println(transactions.KeyToHex(priv))
+
+		return nil
+	},
+}
+
+var walletImportCmd = &cobra.Command{
+	Use:   "import <name>",
+	Short: "Store a hex-encoded private key read from stdin under name",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		net, err := address.NetworkByName(networkName)
+		if err != nil {
+			return err
+		}
+
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("could not read key from stdin: %w", err)
+		}
+
+		priv, err := transactions.KeyFromHex(strings.TrimSpace(line))
+		if err != nil {
+			return err
+		}
+
+		passphrase, err := readPassphrase(true)
+		if err != nil {
+			return err
+		}
+
+		if err := keystore.New(keystoreDir).Import(args[0], priv, passphrase); err != nil {
+			return err
+		}
+
+		fmt.Printf("address: %s\n", transactions.Address(priv, net))
+
+		return nil
+	},
+}
+
+func init() {
+	walletNewCmd.Flags().BoolVar(&newHD, "hd", false, "create an HD wallet backed up by a seed phrase")
+	walletDeriveCmd.Flags().Uint32Var(&derivedAccount, "account", 0, "account of the HD wallet to derive from")
+
+	walletCmd.AddCommand(walletNewCmd)
+	walletCmd.AddCommand(walletRecoverCmd)
+	walletCmd.AddCommand(walletDeriveCmd)
+	walletCmd.AddCommand(walletListCmd)
+	walletCmd.AddCommand(walletExportCmd)
+	walletCmd.AddCommand(walletImportCmd)
+}
+
+func defaultKeystoreDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "keystore"
+	}
+
+	return filepath.Join(home, ".blockchain", "keystore")
+}
+
+// loadKey loads a signing key from the keystore. For HD wallets, the key is
+// derived at index within account.
+func loadKey(name string, account uint32, index uint32) (ed25519.PrivateKey, error) {
+	passphrase, err := readPassphrase(false)
+	if err != nil {
+		return nil, err
+	}
+
+	ks := keystore.New(keystoreDir)
+
+	priv, err := ks.Load(name, passphrase)
+	if !errors.Is(err, keystore.ErrWrongType) {
+		return priv, err
+	}
+
+	mnemonic, err := ks.LoadMnemonic(name, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	acc, err := deriveAccount(mnemonic, account, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return acc.Key(index)
+}
+
+func deriveAccount(mnemonic string, account uint32, net *address.Network) (*wallet.Account, error) {
+	master, err := wallet.FromMnemonic(mnemonic, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return wallet.NewAccount(master, account, net)
+}
+
+func printDerived(mnemonic string, account uint32, index uint32, net *address.Network) error {
+	acc, err := deriveAccount(mnemonic, account, net)
+	if err != nil {
+		return err
+	}
+
+	addr, err := acc.Address(index)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("m/%d'/%d'/%d'/%d'\t%s\n", wallet.Purpose, wallet.CoinType, account, index, addr)
+
+	return nil
+}
+
+// readPassphrase takes the passphrase from the environment, falling back to
+// prompting on the terminal. New passphrases are prompted for twice.
+func readPassphrase(confirm bool) (string, error) {
+	if p := os.Getenv("BLOCKCHAIN_PASSPHRASE"); p != "" {
+		return p, nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		// Stdin may carry a key being imported; fall back to the controlling terminal
+		tty, err := os.Open("/dev/tty")
+		if err != nil {
+			return "", errors.New("no terminal to prompt on, please set BLOCKCHAIN_PASSPHRASE")
+		}
+		defer tty.Close()
+		fd = int(tty.Fd())
+	}
+
+	fmt.Fprint(os.Stderr, "Passphrase: ")
+	p, err := terminal.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+
+	if confirm {
+		fmt.Fprint(os.Stderr, "Repeat passphrase: ")
+		again, err := terminal.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+
+		if string(again) != string(p) {
+			return "", errors.New("passphrases do not match")
+		}
+	}
+
+	return string(p), nil
+}
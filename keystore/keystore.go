@@ -0,0 +1,358 @@
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/asgaines/blockchain/transactions"
+	"github.com/asgaines/blockchain/wallet"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Version is the version of the on-disk key file format
+const Version = 1
+
+// Parameters for deriving the encryption key from a passphrase.
+// N is the CPU/memory cost; raising it slows down brute-forcing of passphrases.
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	saltLen      = 32
+)
+
+var (
+	// ErrNotFound is returned when no key is stored under a name
+	ErrNotFound = errors.New("key not found")
+	// ErrExists is returned when attempting to store a key under a name already in use
+	ErrExists = errors.New("a key with that name already exists")
+	// ErrDecrypt is returned when a key cannot be decrypted, most likely due to
+	// a wrong passphrase
+	ErrDecrypt = errors.New("could not decrypt key, check the passphrase")
+	// ErrCorrupt is returned when a key file is malformed, or asks for key
+	// derivation parameters other than those keys are written with
+	ErrCorrupt = errors.New("key file is corrupt")
+	// ErrWrongType is returned when loading a single key from an HD wallet
+	// entry, or a seed phrase from a single key entry
+	ErrWrongType = errors.New("entry holds a different type of key")
+)
+
+// Types of secret held by an entry
+const (
+	typeKey      = "key"
+	typeMnemonic = "mnemonic"
+)
+
+var validName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Keystore persists private keys to disk, each encrypted with a passphrase
+type Keystore interface {
+	Create(name string, passphrase string) (ed25519.PrivateKey, error)
+	Import(name string, priv ed25519.PrivateKey, passphrase string) error
+	Load(name string, passphrase string) (ed25519.PrivateKey, error)
+	ImportMnemonic(name string, mnemonic string, passphrase string) error
+	LoadMnemonic(name string, passphrase string) (string, error)
+	List() ([]Entry, error)
+}
+
+// Entry describes a stored key without decrypting it
+type Entry struct {
+	Name string
+	// Pubkey is the hex-encoded public key of the entry. For HD wallets it
+	// is that of the first key of the first account.
+	Pubkey string
+	// HD is set for entries holding a seed phrase rather than a single key
+	HD bool
+}
+
+// New returns a Keystore which keeps one file per key within dir
+func New(dir string) Keystore {
+	return &keystore{
+		dir: dir,
+	}
+}
+
+type keystore struct {
+	dir string
+}
+
+type keyFile struct {
+	Version int `json:"version"`
+	// Type is absent for single keys written before HD wallets were supported
+	Type       string `json:"type,omitempty"`
+	Pubkey     string `json:"pubkey"`
+	KDF        kdf    `json:"kdf"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type kdf struct {
+	Name string `json:"name"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt []byte `json:"salt"`
+}
+
+func (ks *keystore) Create(name string, passphrase string) (ed25519.PrivateKey, error) {
+	priv, err := transactions.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ks.Import(name, priv, passphrase); err != nil {
+		return nil, err
+	}
+
+	return priv, nil
+}
+
+func (ks *keystore) Import(name string, priv ed25519.PrivateKey, passphrase string) error {
+	return ks.store(name, typeKey, priv.Seed(), transactions.Pubkey(priv), passphrase)
+}
+
+func (ks *keystore) ImportMnemonic(name string, mnemonic string, passphrase string) error {
+	master, err := wallet.FromMnemonic(mnemonic, "")
+	if err != nil {
+		return err
+	}
+
+	account, err := wallet.NewAccount(master, 0, nil)
+	if err != nil {
+		return err
+	}
+
+	first, err := account.Key(0)
+	if err != nil {
+		return err
+	}
+
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+
+	return ks.store(name, typeMnemonic, []byte(mnemonic), transactions.Pubkey(first), passphrase)
+}
+
+func (ks *keystore) store(name string, typ string, secret []byte, pubkey string, passphrase string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid name %q: only letters, digits, '-' and '_' allowed", name)
+	}
+
+	if passphrase == "" {
+		return errors.New("passphrase must not be empty")
+	}
+
+	if err := os.MkdirAll(ks.dir, 0700); err != nil {
+		return err
+	}
+
+	kf, err := encrypt(secret, pubkey, passphrase)
+	if err != nil {
+		return err
+	}
+	kf.Type = typ
+
+	b, err := json.MarshalIndent(kf, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(ks.path(name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		return ErrExists
+	} else if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return fmt.Errorf("could not write to file: %w", err)
+	}
+
+	return f.Close()
+}
+
+func (ks *keystore) Load(name string, passphrase string) (ed25519.PrivateKey, error) {
+	kf, err := ks.read(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if kf.Type != typeKey {
+		return nil, fmt.Errorf("%s is an HD wallet: %w", name, ErrWrongType)
+	}
+
+	seed, err := decrypt(kf, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("decrypted key has invalid length %d", len(seed))
+	}
+
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+func (ks *keystore) LoadMnemonic(name string, passphrase string) (string, error) {
+	kf, err := ks.read(name)
+	if err != nil {
+		return "", err
+	}
+
+	if kf.Type != typeMnemonic {
+		return "", fmt.Errorf("%s is a single key: %w", name, ErrWrongType)
+	}
+
+	mnemonic, err := decrypt(kf, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	return string(mnemonic), nil
+}
+
+func (ks *keystore) List() ([]Entry, error) {
+	paths, err := filepath.Glob(filepath.Join(ks.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(paths))
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+
+		kf, err := ks.read(name)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, Entry{
+			Name:   name,
+			Pubkey: kf.Pubkey,
+			HD:     kf.Type == typeMnemonic,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+
+	return entries, nil
+}
+
+func (ks *keystore) read(name string) (*keyFile, error) {
+	b, err := ioutil.ReadFile(ks.path(name))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	var kf keyFile
+	if err := json.Unmarshal(b, &kf); err != nil {
+		return nil, fmt.Errorf("could not unmarshal key file %s: %w", name, err)
+	}
+
+	if kf.Version != Version {
+		return nil, fmt.Errorf("unsupported key file version %d", kf.Version)
+	}
+
+	if kf.Type == "" {
+		kf.Type = typeKey
+	}
+
+	return &kf, nil
+}
+
+func (ks *keystore) path(name string) string {
+	return filepath.Join(ks.dir, name+".json")
+}
+
+func encrypt(secret []byte, pubkey string, passphrase string) (*keyFile, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	k := kdf{
+		Name: "scrypt",
+		N:    scryptN,
+		R:    scryptR,
+		P:    scryptP,
+		Salt: salt,
+	}
+
+	aead, err := k.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return &keyFile{
+		Version: Version,
+		Pubkey:  pubkey,
+		KDF:     k,
+		Nonce:   nonce,
+		// Binding the pubkey as additional data prevents it from being swapped
+		// out in the file without detection
+		Ciphertext: aead.Seal(nil, nonce, secret, []byte(pubkey)),
+	}, nil
+}
+
+func decrypt(kf *keyFile, passphrase string) ([]byte, error) {
+	aead, err := kf.KDF.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(kf.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("%w: nonce of %d bytes", ErrCorrupt, len(kf.Nonce))
+	}
+
+	secret, err := aead.Open(nil, kf.Nonce, kf.Ciphertext, []byte(kf.Pubkey))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	return secret, nil
+}
+
+func (k kdf) aead(passphrase string) (cipher.AEAD, error) {
+	if k.Name != "scrypt" {
+		return nil, fmt.Errorf("unsupported kdf %q", k.Name)
+	}
+
+	// The costs are read from the file, so a tampered one could otherwise ask
+	// for more memory than the machine has
+	if k.N != scryptN || k.R != scryptR || k.P != scryptP {
+		return nil, fmt.Errorf("%w: scrypt parameters N=%d r=%d p=%d", ErrCorrupt, k.N, k.R, k.P)
+	}
+
+	key, err := scrypt.Key([]byte(passphrase), k.Salt, k.N, k.R, k.P, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
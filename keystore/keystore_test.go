@@ -0,0 +1,264 @@
+package keystore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/asgaines/blockchain/transactions"
+	"github.com/asgaines/blockchain/wallet"
+)
+
+func tempKeystore(t *testing.T) (Keystore, string) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return New(dir), dir
+}
+
+func TestCreateLoad(t *testing.T) {
+	ks, dir := tempKeystore(t)
+	defer os.RemoveAll(dir)
+
+	priv, err := ks.Create("bluth", "banana stand")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ks.Load("bluth", "banana stand")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(priv, got) {
+		t.Errorf("expected loaded key to equal created key")
+	}
+
+	if _, err := ks.Load("bluth", "frozen banana"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected %v for wrong passphrase, got %v", ErrDecrypt, err)
+	}
+
+	if _, err := ks.Load("funke", "banana stand"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v for missing key, got %v", ErrNotFound, err)
+	}
+
+	if _, err := ks.Create("bluth", "banana stand"); !errors.Is(err, ErrExists) {
+		t.Errorf("expected %v for duplicate name, got %v", ErrExists, err)
+	}
+}
+
+func TestImport(t *testing.T) {
+	cases := []struct {
+		name       string
+		keyName    string
+		passphrase string
+		hasErr     bool
+	}{
+		{
+			name:       "A named key with passphrase is imported",
+			keyName:    "lucille_2",
+			passphrase: "hey brother",
+			hasErr:     false,
+		},
+		{
+			name:       "An empty passphrase is refused",
+			keyName:    "lucille",
+			passphrase: "",
+			hasErr:     true,
+		},
+		{
+			name:       "A name which could escape the keystore directory is refused",
+			keyName:    "../lucille",
+			passphrase: "hey brother",
+			hasErr:     true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ks, dir := tempKeystore(t)
+			defer os.RemoveAll(dir)
+
+			priv, err := transactions.GenerateKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := ks.Import(c.keyName, priv, c.passphrase); (err != nil) != c.hasErr {
+				t.Errorf("expected error: %v, got %v", c.hasErr, err)
+			}
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	ks, dir := tempKeystore(t)
+	defer os.RemoveAll(dir)
+
+	gob, err := ks.Create("gob", "illusion")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buster, err := ks.Create("buster", "loose seal")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ks.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Entry{
+		{Name: "buster", Pubkey: transactions.Pubkey(buster)},
+		{Name: "gob", Pubkey: transactions.Pubkey(gob)},
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestLoadTamperedPubkey(t *testing.T) {
+	ks, dir := tempKeystore(t)
+	defer os.RemoveAll(dir)
+
+	if _, err := ks.Create("tobias", "blue man"); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "tobias.json")
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var kf keyFile
+	if err := json.Unmarshal(b, &kf); err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := transactions.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kf.Pubkey = transactions.Pubkey(other)
+
+	b, err = json.Marshal(kf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(path, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ks.Load("tobias", "blue man"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected %v for swapped pubkey, got %v", ErrDecrypt, err)
+	}
+}
+
+func TestLoadCorrupt(t *testing.T) {
+	cases := []struct {
+		name    string
+		corrupt func(kf *keyFile)
+	}{
+		{
+			name:    "A key file whose nonce is cut short is corrupt",
+			corrupt: func(kf *keyFile) { kf.Nonce = kf.Nonce[:4] },
+		},
+		{
+			name:    "A key file asking for a huge scrypt cost is corrupt",
+			corrupt: func(kf *keyFile) { kf.KDF.N = 1 << 40 },
+		},
+		{
+			name:    "A key file asking for a huge scrypt block size is corrupt",
+			corrupt: func(kf *keyFile) { kf.KDF.R = 1 << 20 },
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ks, dir := tempKeystore(t)
+			defer os.RemoveAll(dir)
+
+			if _, err := ks.Create("lucille", "vodka rocks"); err != nil {
+				t.Fatal(err)
+			}
+
+			path := filepath.Join(dir, "lucille.json")
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var kf keyFile
+			if err := json.Unmarshal(b, &kf); err != nil {
+				t.Fatal(err)
+			}
+
+			c.corrupt(&kf)
+
+			b, err = json.Marshal(kf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := ioutil.WriteFile(path, b, 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := ks.Load("lucille", "vodka rocks"); !errors.Is(err, ErrCorrupt) {
+				t.Errorf("expected %v, got %v", ErrCorrupt, err)
+			}
+		})
+	}
+}
+
+func TestMnemonic(t *testing.T) {
+	ks, dir := tempKeystore(t)
+	defer os.RemoveAll(dir)
+
+	mnemonic, err := wallet.NewMnemonic()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ks.ImportMnemonic("maeby", mnemonic, "marry me"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ks.LoadMnemonic("maeby", "marry me")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != mnemonic {
+		t.Errorf("expected %q, got %q", mnemonic, got)
+	}
+
+	if _, err := ks.Load("maeby", "marry me"); !errors.Is(err, ErrWrongType) {
+		t.Errorf("expected %v loading single key from HD wallet, got %v", ErrWrongType, err)
+	}
+
+	if err := ks.ImportMnemonic("surely", "not a real seed phrase", "marry me"); !errors.Is(err, wallet.ErrInvalidMnemonic) {
+		t.Errorf("expected %v, got %v", wallet.ErrInvalidMnemonic, err)
+	}
+
+	entries, err := ks.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 || !entries[0].HD {
+		t.Errorf("expected a single HD entry, got %v", entries)
+	}
+}
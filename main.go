@@ -2,8 +2,7 @@ package main
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -15,10 +14,16 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/asgaines/blockchain/address"
 	"github.com/asgaines/blockchain/chain"
+	"github.com/asgaines/blockchain/keystore"
+	"github.com/asgaines/blockchain/mempool"
 	"github.com/asgaines/blockchain/mining"
 	"github.com/asgaines/blockchain/nodes"
+	"github.com/asgaines/blockchain/params"
 	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/asgaines/blockchain/transactions"
+	"github.com/asgaines/blockchain/wallet"
 	"google.golang.org/grpc"
 )
 
@@ -51,11 +56,16 @@ func main() {
 	var seedAddrsRaw string
 	var minPeers int
 	var maxPeers int
-	var targetDurPerBlock time.Duration
-	var recalcPeriod int
 	var speedArg string
 	var numMiners int
 	var filesPrefix string
+	var keystoreDir string
+	var walletName string
+	var networkName string
+	var mempoolSize int
+	var mempoolPerSender int
+	var mempoolTTL time.Duration
+	var syncModeArg string
 
 	flag.IntVar(&poolID, "poolid", 0, "The ID for a node within a single miner's pool (nodes with same pubkey).")
 	flag.StringVar(&bindAddr, "bindAddr", ":20403", "Local address to bind/listen on")
@@ -63,18 +73,22 @@ func main() {
 	flag.StringVar(&seedAddrsRaw, "seeds", "", "Seeding of potential peers for peer discovery. An optional comma-separated list of host/ips with port.")
 	flag.IntVar(&minPeers, "minpeers", 25, "The minimum number of peers to aim for; any fewer will trigger a peer discovery event")
 	flag.IntVar(&maxPeers, "maxpeers", 50, "The maximum number of peers to seed out to")
-	flag.DurationVar(&targetDurPerBlock, "targetdur", 10*time.Second, "The desired amount of time between block mining events; controls the difficulty of the mining")
-	flag.IntVar(&recalcPeriod, "recalc", 10, "How many blocks to solve before recalculating difficulty target")
 	flag.StringVar(&speedArg, "speed", "medium", "Speed of hashing, CPU usage. One of low/medium/high/ultra")
 	flag.IntVar(&numMiners, "miners", 1, "The number of concurrent miners to run, one per thread")
 	flag.StringVar(&filesPrefix, "filesprefix", "run", "Common prefix for all output files")
+	flag.StringVar(&keystoreDir, "keystore", "/storage/keystore", "Directory of the encrypted keystore holding the mining reward key")
+	flag.StringVar(&networkName, "network", address.Mainnet.Name, "The network to join. One of mainnet/testnet/utxonet")
+	flag.IntVar(&mempoolSize, "mempoolsize", mempool.DefaultConfig.MaxSize, "The maximum number of pending txs held; beyond it, those paying the lowest fee rate are evicted")
+	flag.IntVar(&mempoolPerSender, "mempoolpersender", mempool.DefaultConfig.MaxPerSender, "The maximum number of pending txs held from any one sender")
+	flag.DurationVar(&mempoolTTL, "mempoolttl", mempool.DefaultConfig.TTL, "How long a pending tx is held before being expired")
+	flag.StringVar(&syncModeArg, "sync", "headers", "How to fetch the chain of peers on start. One of headers/full: headers fetches and checks the header chain first, then the blocks in parallel from all peers")
+	flag.StringVar(&walletName, "wallet", "", "Name of the keystore key or HD wallet to receive mining rewards. Its passphrase is read from BLOCKCHAIN_PASSPHRASE")
 
 	flag.Parse()
 
-	key := os.Getenv("BLOCKCHAIN_KEY")
-	if key == "" {
+	if walletName == "" {
 		flag.Usage()
-		log.Fatal("Please set BLOCKCHAIN_KEY env variable")
+		log.Fatal("please include wallet, the name of a key created with `client wallet new`")
 	}
 
 	if returnAddr == "" {
@@ -85,52 +99,79 @@ func main() {
 		log.Fatal("invalid returnAddr")
 	}
 
+	netParams, err := params.ByName(networkName)
+	if err != nil {
+		flag.Usage()
+		log.Fatal(err)
+	}
+
 	speed, err := mining.ToSpeed(speedArg)
 	if err != nil {
 		flag.Usage()
 		log.Fatal(err)
 	}
 
+	syncMode, err := nodes.ToSyncMode(syncModeArg)
+	if err != nil {
+		flag.Usage()
+		log.Fatal(err)
+	}
+
 	var wg sync.WaitGroup
 	ctx, cancel := context.WithCancel(context.Background())
 
-	fmt.Println(ascii)
+	fmt.Print(ascii)
+
+	pubkey, rewardAccount, err := loadRewardKey(keystoreDir, walletName, os.Getenv("BLOCKCHAIN_PASSPHRASE"), poolID, netParams.Network)
+	if err != nil {
+		log.Fatalf("could not load wallet %s: %s", walletName, err)
+	}
 
-	hb := sha256.Sum256([]byte(key))
-	pubkey := hex.EncodeToString(hb[:])
-	log.Printf("Your public key is: %s", pubkey)
+	if rewardAccount != nil {
+		log.Printf("Your address is: %s (block rewards are paid to addresses derived from account %d of your HD wallet)", pubkey, poolID)
+	} else {
+		log.Printf("Your address is: %s", pubkey)
+	}
 
 	if _, _, err := net.SplitHostPort(bindAddr); err != nil {
 		log.Fatalf("invalid bindAddr: %s", bindAddr)
 	}
 
 	hasher := chain.NewHasher()
-	filesPrefix = fmt.Sprintf("%s_%dp_%dm", targetDurPerBlock, recalcPeriod, numMiners)
+	filesPrefix = fmt.Sprintf("%s_%dp_%dm", netParams.TargetDurPerBlock, netParams.RecalcPeriod, numMiners)
 	miners := make([]mining.Miner, 0, numMiners)
 
 	for n := 0; n < numMiners; n++ {
 		miners = append(miners, mining.NewMiner(
 			n,
 			pubkey,
-			targetDurPerBlock,
+			netParams.TargetDurPerBlock,
 			speed,
 			hasher,
 		))
 	}
 
+	txpool := mempool.New(mempool.Config{
+		MaxSize:      mempoolSize,
+		MaxPerSender: mempoolPerSender,
+		TTL:          mempoolTTL,
+	})
+
 	node := nodes.NewNode(
 		miners,
+		txpool,
 		pubkey,
+		netParams,
+		rewardAccount,
 		poolID,
 		minPeers,
 		maxPeers,
-		targetDurPerBlock,
-		recalcPeriod,
 		returnAddr,
 		strings.Split(seedAddrsRaw, ","),
 		speed,
 		filesPrefix,
 		hasher,
+		syncMode,
 	)
 
 	wg.Add(1)
@@ -157,7 +198,7 @@ func main() {
 		}
 		wg.Done()
 	}()
-	sigs := make(chan os.Signal)
+	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
@@ -170,3 +211,39 @@ func main() {
 
 	wg.Wait()
 }
+
+// loadRewardKey loads the key which mining rewards are paid to from the keystore.
+// For an HD wallet, the account matching the poolID is also returned, so that
+// each node of a pool pays its rewards to its own fresh addresses.
+func loadRewardKey(keystoreDir string, name string, passphrase string, poolID int, network *address.Network) (string, *wallet.Account, error) {
+	ks := keystore.New(keystoreDir)
+
+	privkey, err := ks.Load(name, passphrase)
+	if err == nil {
+		return transactions.Address(privkey, network), nil, nil
+	} else if !errors.Is(err, keystore.ErrWrongType) {
+		return "", nil, err
+	}
+
+	mnemonic, err := ks.LoadMnemonic(name, passphrase)
+	if err != nil {
+		return "", nil, err
+	}
+
+	master, err := wallet.FromMnemonic(mnemonic, "")
+	if err != nil {
+		return "", nil, err
+	}
+
+	account, err := wallet.NewAccount(master, uint32(poolID), network)
+	if err != nil {
+		return "", nil, err
+	}
+
+	pubkey, err := account.Address(0)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return pubkey, account, nil
+}
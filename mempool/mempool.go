@@ -0,0 +1,384 @@
+package mempool
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/asgaines/blockchain/transactions"
+	"github.com/asgaines/blockchain/utxo"
+	"github.com/golang/protobuf/proto"
+)
+
+var (
+	// ErrExists is returned when adding a tx already in the pool
+	ErrExists = errors.New("tx already in pool")
+	// ErrNonceUsed is returned when adding a tx whose nonce is already used by
+	// a pending tx of the same sender
+	ErrNonceUsed = errors.New("nonce already used by a pending tx")
+	// ErrDoubleSpend is returned when adding a tx spending an output already
+	// spent by a pending tx
+	ErrDoubleSpend = errors.New("output already spent by a pending tx")
+	// ErrSenderLimit is returned when the sender already has the maximum
+	// number of txs pending
+	ErrSenderLimit = errors.New("too many pending txs from sender")
+	// ErrFull is returned when the pool is full and the tx does not pay a high
+	// enough fee rate to evict another
+	ErrFull = errors.New("pool is full and tx fee rate too low")
+)
+
+// Config sets the limits of a Mempool
+type Config struct {
+	// MaxSize is the maximum number of txs held in the pool. Once reached, the
+	// txs paying the lowest fee rate are evicted to make room
+	MaxSize int
+	// MaxPerSender is the maximum number of pending txs from any one sender
+	MaxPerSender int
+	// TTL is how long a tx can wait in the pool before being expired. A
+	// locked tx only starts waiting once it matures
+	TTL time.Duration
+}
+
+// DefaultConfig holds the limits used when none are given
+var DefaultConfig = Config{
+	MaxSize:      5000,
+	MaxPerSender: 64,
+	TTL:          24 * time.Hour,
+}
+
+// Mempool holds the txs waiting to be included in a block. It is safe for
+// concurrent use.
+type Mempool interface {
+	// Add inserts the tx into the pool, evicting the lowest fee rate tx if
+	// the pool is full
+	Add(tx *pb.Tx) error
+	// Get returns the tx with the hash, if pending
+	Get(hash []byte) (*pb.Tx, bool)
+	// Has reports whether the tx with the hash is pending
+	Has(hash []byte) bool
+	// Remove drops the txs with the hashes from the pool
+	Remove(hashes ...[]byte)
+	// BySender returns the pending txs of the sender, in nonce order
+	BySender(sender string) []*pb.Tx
+	// Spender returns the pending tx spending the output the outpoint refers to
+	Spender(op *pb.OutPoint) (*pb.Tx, bool)
+	// All returns every pending tx, in the order they were added
+	All() []*pb.Tx
+	// Mature returns the pending txs which can be included in the block at the
+	// height, timestamped at now, in the order they were added. Locked txs
+	// are held back until then.
+	Mature(height uint64, now time.Time) []*pb.Tx
+	// Expire drops and returns the txs which have outlived the TTL by now,
+	// counted from when they matured for the block at the height, along with
+	// the txs of their senders of higher nonces, which no block could include
+	// without them
+	Expire(height uint64, now time.Time) []*pb.Tx
+	// Len returns the number of pending txs
+	Len() int
+}
+
+// New instantiates an empty Mempool with the limits of the config
+func New(cfg Config) Mempool {
+	mp := mempool{
+		cfg:      cfg,
+		byHash:   make(map[string]*entry),
+		bySender: make(map[string]map[string]*entry),
+		byNonce:  make(map[string]map[uint64]*entry),
+		spends:   make(map[string]*entry),
+		now:      time.Now,
+	}
+
+	return &mp
+}
+
+type entry struct {
+	tx    *pb.Tx
+	added time.Time
+	// matured is when the tx was first seen mature, which is when it was
+	// added unless locked. It is zero until then.
+	matured time.Time
+	seq     uint64
+}
+
+type mempool struct {
+	cfg      Config
+	byHash   map[string]*entry
+	bySender map[string]map[string]*entry
+	// byNonce indexes the txs of the account ledger, which are ordered by
+	// nonce. Txs of the UTXO ledger are instead kept apart by their spends.
+	byNonce map[string]map[uint64]*entry
+	spends  map[string]*entry
+	seq     uint64
+	now     func() time.Time
+	mu      sync.RWMutex
+}
+
+// FeeRate is the fee a tx pays per byte of block space it takes up
+func FeeRate(tx *pb.Tx) float64 {
+	return float64(tx.GetFee()) / float64(proto.Size(tx))
+}
+
+func (mp *mempool) Add(tx *pb.Tx) error {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	if _, ok := mp.byHash[string(tx.GetHash())]; ok {
+		return ErrExists
+	}
+
+	sender := tx.GetSender()
+	if len(tx.GetInputs()) == 0 {
+		if _, ok := mp.byNonce[sender][tx.GetNonce()]; ok {
+			return fmt.Errorf("%w: %d", ErrNonceUsed, tx.GetNonce())
+		}
+	}
+
+	for _, op := range tx.GetInputs() {
+		if _, ok := mp.spends[utxo.Key(op)]; ok {
+			return fmt.Errorf("%w: %s", ErrDoubleSpend, utxo.Key(op))
+		}
+	}
+
+	if mp.cfg.MaxPerSender > 0 && len(mp.bySender[sender]) >= mp.cfg.MaxPerSender {
+		return ErrSenderLimit
+	}
+
+	if mp.cfg.MaxSize > 0 && len(mp.byHash) >= mp.cfg.MaxSize {
+		victim := mp.evictionCandidate(sender)
+		if victim == nil || FeeRate(victim.tx) >= FeeRate(tx) {
+			return ErrFull
+		}
+
+		mp.remove(victim)
+	}
+
+	e := &entry{
+		tx:    tx,
+		added: mp.now(),
+		seq:   mp.seq,
+	}
+	mp.seq++
+
+	if tx.GetLock() == 0 {
+		e.matured = e.added
+	}
+
+	mp.byHash[string(tx.GetHash())] = e
+	if _, ok := mp.bySender[sender]; !ok {
+		mp.bySender[sender] = make(map[string]*entry)
+	}
+	mp.bySender[sender][string(tx.GetHash())] = e
+
+	if len(tx.GetInputs()) == 0 {
+		if _, ok := mp.byNonce[sender]; !ok {
+			mp.byNonce[sender] = make(map[uint64]*entry)
+		}
+		mp.byNonce[sender][tx.GetNonce()] = e
+	}
+
+	for _, op := range tx.GetInputs() {
+		mp.spends[utxo.Key(op)] = e
+	}
+
+	return nil
+}
+
+// evictionCandidate finds the tx paying the lowest fee rate among the highest
+// nonce txs of each sender, so evicting it leaves no gap in another tx's nonce
+// sequence. The sender of the tx being added is excluded for the same reason.
+func (mp *mempool) evictionCandidate(except string) *entry {
+	var victim *entry
+
+	for sender, txs := range mp.bySender {
+		if sender == except {
+			continue
+		}
+
+		var last *entry
+		for _, e := range txs {
+			if last == nil || e.tx.GetNonce() > last.tx.GetNonce() ||
+				(e.tx.GetNonce() == last.tx.GetNonce() && e.seq > last.seq) {
+				last = e
+			}
+		}
+
+		if victim == nil || FeeRate(last.tx) < FeeRate(victim.tx) ||
+			(FeeRate(last.tx) == FeeRate(victim.tx) && last.seq > victim.seq) {
+			victim = last
+		}
+	}
+
+	return victim
+}
+
+func (mp *mempool) Get(hash []byte) (*pb.Tx, bool) {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+
+	e, ok := mp.byHash[string(hash)]
+	if !ok {
+		return nil, false
+	}
+
+	return e.tx, true
+}
+
+func (mp *mempool) Has(hash []byte) bool {
+	_, ok := mp.Get(hash)
+	return ok
+}
+
+func (mp *mempool) Remove(hashes ...[]byte) {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	for _, hash := range hashes {
+		if e, ok := mp.byHash[string(hash)]; ok {
+			mp.remove(e)
+		}
+	}
+}
+
+func (mp *mempool) remove(e *entry) {
+	delete(mp.byHash, string(e.tx.GetHash()))
+
+	sender := e.tx.GetSender()
+	delete(mp.bySender[sender], string(e.tx.GetHash()))
+	if len(mp.bySender[sender]) == 0 {
+		delete(mp.bySender, sender)
+	}
+
+	if len(e.tx.GetInputs()) == 0 {
+		delete(mp.byNonce[sender], e.tx.GetNonce())
+		if len(mp.byNonce[sender]) == 0 {
+			delete(mp.byNonce, sender)
+		}
+	}
+
+	for _, op := range e.tx.GetInputs() {
+		delete(mp.spends, utxo.Key(op))
+	}
+}
+
+func (mp *mempool) BySender(sender string) []*pb.Tx {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+
+	entries := make([]*entry, 0, len(mp.bySender[sender]))
+	for _, e := range mp.bySender[sender] {
+		entries = append(entries, e)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].tx.GetNonce() != entries[j].tx.GetNonce() {
+			return entries[i].tx.GetNonce() < entries[j].tx.GetNonce()
+		}
+		return entries[i].seq < entries[j].seq
+	})
+
+	txs := make([]*pb.Tx, 0, len(entries))
+	for _, e := range entries {
+		txs = append(txs, e.tx)
+	}
+
+	return txs
+}
+
+func (mp *mempool) Spender(op *pb.OutPoint) (*pb.Tx, bool) {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+
+	e, ok := mp.spends[utxo.Key(op)]
+	if !ok {
+		return nil, false
+	}
+
+	return e.tx, true
+}
+
+func (mp *mempool) All() []*pb.Tx {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+
+	entries := make([]*entry, 0, len(mp.byHash))
+	for _, e := range mp.byHash {
+		entries = append(entries, e)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].seq < entries[j].seq
+	})
+
+	txs := make([]*pb.Tx, 0, len(entries))
+	for _, e := range entries {
+		txs = append(txs, e.tx)
+	}
+
+	return txs
+}
+
+func (mp *mempool) Mature(height uint64, now time.Time) []*pb.Tx {
+	mature := make([]*pb.Tx, 0)
+	for _, tx := range mp.All() {
+		if transactions.IsMature(tx, height, now) {
+			mature = append(mature, tx)
+		}
+	}
+
+	return mature
+}
+
+func (mp *mempool) Expire(height uint64, now time.Time) []*pb.Tx {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	expired := make([]*pb.Tx, 0)
+	if mp.cfg.TTL <= 0 {
+		return expired
+	}
+
+	// gaps holds the lowest nonce expired of each sender
+	gaps := make(map[string]uint64)
+
+	for _, e := range mp.byHash {
+		if e.matured.IsZero() {
+			if !transactions.IsMature(e.tx, height, now) {
+				continue
+			}
+			e.matured = now
+		}
+
+		if now.Sub(e.matured) > mp.cfg.TTL {
+			expired = append(expired, e.tx)
+			mp.remove(e)
+
+			if len(e.tx.GetInputs()) == 0 {
+				sender := e.tx.GetSender()
+				if nonce, ok := gaps[sender]; !ok || e.tx.GetNonce() < nonce {
+					gaps[sender] = e.tx.GetNonce()
+				}
+			}
+		}
+	}
+
+	for sender, gap := range gaps {
+		for nonce, e := range mp.byNonce[sender] {
+			if nonce > gap {
+				expired = append(expired, e.tx)
+				mp.remove(e)
+			}
+		}
+	}
+
+	return expired
+}
+
+func (mp *mempool) Len() int {
+	mp.mu.RLock()
+	defer mp.mu.RUnlock()
+
+	return len(mp.byHash)
+}
@@ -0,0 +1,330 @@
+package mempool
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/asgaines/blockchain/transactions"
+)
+
+func newTx(sender string, nonce uint64, fee uint64) *pb.Tx {
+	tx := &pb.Tx{
+		Sender: sender,
+		Nonce:  nonce,
+		Fee:    fee,
+	}
+	transactions.SetHash(tx)
+
+	return tx
+}
+
+func newSpend(sender string, index uint32, fee uint64) *pb.Tx {
+	tx := &pb.Tx{
+		Sender: sender,
+		Fee:    fee,
+		Inputs: []*pb.OutPoint{
+			{TxHash: []byte("banana stand"), Index: index},
+		},
+	}
+	transactions.SetHash(tx)
+
+	return tx
+}
+
+func TestAdd(t *testing.T) {
+	cases := []struct {
+		name     string
+		cfg      Config
+		existing []*pb.Tx
+		tx       *pb.Tx
+		err      error
+	}{
+		{
+			name: "A tx is added to an empty pool",
+			cfg:  DefaultConfig,
+			tx:   newTx("Lindsay", 0, 0),
+			err:  nil,
+		},
+		{
+			name:     "A tx already in the pool is refused",
+			cfg:      DefaultConfig,
+			existing: []*pb.Tx{newTx("Lindsay", 0, 0)},
+			tx:       newTx("Lindsay", 0, 0),
+			err:      ErrExists,
+		},
+		{
+			name:     "A different tx reusing a pending nonce is refused",
+			cfg:      DefaultConfig,
+			existing: []*pb.Tx{newTx("Lindsay", 0, 0)},
+			tx:       newTx("Lindsay", 0, 1),
+			err:      ErrNonceUsed,
+		},
+		{
+			name:     "A tx spending an output already spent by a pending tx is refused",
+			cfg:      DefaultConfig,
+			existing: []*pb.Tx{newSpend("Lindsay", 0, 0)},
+			tx:       newSpend("Lindsay", 0, 1),
+			err:      ErrDoubleSpend,
+		},
+		{
+			name:     "Txs spending different outputs need no distinct nonces",
+			cfg:      DefaultConfig,
+			existing: []*pb.Tx{newSpend("Lindsay", 0, 0)},
+			tx:       newSpend("Lindsay", 1, 0),
+			err:      nil,
+		},
+		{
+			name: "A sender at their limit of pending txs is refused",
+			cfg: Config{
+				MaxSize:      10,
+				MaxPerSender: 2,
+			},
+			existing: []*pb.Tx{newTx("Lindsay", 0, 0), newTx("Lindsay", 1, 0)},
+			tx:       newTx("Lindsay", 2, 0),
+			err:      ErrSenderLimit,
+		},
+		{
+			name: "A full pool evicts a lower fee rate tx for a higher one",
+			cfg: Config{
+				MaxSize: 1,
+			},
+			existing: []*pb.Tx{newTx("Lindsay", 0, 1)},
+			tx:       newTx("Tobias", 0, 2),
+			err:      nil,
+		},
+		{
+			name: "A full pool refuses a tx not paying more than any it holds",
+			cfg: Config{
+				MaxSize: 1,
+			},
+			existing: []*pb.Tx{newTx("Lindsay", 0, 2)},
+			tx:       newTx("Tobias", 0, 1),
+			err:      ErrFull,
+		},
+		{
+			name: "A full pool does not evict a tx of the same sender",
+			cfg: Config{
+				MaxSize: 1,
+			},
+			existing: []*pb.Tx{newTx("Lindsay", 0, 1)},
+			tx:       newTx("Lindsay", 1, 2),
+			err:      ErrFull,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mp := New(c.cfg)
+			for _, tx := range c.existing {
+				if err := mp.Add(tx); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := mp.Add(c.tx)
+
+			if !errors.Is(err, c.err) {
+				t.Errorf("expected %v, got %v", c.err, err)
+			}
+
+			if c.err == nil && !mp.Has(c.tx.GetHash()) {
+				t.Errorf("expected tx to be in pool")
+			}
+
+			if c.cfg.MaxSize > 0 && mp.Len() > c.cfg.MaxSize {
+				t.Errorf("expected at most %d txs, got %d", c.cfg.MaxSize, mp.Len())
+			}
+		})
+	}
+}
+
+func TestEvictsHighestNonce(t *testing.T) {
+	mp := New(Config{MaxSize: 2})
+
+	first, second := newTx("Lindsay", 0, 1), newTx("Lindsay", 1, 1)
+	for _, tx := range []*pb.Tx{first, second} {
+		if err := mp.Add(tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := mp.Add(newTx("Tobias", 0, 5)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !mp.Has(first.GetHash()) || mp.Has(second.GetHash()) {
+		t.Errorf("expected the sender's later tx to be evicted, leaving no nonce gap")
+	}
+}
+
+func TestOrdering(t *testing.T) {
+	mp := New(DefaultConfig)
+
+	txs := []*pb.Tx{
+		newTx("Lindsay", 2, 0),
+		newTx("Tobias", 0, 0),
+		newTx("Lindsay", 0, 0),
+		newTx("Lindsay", 1, 0),
+	}
+	for _, tx := range txs {
+		if err := mp.Add(tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	all := mp.All()
+	for i := range txs {
+		if all[i] != txs[i] {
+			t.Errorf("expected txs in order added, got %v", all)
+			break
+		}
+	}
+
+	bySender := mp.BySender("Lindsay")
+	if len(bySender) != 3 {
+		t.Fatalf("expected 3 txs from sender, got %v", bySender)
+	}
+
+	for i, tx := range bySender {
+		if tx.GetNonce() != uint64(i) {
+			t.Errorf("expected txs in nonce order, got %v", bySender)
+			break
+		}
+	}
+
+	mp.Remove(txs[2].GetHash())
+	if _, ok := mp.Get(txs[2].GetHash()); ok || mp.Len() != 3 {
+		t.Errorf("expected tx to be removed")
+	}
+}
+
+func TestExpire(t *testing.T) {
+	now := time.Date(2003, 11, 2, 0, 0, 0, 0, time.UTC)
+
+	mp := &mempool{
+		cfg:      Config{TTL: time.Hour},
+		byHash:   make(map[string]*entry),
+		bySender: make(map[string]map[string]*entry),
+		byNonce:  make(map[string]map[uint64]*entry),
+		spends:   make(map[string]*entry),
+		now:      func() time.Time { return now },
+	}
+
+	old := newTx("Lindsay", 0, 0)
+	if err := mp.Add(old); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(30 * time.Minute)
+	recent := newTx("Tobias", 0, 0)
+	if err := mp.Add(recent); err != nil {
+		t.Fatal(err)
+	}
+
+	expired := mp.Expire(0, now.Add(45*time.Minute))
+
+	if len(expired) != 1 || expired[0] != old {
+		t.Errorf("expected only the old tx to expire, got %v", expired)
+	}
+
+	if mp.Has(old.GetHash()) || !mp.Has(recent.GetHash()) {
+		t.Errorf("expected only the recent tx to remain")
+	}
+}
+
+func TestExpireGap(t *testing.T) {
+	now := time.Date(2003, 11, 2, 0, 0, 0, 0, time.UTC)
+
+	mp := New(Config{TTL: time.Hour}).(*mempool)
+	mp.now = func() time.Time { return now }
+
+	middle := newTx("Lindsay", 1, 0)
+	if err := mp.Add(middle); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(30 * time.Minute)
+	first := newTx("Lindsay", 0, 0)
+	last := newTx("Lindsay", 2, 0)
+	other := newTx("Tobias", 3, 0)
+	for _, tx := range []*pb.Tx{first, last, other} {
+		if err := mp.Add(tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expired := mp.Expire(0, now.Add(45*time.Minute))
+
+	if len(expired) != 2 {
+		t.Errorf("expected the middle tx and the one after it to expire, got %v", expired)
+	}
+
+	if mp.Has(middle.GetHash()) || mp.Has(last.GetHash()) {
+		t.Errorf("expected the txs from the expired nonce on to be dropped")
+	}
+
+	if !mp.Has(first.GetHash()) || !mp.Has(other.GetHash()) {
+		t.Errorf("expected the tx before the gap and those of other senders to remain")
+	}
+}
+
+func TestSpender(t *testing.T) {
+	mp := New(DefaultConfig)
+
+	spend := newSpend("Lindsay", 0, 0)
+	if err := mp.Add(spend); err != nil {
+		t.Fatal(err)
+	}
+
+	op := spend.GetInputs()[0]
+	if got, ok := mp.Spender(op); !ok || got != spend {
+		t.Errorf("expected pending tx to spend output, got %v", got)
+	}
+
+	mp.Remove(spend.GetHash())
+	if _, ok := mp.Spender(op); ok {
+		t.Errorf("expected output to be unspent once tx is removed")
+	}
+
+	if err := mp.Add(newSpend("Lindsay", 0, 1)); err != nil {
+		t.Errorf("expected output to be spendable again, got %v", err)
+	}
+}
+
+func TestExpireLocked(t *testing.T) {
+	now := time.Date(2003, 11, 2, 0, 0, 0, 0, time.UTC)
+
+	mp := New(Config{TTL: time.Hour}).(*mempool)
+	mp.now = func() time.Time { return now }
+
+	locked := newTx("Lindsay", 0, 0)
+	locked.Lock = 10
+	transactions.SetHash(locked)
+
+	if err := mp.Add(locked); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if expired := mp.Expire(9, now); len(expired) != 0 {
+		t.Errorf("expected locked tx not to expire before it matures, got %v", expired)
+	}
+
+	if mature := mp.Mature(9, now); len(mature) != 0 {
+		t.Errorf("expected locked tx to be held back, got %v", mature)
+	}
+
+	if mature := mp.Mature(10, now); len(mature) != 1 || mature[0] != locked {
+		t.Errorf("expected tx to be mature at its lock height, got %v", mature)
+	}
+
+	if expired := mp.Expire(10, now); len(expired) != 0 {
+		t.Errorf("expected tx to start waiting once mature, got %v", expired)
+	}
+
+	if expired := mp.Expire(10, now.Add(61*time.Minute)); len(expired) != 1 || expired[0] != locked {
+		t.Errorf("expected tx to expire a TTL after it matured, got %v", expired)
+	}
+}
@@ -0,0 +1,116 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+)
+
+// Prefixes hashed ahead of leaves and interior nodes, so that no interior node
+// can be passed off as a tx hash, nor a tx as a pair of nodes
+const (
+	leafPrefix byte = 0
+	nodePrefix byte = 1
+)
+
+// ErrIndexOutOfRange is returned when asked for the proof of a tx the block
+// does not have
+var ErrIndexOutOfRange = errors.New("tx index out of range")
+
+// Root computes the root of the binary merkle tree over the hashes of the txs,
+// in order. The last node of a level with an odd number of them is carried up
+// to the next level as it is. The root of no txs is the hash of nothing.
+func Root(txs []*pb.Tx) []byte {
+	if len(txs) == 0 {
+		h := sha256.Sum256(nil)
+		return h[:]
+	}
+
+	level := leaves(txs)
+	for len(level) > 1 {
+		level = parents(level)
+	}
+
+	return level[0]
+}
+
+// Proof returns the path from the hash of the tx at the index up to the root
+// of the txs: the sibling of each node along the way, lowest first. A node
+// carried up without a sibling adds no step.
+func Proof(txs []*pb.Tx, index int) ([]*pb.MerkleStep, error) {
+	if index < 0 || index >= len(txs) {
+		return nil, ErrIndexOutOfRange
+	}
+
+	path := make([]*pb.MerkleStep, 0)
+
+	level := leaves(txs)
+	for len(level) > 1 {
+		if index%2 == 1 {
+			path = append(path, &pb.MerkleStep{Hash: level[index-1], Left: true})
+		} else if index+1 < len(level) {
+			path = append(path, &pb.MerkleStep{Hash: level[index+1], Left: false})
+		}
+
+		level = parents(level)
+		index /= 2
+	}
+
+	return path, nil
+}
+
+// Verify reports whether the path leads from the tx hash up to the root, so
+// proving the tx included in any block whose header has that merkle root
+func Verify(txHash []byte, path []*pb.MerkleStep, root []byte) bool {
+	node := hashLeaf(txHash)
+
+	for _, step := range path {
+		if step.GetLeft() {
+			node = hashNode(step.GetHash(), node)
+		} else {
+			node = hashNode(node, step.GetHash())
+		}
+	}
+
+	return bytes.Equal(node, root)
+}
+
+func leaves(txs []*pb.Tx) [][]byte {
+	level := make([][]byte, 0, len(txs))
+	for _, tx := range txs {
+		level = append(level, hashLeaf(tx.GetHash()))
+	}
+
+	return level
+}
+
+func parents(level [][]byte) [][]byte {
+	next := make([][]byte, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		if i+1 == len(level) {
+			next = append(next, level[i])
+			continue
+		}
+
+		next = append(next, hashNode(level[i], level[i+1]))
+	}
+
+	return next
+}
+
+func hashLeaf(txHash []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(txHash)
+	return h.Sum(nil)
+}
+
+func hashNode(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
@@ -0,0 +1,140 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"testing"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+)
+
+func txsOf(n int) []*pb.Tx {
+	txs := make([]*pb.Tx, 0, n)
+	for i := 0; i < n; i++ {
+		h := sha256.Sum256([]byte(fmt.Sprint(i)))
+		txs = append(txs, &pb.Tx{Hash: h[:]})
+	}
+
+	return txs
+}
+
+func TestRoot(t *testing.T) {
+	txs := txsOf(3)
+	a, b, c := hashLeaf(txs[0].GetHash()), hashLeaf(txs[1].GetHash()), hashLeaf(txs[2].GetHash())
+	empty := sha256.Sum256(nil)
+
+	cases := []struct {
+		name     string
+		txs      []*pb.Tx
+		expected []byte
+	}{
+		{
+			name:     "The root of no txs is the hash of nothing",
+			txs:      nil,
+			expected: empty[:],
+		},
+		{
+			name:     "The root of a single tx is its leaf",
+			txs:      txs[:1],
+			expected: a,
+		},
+		{
+			name:     "The root of two txs combines their leaves",
+			txs:      txs[:2],
+			expected: hashNode(a, b),
+		},
+		{
+			name:     "The last node of an odd level is carried up as it is",
+			txs:      txs,
+			expected: hashNode(hashNode(a, b), c),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Root(c.txs); !bytes.Equal(got, c.expected) {
+				t.Errorf("expected %x, got %x", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestProof(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		txs := txsOf(n)
+		root := Root(txs)
+
+		for i, tx := range txs {
+			path, err := Proof(txs, i)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !Verify(tx.GetHash(), path, root) {
+				t.Errorf("expected proof of tx %d of %d to verify", i, n)
+			}
+
+			// Proving another tx with the path must fail
+			other := txs[(i+1)%n]
+			if n > 1 && Verify(other.GetHash(), path, root) {
+				t.Errorf("expected proof of tx %d of %d not to verify tx %d", i, n, (i+1)%n)
+			}
+		}
+	}
+}
+
+func TestVerifyTampered(t *testing.T) {
+	txs := txsOf(5)
+	root := Root(txs)
+
+	path, err := Proof(txs, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		tamper func(path []*pb.MerkleStep) []*pb.MerkleStep
+	}{
+		{
+			name: "A step with a changed hash does not verify",
+			tamper: func(path []*pb.MerkleStep) []*pb.MerkleStep {
+				path[0] = &pb.MerkleStep{Hash: []byte("Gob"), Left: path[0].GetLeft()}
+				return path
+			},
+		},
+		{
+			name: "A step on the wrong side does not verify",
+			tamper: func(path []*pb.MerkleStep) []*pb.MerkleStep {
+				path[0] = &pb.MerkleStep{Hash: path[0].GetHash(), Left: !path[0].GetLeft()}
+				return path
+			},
+		},
+		{
+			name: "A path missing a step does not verify",
+			tamper: func(path []*pb.MerkleStep) []*pb.MerkleStep {
+				return path[1:]
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tampered := c.tamper(append([]*pb.MerkleStep{}, path...))
+
+			if Verify(txs[2].GetHash(), tampered, root) {
+				t.Errorf("expected tampered path not to verify")
+			}
+		})
+	}
+}
+
+func TestProofOutOfRange(t *testing.T) {
+	for _, index := range []int{-1, 3} {
+		if _, err := Proof(txsOf(3), index); !errors.Is(err, ErrIndexOutOfRange) {
+			t.Errorf("expected index %d to be out of range, got %v", index, err)
+		}
+	}
+}
@@ -108,19 +108,24 @@ func (m *miner) SetTarget(difficulty float64) error {
 		return fmt.Errorf("minimum difficulty is 1, cannot set target based on value %v", difficulty)
 	}
 
+	m.target = TargetFor(difficulty)
+	log.Printf("%064x (target)", m.target)
+
+	return nil
+}
+
+// TargetFor is the target a block mined at the difficulty must meet: MaxTarget
+// divided by the difficulty, capped at MaxTarget
+func TargetFor(difficulty float64) []byte {
 	diffF := new(big.Float).SetFloat64(difficulty)
 
 	target, _ := new(big.Float).Quo(new(big.Float).SetInt(MaxTarget), diffF).Int(nil)
 
 	if target.Cmp(MaxTarget) == 1 {
-		m.target = new(big.Int).Set(MaxTarget).Bytes()
-		return nil
+		return new(big.Int).Set(MaxTarget).Bytes()
 	}
 
-	m.target = target.Bytes()
-	log.Printf("%064x (target)", m.target)
-
-	return nil
+	return target.Bytes()
 }
 
 func (m *miner) SetTxs(txs []*pb.Tx) {
@@ -0,0 +1,87 @@
+package nettime
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// MinSamples is the number of peer clock offsets needed before the local
+	// time is adjusted
+	MinSamples = 5
+	// MaxSamples is the most peers whose clock offset is kept
+	MaxSamples = 200
+	// MaxAdjustment is the furthest the local time is adjusted. Should the
+	// median offset of peers be further, the local time is kept as it is.
+	MaxAdjustment = 70 * time.Minute
+)
+
+// Clock tells the network-adjusted time: the local time, corrected by the
+// median offset of the clocks of peers from it. It is safe for concurrent use.
+type Clock interface {
+	// Now returns the network-adjusted time
+	Now() time.Time
+	// Offset returns the correction made to the local time
+	Offset() time.Duration
+	// AddSample records the offset of the clock of the source, a peer, from the
+	// local clock. Only the first sample of each source is kept.
+	AddSample(source string, offset time.Duration)
+}
+
+// New instantiates a Clock with no samples, telling the local time
+func New() Clock {
+	c := clock{
+		samples: make(map[string]time.Duration),
+		now:     time.Now,
+	}
+
+	return &c
+}
+
+type clock struct {
+	samples map[string]time.Duration
+	offset  time.Duration
+	now     func() time.Time
+	mu      sync.RWMutex
+}
+
+func (c *clock) Now() time.Time {
+	return c.now().Add(c.Offset())
+}
+
+func (c *clock) Offset() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.offset
+}
+
+func (c *clock) AddSample(source string, offset time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.samples[source]; ok || len(c.samples) >= MaxSamples {
+		return
+	}
+	c.samples[source] = offset
+
+	if len(c.samples) < MinSamples {
+		return
+	}
+
+	offsets := make([]time.Duration, 0, len(c.samples))
+	for _, o := range c.samples {
+		offsets = append(offsets, o)
+	}
+	sort.Slice(offsets, func(i, j int) bool {
+		return offsets[i] < offsets[j]
+	})
+
+	median := offsets[len(offsets)/2]
+	if median > MaxAdjustment || median < -MaxAdjustment {
+		median = 0
+	}
+
+	c.offset = median
+}
@@ -0,0 +1,76 @@
+package nettime
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestOffset(t *testing.T) {
+	cases := []struct {
+		name     string
+		offsets  []time.Duration
+		expected time.Duration
+	}{
+		{
+			name:     "Too few samples leave the local time as it is",
+			offsets:  []time.Duration{time.Minute, time.Minute, time.Minute, time.Minute},
+			expected: 0,
+		},
+		{
+			name:     "The local time is corrected by the median offset",
+			offsets:  []time.Duration{-time.Hour, time.Second, 2 * time.Second, 3 * time.Second, time.Hour},
+			expected: 2 * time.Second,
+		},
+		{
+			name:     "A median offset beyond the furthest adjustment is ignored",
+			offsets:  []time.Duration{2 * time.Hour, 2 * time.Hour, 2 * time.Hour, 2 * time.Hour, 2 * time.Hour},
+			expected: 0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			clock := New()
+			for i, offset := range c.offsets {
+				clock.AddSample(fmt.Sprint(i), offset)
+			}
+
+			if got := clock.Offset(); got != c.expected {
+				t.Errorf("expected %v, got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestAddSampleOncePerSource(t *testing.T) {
+	clock := New()
+
+	// A single peer cannot outvote the rest by repeating itself
+	for i := 0; i < 4; i++ {
+		clock.AddSample(fmt.Sprint(i), 0)
+	}
+	for i := 0; i < 10; i++ {
+		clock.AddSample("Gob", time.Hour)
+	}
+
+	if got := clock.Offset(); got != 0 {
+		t.Errorf("expected no offset, got %v", got)
+	}
+}
+
+func TestNow(t *testing.T) {
+	local := time.Date(2003, 11, 2, 0, 0, 0, 0, time.UTC)
+	clock := &clock{
+		samples: make(map[string]time.Duration),
+		now:     func() time.Time { return local },
+	}
+
+	for i := 0; i < MinSamples; i++ {
+		clock.AddSample(fmt.Sprint(i), time.Minute)
+	}
+
+	if got := clock.Now(); !got.Equal(local.Add(time.Minute)) {
+		t.Errorf("expected %v, got %v", local.Add(time.Minute), got)
+	}
+}
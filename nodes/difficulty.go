@@ -0,0 +1,81 @@
+package nodes
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/asgaines/blockchain/chain"
+	"github.com/asgaines/blockchain/mining"
+)
+
+// MinDifficulty is the lowest difficulty a block can be required to meet, that
+// of the highest target
+const MinDifficulty float64 = 1
+
+// ErrWrongTarget is when a block declares a target other than the one required
+// of it by the blocks before it
+var ErrWrongTarget = errors.New("target does not match the difficulty required")
+
+// initialDifficulty is the difficulty required of the first blocks of a chain,
+// until it is first recalculated
+func (n *node) initialDifficulty() float64 {
+	return math.Max(MinDifficulty, n.genesisDifficulty)
+}
+
+// nextDifficulty is the difficulty required of the block after the one at the
+// height, the block at the height having been required to meet difficulty. It
+// is recalculated every recalcPeriod blocks, from how long those took to mine
+// as told by their timestamps.
+func (n *node) nextDifficulty(c *chain.Chain, height uint64, difficulty float64) float64 {
+	// A node without a recalc period holds the difficulty where it started
+	if n.recalcPeriod <= 0 {
+		return difficulty
+	}
+
+	period := uint64(n.recalcPeriod)
+	if height == 0 || height%period != 0 {
+		return difficulty
+	}
+
+	from := blockTime(c.Pbc.Blocks[height-period])
+	to := blockTime(c.Pbc.Blocks[height])
+
+	// Blocks timestamped out of order took no time at all, so raise the
+	// difficulty as far as it goes rather than lower it
+	avgBlockDur := to.Sub(from) / time.Duration(period)
+	if avgBlockDur < 1 {
+		avgBlockDur = 1
+	}
+
+	return math.Max(MinDifficulty, n.calcDifficulty(avgBlockDur, difficulty))
+}
+
+// requiredDifficulty is the difficulty the block at the height is required to
+// meet by the blocks of the chain before it
+func (n *node) requiredDifficulty(c *chain.Chain, height uint64) float64 {
+	difficulty := n.initialDifficulty()
+	if n.recalcPeriod <= 0 {
+		return difficulty
+	}
+
+	for h := uint64(n.recalcPeriod); h < height; h += uint64(n.recalcPeriod) {
+		difficulty = n.nextDifficulty(c, h, difficulty)
+	}
+
+	return difficulty
+}
+
+// checkTarget ensures the block declares the target of the difficulty required
+// of it. Its hash meeting that target is left to checkLink.
+func checkTarget(block *chain.Block, difficulty float64) error {
+	required := new(big.Int).SetBytes(mining.TargetFor(difficulty))
+
+	if new(big.Int).SetBytes(block.Target).Cmp(required) != 0 {
+		return fmt.Errorf("%w: declares %x, required %x", ErrWrongTarget, block.Target, required.Bytes())
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package nodes
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/asgaines/blockchain/chain"
+	"github.com/asgaines/blockchain/mining"
+	"github.com/asgaines/blockchain/nettime"
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+func TestRequiredDifficulty(t *testing.T) {
+	// chainAt builds a chain of blocks timestamped at the seconds
+	chainAt := func(seconds ...int64) *chain.Chain {
+		blocks := make([]*pb.Block, len(seconds))
+		for i, s := range seconds {
+			blocks[i] = &pb.Block{Timestamp: &timestamp.Timestamp{Seconds: s}}
+		}
+
+		return &chain.Chain{
+			Pbc: &pb.Chain{
+				Blocks: blocks,
+			},
+		}
+	}
+
+	cases := []struct {
+		name              string
+		chain             *chain.Chain
+		genesisDifficulty float64
+		height            uint64
+		expected          float64
+	}{
+		{
+			name:              "Blocks before the first recalculation are required the genesis difficulty",
+			chain:             chainAt(0, 1),
+			genesisDifficulty: 100,
+			height:            2,
+			expected:          100,
+		},
+		{
+			name:              "Blocks mined at the desired duration hold the difficulty",
+			chain:             chainAt(0, 100, 200),
+			genesisDifficulty: 100,
+			height:            3,
+			expected:          100,
+		},
+		{
+			name:              "Blocks mined in half the desired duration double the difficulty",
+			chain:             chainAt(0, 50, 100),
+			genesisDifficulty: 100,
+			height:            3,
+			expected:          200,
+		},
+		{
+			name:              "Each recalculation builds on the one before",
+			chain:             chainAt(0, 50, 100, 200, 500),
+			genesisDifficulty: 100,
+			height:            5,
+			expected:          100,
+		},
+		{
+			name:              "Blocks timestamped out of order raise the difficulty as far as it goes",
+			chain:             chainAt(1000, 500, 0),
+			genesisDifficulty: 100,
+			height:            3,
+			expected:          400,
+		},
+		{
+			name:              "The difficulty falls no lower than the minimum",
+			chain:             chainAt(0, 1000, 2000),
+			genesisDifficulty: 2,
+			height:            3,
+			expected:          MinDifficulty,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			n := node{
+				targetDurPerBlock: 100 * time.Second,
+				recalcPeriod:      2,
+				genesisDifficulty: c.genesisDifficulty,
+			}
+
+			if got := n.requiredDifficulty(c.chain, c.height); got != c.expected {
+				t.Errorf("expected %v, got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestValidateTargets(t *testing.T) {
+	hasher := chain.NewHasher()
+
+	n := node{
+		hasher:            hasher,
+		params:            instantParams(),
+		clock:             nettime.New(),
+		targetDurPerBlock: time.Minute,
+		recalcPeriod:      2,
+	}
+
+	// Mined a second apart, the blocks from height 3 are required a
+	// difficulty of 4
+	honest := minedChain(&n, 4, time.Second)
+
+	// remined replaces the last block of the chain with one declaring the
+	// target, and meeting it
+	remined := func(target []byte) *chain.Chain {
+		parent := honest.Pbc.Blocks[:honest.Length()-1]
+		last := honest.LastLink()
+
+		block := chain.NewBlock(hasher, last.Prevhash, last.Txs, 0, target, "")
+		block.Timestamp = last.Timestamp
+		for new(big.Int).SetBytes(hasher.Hash(block)).Cmp(new(big.Int).SetBytes(target)) > 0 {
+			block.Nonce++
+		}
+
+		c := &chain.Chain{
+			Pbc:    &pb.Chain{Blocks: append([]*pb.Block{}, parent...)},
+			Hasher: hasher,
+		}
+
+		return c.WithBlock(block)
+	}
+
+	cases := []struct {
+		name  string
+		chain *chain.Chain
+		err   error
+	}{
+		{
+			name:  "Blocks declaring the targets required of them are valid",
+			chain: honest,
+		},
+		{
+			name:  "A block holding on to the target of before the recalculation is not valid",
+			chain: remined(mining.TargetFor(1)),
+			err:   ErrWrongTarget,
+		},
+		{
+			name:  "A block declaring an easier target than required is not valid",
+			chain: remined(mining.TargetFor(2)),
+			err:   ErrWrongTarget,
+		},
+		{
+			name:  "A block declaring a harder target than required is not valid",
+			chain: remined(mining.TargetFor(16)),
+			err:   ErrWrongTarget,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			headers := make([]*pb.BlockHeader, c.chain.Length())
+			for i, block := range c.chain.Pbc.Blocks {
+				headers[i] = (*chain.Block)(block).Header(uint64(i))
+			}
+
+			for _, err := range []error{n.Validate(c.chain), n.validateHeaders(headers)} {
+				if c.err == nil {
+					if err != nil {
+						t.Errorf("expected chain to be valid, got %s", err)
+					}
+					continue
+				}
+
+				var verr *ValidationError
+				if !errors.As(err, &verr) || verr.Height != 3 || !errors.Is(err, c.err) {
+					t.Errorf("expected block 3 to fail with %v, got %v", c.err, err)
+				}
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/asgaines/blockchain/chain"
 	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/golang/protobuf/ptypes"
 	"google.golang.org/grpc"
 )
 
@@ -18,7 +19,7 @@ func (n *node) periodicDiscoverPeers(ctx context.Context) {
 	for {
 		select {
 		case <-ticker.C:
-			if len(n.peers) < n.minPeers {
+			if len(n.getPeers()) < n.minPeers {
 				n.discoverPeers(ctx)
 			}
 		case <-ctx.Done():
@@ -30,7 +31,6 @@ func (n *node) periodicDiscoverPeers(ctx context.Context) {
 func (n *node) discoverPeers(ctx context.Context) {
 	// log.Printf("Discovering peers. Current peers: %v. Known addrs: %v", n.peers, n.knownAddrs)
 	var wg sync.WaitGroup
-	var mutex sync.Mutex
 
 	if n.knownAddrs.Len() < 1 {
 		n.appendAddrs(n.getSeedAddrs())
@@ -69,11 +69,28 @@ func (n *node) discoverPeers(ctx context.Context) {
 				return
 			}
 
+			if peerTime, err := ptypes.Timestamp(resp.GetTime()); err == nil {
+				n.clock.AddSample(nodeID.Pubkey, time.Until(peerTime))
+			}
+
+			n.peersMu.Lock()
+			_, known := n.peers[nodeID]
+			added := !known && resp.GetOk() && len(n.peers) < n.maxPeers
+			if added {
+				n.peers[nodeID] = NewPeer(
+					ctx,
+					door,
+					client,
+					conn,
+				)
+			}
+			n.peersMu.Unlock()
+
 			// Having to check this is indicative of an issue
 			// This door ideally would not have been in the list to begin with
 			// It's due to knownAddrs having two different addresses for the same node,
 			// one likely from a seed, the other from the peer reaching out
-			if _, ok := n.peers[nodeID]; ok {
+			if known {
 				// n.knownAddrs.RemoveOne(door)
 				// log.Println(n.knownAddrs.ReadAll())
 				if err := conn.Close(); err != nil {
@@ -82,15 +99,7 @@ func (n *node) discoverPeers(ctx context.Context) {
 				return
 			}
 
-			if resp.GetOk() && len(n.peers) < n.maxPeers {
-				mutex.Lock()
-				n.peers[nodeID] = NewPeer(
-					ctx,
-					door,
-					client,
-					conn,
-				)
-				mutex.Unlock()
+			if added {
 				log.Printf("Added new peer: %s (address: %s)", nodeID.ToProto().GetPubkey(), door)
 				// fmt.Println(n.peers)
 			} else {
@@ -106,38 +115,46 @@ func (n *node) discoverPeers(ctx context.Context) {
 	wg.Wait()
 }
 
-func (n *node) getInitState(ctx context.Context) (*chain.Chain, float64, error) {
+// getInitState fetches the chain of every peer, returning the valid one with
+// the most work, or the stored chain of the node should none have more
+func (n *node) getInitState(ctx context.Context) (*chain.Chain, error) {
+	if n.syncMode == SyncHeaders {
+		return n.syncHeadersFirst(ctx)
+	}
+
 	mainChain := chain.InitChain(n.hasher, n.filesPrefix)
-	difficulty := InitialExpectedHashrate * n.targetDurPerBlock.Seconds()
+	n.legacyHeight = mainChain.LegacyHeight
 
 	var wg sync.WaitGroup
 	var mutex sync.Mutex
 
-	wg.Add(len(n.peers))
-	for _, p := range n.peers {
+	peers := n.getPeers()
+
+	wg.Add(len(peers))
+	for _, p := range peers {
 		go func(p Peer) {
 			defer wg.Done()
 
-			c, diff, err := p.GetState(n.getID())
+			c, _, err := p.GetState(n.getID())
 			if err != nil {
 				log.Println(err)
 				return
 			}
 
-			if !n.IsValid(c) {
+			if err := n.Validate(c); err != nil {
+				log.Printf("rejected chain of peer: %s", err)
 				return
 			}
 
-			if c.Length() > mainChain.Length() {
-				mutex.Lock()
+			mutex.Lock()
+			if c.HasMoreWork(mainChain) {
 				mainChain = c
-				difficulty = diff
-				mutex.Unlock()
 			}
+			mutex.Unlock()
 		}(p)
 	}
 
 	wg.Wait()
 
-	return mainChain, difficulty, nil
+	return mainChain, nil
 }
@@ -0,0 +1,394 @@
+package nodes
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/asgaines/blockchain/accounts"
+	"github.com/asgaines/blockchain/address"
+	"github.com/asgaines/blockchain/canonical"
+	"github.com/asgaines/blockchain/chain"
+	"github.com/asgaines/blockchain/mempool"
+	"github.com/asgaines/blockchain/params"
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/asgaines/blockchain/transactions"
+	"github.com/asgaines/blockchain/utxo"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+)
+
+// ValidationError locates the first rule broken by a chain: the block, and
+// the tx within it when the fault is with one tx rather than the block
+type ValidationError struct {
+	Height    uint64
+	BlockHash []byte
+	// Tx is the index of the tx within the block, or -1 for the block itself
+	Tx     int
+	TxHash []byte
+	Err    error
+}
+
+func (e *ValidationError) Error() string {
+	if e.Tx < 0 {
+		return fmt.Sprintf("block %d (%x): %s", e.Height, e.BlockHash, e.Err)
+	}
+
+	return fmt.Sprintf("block %d (%x) tx %d (%x): %s", e.Height, e.BlockHash, e.Tx, e.TxHash, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// forkPoint returns the index of the first block at which the chains differ
+func forkPoint(a *chain.Chain, b *chain.Chain) int {
+	fork := 0
+	for fork < a.Length() && fork < b.Length() &&
+		proto.Equal(a.Pbc.Blocks[fork], b.Pbc.Blocks[fork]) {
+		fork++
+	}
+
+	return fork
+}
+
+// checkCoinbase ensures the block at the height pays exactly one block solve
+// reward, as its first tx. The reward records the height, so that no two
+// rewards share a hash. How much it claims is checked against the subsidy
+// and fees apart.
+func checkCoinbase(txs []*pb.Tx, height uint64) error {
+	if len(txs) == 0 || txs[0].GetSender() != "" {
+		return errors.New("first tx is not a block solve reward")
+	}
+
+	for i, tx := range txs[1:] {
+		if tx.GetSender() == "" {
+			return fmt.Errorf("tx %d is a second block solve reward", i+1)
+		}
+
+		if tx.GetHeight() != 0 {
+			return fmt.Errorf("tx %d records a height, which only block solve rewards do", i+1)
+		}
+	}
+
+	reward := txs[0]
+	if reward.GetHeight() != height {
+		return fmt.Errorf("block solve reward records height %d, expected %d", reward.GetHeight(), height)
+	}
+
+	// Nobody signs a reward, so there is nobody to sequence, charge or lock it for
+	if reward.GetNonce() != 0 || reward.GetFee() != 0 || reward.GetLock() != 0 || len(reward.GetInputs()) > 0 {
+		return errors.New("block solve reward sets a nonce, fee, lock or inputs")
+	}
+
+	return nil
+}
+
+// verifyTxs checks what each tx of a block can prove on its own: the signatures
+// of txs and the addresses paid by the block solve reward. No tx depends on
+// another for this, so they are checked concurrently. The index of the first
+// tx failing is returned with its error.
+func verifyTxs(txs []*pb.Tx, net *address.Network) (int, error) {
+	errs := make([]error, len(txs))
+	workers := make(chan struct{}, runtime.NumCPU())
+
+	var wg sync.WaitGroup
+
+	wg.Add(len(txs))
+	for i, tx := range txs {
+		workers <- struct{}{}
+		go func(i int, tx *pb.Tx) {
+			defer wg.Done()
+			defer func() { <-workers }()
+
+			errs[i] = verifyTx(tx, net)
+		}(i, tx)
+	}
+
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return i, err
+		}
+	}
+
+	return -1, nil
+}
+
+func verifyTx(tx *pb.Tx, net *address.Network) error {
+	if tx.GetSender() != "" {
+		return transactions.Verify(tx, net)
+	}
+
+	// The block solve reward comes from thin air, so has nobody to sign it
+	if tx.GetVersion() > canonical.TxVersion {
+		return fmt.Errorf("reward version %d: %w", tx.GetVersion(), canonical.ErrUnknownVersion)
+	}
+
+	if len(tx.GetOutputs()) == 0 {
+		if _, err := address.Validate(tx.GetRecipient(), net); err != nil {
+			return fmt.Errorf("reward recipient: %w", err)
+		}
+	}
+
+	for i, out := range tx.GetOutputs() {
+		if _, err := address.Validate(out.GetRecipient(), net); err != nil {
+			return fmt.Errorf("reward output %d recipient: %w", i, err)
+		}
+	}
+
+	return transactions.CheckLegacy(tx)
+}
+
+// checkLocks ensures every tx of the block at the height had matured by the
+// block's timestamp. A block without a valid timestamp can't include txs
+// locked until a time. The index of the first tx still locked is returned.
+func checkLocks(block *pb.Block, height uint64) (int, error) {
+	blockTime := time.Unix(0, 0)
+	if t, err := ptypes.Timestamp(block.GetTimestamp()); err == nil {
+		blockTime = t
+	}
+
+	for i, tx := range block.GetTxs() {
+		if !transactions.IsMature(tx, height, blockTime) {
+			return i, fmt.Errorf("tx is locked until %s", describeLock(tx.GetLock()))
+		}
+	}
+
+	return -1, nil
+}
+
+// checkLegacy ensures no tx of the block at the height is of legacy form, of
+// whole coin amounts or of version 0, unless the block is among those of the
+// stored chain, at or below legacyHeight. The index of the first legacy tx is
+// returned.
+func checkLegacy(block *pb.Block, height uint64, legacyHeight uint64) (int, error) {
+	if height <= legacyHeight {
+		return -1, nil
+	}
+
+	for i, tx := range block.GetTxs() {
+		if transactions.IsLegacy(tx) {
+			return i, transactions.ErrLegacyTx
+		}
+
+		if tx.GetVersion() < canonical.TxVersion {
+			return i, transactions.ErrUnversionedTx
+		}
+	}
+
+	return -1, nil
+}
+
+// rewardValue is the credit created by a block solve reward, whichever ledger
+// it is paid on
+func rewardValue(tx *pb.Tx) (uint64, bool) {
+	outputs, err := utxo.OutputsTotal(tx)
+	if err != nil {
+		return 0, false
+	}
+
+	return addAmounts(tx.GetValue(), outputs)
+}
+
+// circulatingSupply is the credit in existence in the chain. Subsidies create
+// credit and fees only move it, so it is what the block solve rewards claimed
+// less the fees they were paid from. A fee left unclaimed is thereby lost.
+func circulatingSupply(c *chain.Chain) uint64 {
+	var rewards, fees uint64
+
+	for _, block := range c.Pbc.Blocks {
+		for _, tx := range block.GetTxs() {
+			if tx.GetSender() != "" {
+				fees += tx.GetFee()
+				continue
+			}
+
+			if value, ok := rewardValue(tx); ok {
+				rewards += value
+			}
+		}
+	}
+
+	if fees > rewards {
+		return 0
+	}
+
+	return rewards - fees
+}
+
+// initLedger validates the initial chain and counts its balances on top of
+// those stored with it, returning the chain should it be valid, or else a new
+// one. The blocks counted by the stored balances were validated before being
+// stored, so only those beyond are validated, against the ledger restored from
+// the balances. The UTXO set is not stored, so a chain of the UTXO ledger is
+// validated whole.
+func (n *node) initLedger(c *chain.Chain, stored chain.Balances) *chain.Chain {
+	n.balances = stored
+
+	var err error
+	if trusted := n.balances.Height(); trusted > 1 && n.params.Ledger != params.LedgerUTXO {
+		err = n.validateFrom(c, trusted, restoreAccounts(c, n.balances, n.params.CoinbaseMaturity, n.balances.Addrs()))
+	} else {
+		err = n.Validate(c)
+	}
+
+	if err != nil {
+		log.Printf("rejected initial chain: %s", err)
+		c = chain.NewChain(n.hasher)
+		n.balances = chain.NewBalances()
+	}
+
+	n.syncBalances(nil, c)
+
+	return c
+}
+
+// restoreAccounts restores the accounts of the addresses as of the blocks of
+// the chain counted by the balances, without replaying them. Only the block
+// solve rewards of the blocks too recent to have matured are read from the
+// chain.
+func restoreAccounts(c *chain.Chain, b chain.Balances, coinbaseMaturity uint64, addrs []string) accounts.State {
+	credits := make(map[string]uint64)
+	nonces := make(map[string]uint64)
+
+	for _, addr := range addrs {
+		credits[addr] = b.Credit(addr)
+		nonces[addr] = b.Nonce(addr)
+	}
+
+	// A reward of the height is immature in the block after the last counted
+	// should fewer than coinbaseMaturity blocks lie between them
+	from := uint64(1)
+	if b.Height() > coinbaseMaturity {
+		from = b.Height() - coinbaseMaturity + 1
+	}
+
+	rewards := make([]*pb.Tx, 0)
+	for height := from; height < b.Height(); height++ {
+		for _, tx := range c.Pbc.Blocks[height].GetTxs() {
+			if _, ok := credits[tx.GetRecipient()]; ok && accounts.IsCoinbase(tx) {
+				rewards = append(rewards, tx)
+			}
+		}
+	}
+
+	return accounts.Restore(coinbaseMaturity, credits, nonces, rewards)
+}
+
+// syncBalances brings the balances in line with a newly adopted chain: the
+// blocks of prev orphaned by the switch are disconnected, then the new blocks
+// connected
+func (n *node) syncBalances(prev *chain.Chain, c *chain.Chain) {
+	if prev != nil {
+		fork := uint64(forkPoint(prev, c))
+
+		for height := n.balances.Height(); height > fork; height-- {
+			if err := n.balances.DisconnectBlock(prev.BlockByIdx(int(height - 1))); err != nil {
+				log.Printf("could not disconnect block %d from balances: %s", height-1, err)
+				return
+			}
+		}
+	}
+
+	for height := n.balances.Height(); height < uint64(c.Length()); height++ {
+		n.balances.ConnectBlock(c.BlockByIdx(int(height)), c.Hash(height))
+	}
+}
+
+// syncUTXOs brings the UTXO set in line with a newly adopted chain: the blocks
+// of prev orphaned by the switch are undone, then the new blocks applied
+func (n *node) syncUTXOs(prev *chain.Chain, c *chain.Chain) {
+	if n.params.Ledger != params.LedgerUTXO {
+		return
+	}
+
+	fork := 0
+	if prev != nil {
+		fork = forkPoint(prev, c)
+	}
+
+	for len(n.undos) > fork {
+		n.utxos.UndoBlock(n.undos[len(n.undos)-1])
+		n.undos = n.undos[:len(n.undos)-1]
+	}
+
+	for height := len(n.undos); height < c.Length(); height++ {
+		undo, err := n.utxos.ApplyBlock(c.Pbc.Blocks[height], uint64(height))
+		if err != nil {
+			log.Printf("could not apply block %d to UTXO set: %s", height, err)
+			return
+		}
+
+		n.undos = append(n.undos, undo)
+	}
+}
+
+// selectUTXOTxs picks txs from the txpool for the next block, highest fee rate
+// first, until maxSize bytes are filled. The txpool refuses txs spending the
+// same output, so any mature tx whose inputs are all unspent can be included.
+func (n *node) selectUTXOTxs(maxSize int) []*pb.Tx {
+	height := uint64(n.chain.Length())
+	candidates := n.txpool.Mature(height, time.Now())
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return mempool.FeeRate(candidates[i]) > mempool.FeeRate(candidates[j])
+	})
+
+	selected := make([]*pb.Tx, 0)
+	size := 0
+
+	for _, tx := range candidates {
+		if n.utxos.CheckTx(tx, height) != nil {
+			continue
+		}
+
+		if txSize := proto.Size(tx); size+txSize <= maxSize {
+			selected = append(selected, tx)
+			size += txSize
+		}
+	}
+
+	return selected
+}
+
+// staleUTXOTxs returns the hashes of pending txs spending outputs which are no
+// longer unspent, or mature, in the chain
+func (n *node) staleUTXOTxs() [][]byte {
+	stale := make([][]byte, 0)
+	height := uint64(n.chain.Length())
+
+	for _, tx := range n.txpool.All() {
+		if n.utxos.CheckTx(tx, height) != nil {
+			stale = append(stale, tx.GetHash())
+		}
+	}
+
+	return stale
+}
+
+// getUnspentFor returns the outputs owned by the address which are neither
+// spent in the chain nor by a tx pending in the txpool. Rewards not yet mature
+// enough to be spent in the next block are returned apart.
+func (n *node) getUnspentFor(addr string) ([]*pb.Unspent, []*pb.Unspent) {
+	unspent := make([]*pb.Unspent, 0)
+	immature := make([]*pb.Unspent, 0)
+	height := uint64(n.chain.Length())
+
+	for _, u := range n.utxos.ForAddress(addr) {
+		if u.GetCoinbase() && !n.params.IsMature(u.GetHeight(), height) {
+			immature = append(immature, u)
+			continue
+		}
+
+		if _, ok := n.txpool.Spender(u.GetOutPoint()); !ok {
+			unspent = append(unspent, u)
+		}
+	}
+
+	return unspent, immature
+}
@@ -0,0 +1,261 @@
+package nodes
+
+import (
+	"testing"
+
+	"github.com/asgaines/blockchain/address"
+	"github.com/asgaines/blockchain/chain"
+	"github.com/asgaines/blockchain/chain/mocks"
+	"github.com/asgaines/blockchain/nettime"
+	"github.com/asgaines/blockchain/params"
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/asgaines/blockchain/transactions"
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+func TestCirculatingSupply(t *testing.T) {
+	cases := []struct {
+		name     string
+		blocks   []*pb.Block
+		expected uint64
+	}{
+		{
+			name:     "A chain of only the genesis block holds no credit",
+			blocks:   []*pb.Block{{}},
+			expected: 0,
+		},
+		{
+			name: "Subsidies claimed on either ledger are summed",
+			blocks: []*pb.Block{
+				{},
+				{Txs: []*pb.Tx{{Recipient: "Gob", Value: 100}}},
+				{Txs: []*pb.Tx{{Outputs: []*pb.TxOut{{Recipient: "Gob", Value: 60}, {Recipient: "Tobias", Value: 40}}}}},
+			},
+			expected: 200,
+		},
+		{
+			name: "Fees claimed by the reward do not add to the supply",
+			blocks: []*pb.Block{
+				{},
+				{Txs: []*pb.Tx{{Recipient: "Gob", Value: 100}}},
+				{Txs: []*pb.Tx{
+					{Recipient: "Gob", Value: 103},
+					{Sender: "Gob", Recipient: "Tobias", Value: 10, Fee: 3},
+				}},
+			},
+			expected: 200,
+		},
+		{
+			name: "Fees left unclaimed by the reward are lost",
+			blocks: []*pb.Block{
+				{},
+				{Txs: []*pb.Tx{{Recipient: "Gob", Value: 100}}},
+				{Txs: []*pb.Tx{
+					{Recipient: "Gob", Value: 100},
+					{Sender: "Gob", Recipient: "Tobias", Value: 10, Fee: 3},
+				}},
+			},
+			expected: 197,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := circulatingSupply(&chain.Chain{
+				Pbc: &pb.Chain{
+					Blocks: c.blocks,
+				},
+			})
+
+			if got != c.expected {
+				t.Errorf("expected %d, got %d", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestCheckCoinbase(t *testing.T) {
+	reward := &pb.Tx{Recipient: "Gob", Value: 100, Height: 2}
+	spend := &pb.Tx{Sender: "Gob", Recipient: "Tobias", Value: 10}
+
+	cases := []struct {
+		name  string
+		txs   []*pb.Tx
+		valid bool
+	}{
+		{
+			name:  "A reward recording its height followed by txs is valid",
+			txs:   []*pb.Tx{reward, spend},
+			valid: true,
+		},
+		{
+			name:  "A block without a reward is not valid",
+			txs:   []*pb.Tx{spend},
+			valid: false,
+		},
+		{
+			name:  "A reward after another tx is not valid",
+			txs:   []*pb.Tx{spend, reward},
+			valid: false,
+		},
+		{
+			name:  "A second reward is not valid",
+			txs:   []*pb.Tx{reward, {Recipient: "Tobias", Value: 1, Height: 2}},
+			valid: false,
+		},
+		{
+			name:  "A reward recording another height is not valid",
+			txs:   []*pb.Tx{{Recipient: "Gob", Value: 100, Height: 1}},
+			valid: false,
+		},
+		{
+			name:  "A reward setting a fee is not valid",
+			txs:   []*pb.Tx{{Recipient: "Gob", Value: 100, Height: 2, Fee: 1}},
+			valid: false,
+		},
+		{
+			name:  "A tx other than the reward recording a height is not valid",
+			txs:   []*pb.Tx{reward, {Sender: "Gob", Recipient: "Tobias", Value: 10, Height: 2}},
+			valid: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := checkCoinbase(c.txs, 2)
+
+			if (err == nil) != c.valid {
+				t.Errorf("expected valid to be %v, got %v", c.valid, err)
+			}
+		})
+	}
+}
+
+func TestInitLedger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHasher := mocks.NewMockHasher(ctrl)
+	mockHasher.EXPECT().Hash(gomock.Any()).Return([]byte{1}).AnyTimes()
+
+	gob, err := transactions.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gobAddr := transactions.Address(gob, address.Mainnet)
+
+	ledgerParams := *params.Mainnet
+	ledgerParams.CoinbaseMaturity = 1
+
+	// The reward of the first block pays Gob twice the subsidy, more than
+	// validation allows, and the third block spends the value from it
+	chainSpending := func(value uint64) *chain.Chain {
+		spend := &pb.Tx{
+			Timestamp: &timestamp.Timestamp{
+				Seconds: 646459200,
+			},
+			Value:     value,
+			Recipient: testMiner,
+		}
+		transactions.Sign(spend, gob, address.Mainnet)
+
+		blocks := []*pb.Block{{}}
+		for height := uint64(1); height <= 3; height++ {
+			txs := []*pb.Tx{testReward(height)}
+
+			switch height {
+			case 1:
+				txs[0].Recipient = gobAddr
+				txs[0].Value = 2 * blockSubsidy
+			case 3:
+				txs = append(txs, spend)
+			}
+
+			blocks = append(blocks, &pb.Block{
+				Prevhash:  []byte{1},
+				Target:    testTarget,
+				Timestamp: testTimestamp(height),
+				Txs:       txs,
+			})
+		}
+
+		return currentForm(&chain.Chain{
+			Pbc:    &pb.Chain{Blocks: blocks},
+			Hasher: mockHasher,
+		})
+	}
+
+	// storedBalances counts the blocks of the chain below the height, as stored
+	// by a node having validated them
+	storedBalances := func(c *chain.Chain, height int) chain.Balances {
+		b := chain.NewBalances()
+		for h := 0; h < height; h++ {
+			b.ConnectBlock(c.BlockByIdx(h), c.Hash(uint64(h)))
+		}
+
+		return b
+	}
+
+	cases := []struct {
+		name   string
+		value  uint64
+		stored int
+		valid  bool
+	}{
+		{
+			name:   "Blocks counted by the stored balances are trusted rather than replayed",
+			value:  2 * blockSubsidy,
+			stored: 3,
+			valid:  true,
+		},
+		{
+			name:   "Blocks beyond the stored balances are validated against them",
+			value:  2*blockSubsidy + 1,
+			stored: 3,
+			valid:  false,
+		},
+		{
+			name:   "A chain without stored balances is validated whole",
+			value:  2 * blockSubsidy,
+			stored: 0,
+			valid:  false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			n := node{
+				hasher: mockHasher,
+				params: &ledgerParams,
+				clock:  nettime.New(),
+			}
+
+			c := chainSpending(tc.value)
+			got := n.initLedger(c, storedBalances(c, tc.stored))
+
+			if !tc.valid {
+				if got.Length() != 1 || n.balances.Height() != 1 {
+					t.Errorf("expected the chain to be replaced with a new one, got %d blocks", got.Length())
+				}
+				return
+			}
+
+			if got != c {
+				t.Fatalf("expected the chain to be kept, got %d blocks", got.Length())
+			}
+
+			if height := n.balances.Height(); height != 4 {
+				t.Errorf("expected balances of 4 blocks, got %d", height)
+			}
+
+			if credit := n.balances.Credit(testMiner); credit != 2*blockSubsidy {
+				t.Errorf("expected the miner to hold %d, got %d", 2*blockSubsidy, credit)
+			}
+
+			if credit := n.balances.Credit(gobAddr); credit != 0 {
+				t.Errorf("expected Gob to hold nothing, got %d", credit)
+			}
+		})
+	}
+}
@@ -3,17 +3,25 @@ package nodes
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math"
-	"math/big"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/asgaines/blockchain/accounts"
+	"github.com/asgaines/blockchain/canonical"
 	"github.com/asgaines/blockchain/chain"
+	"github.com/asgaines/blockchain/mempool"
+	"github.com/asgaines/blockchain/merkle"
 	"github.com/asgaines/blockchain/mining"
+	"github.com/asgaines/blockchain/params"
 	pb "github.com/asgaines/blockchain/protogo/blockchain"
 	"github.com/asgaines/blockchain/transactions"
+	"github.com/asgaines/blockchain/utxo"
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
 )
 
@@ -21,6 +29,14 @@ import (
 // adjusted by any one recalculation
 const DiffConfineFactor float64 = 4
 
+// MaxNonceGap is how far beyond a sender's next expected nonce a tx can be
+// accepted into the txpool, held back until the txs filling the gap arrive
+const MaxNonceGap uint64 = 64
+
+// MaxBlockTxsSize is the maximum combined size in bytes of the txs in a block
+// template, excluding the reward
+const MaxBlockTxsSize int = 1 << 20
+
 func (n *node) mine(ctx context.Context) {
 	conveyors := make([]<-chan mining.BlockReport, 0, len(n.miners))
 
@@ -32,12 +48,16 @@ func (n *node) mine(ctx context.Context) {
 	}
 
 	for mineReport := range n.mergeConveyors(conveyors...) {
+		n.mu.Lock()
 		chain := n.chain.WithBlock(mineReport.Block)
-		if overridden := n.setChain(chain, true); !overridden {
+		overridden := n.setChain(chain, true)
+		n.mu.Unlock()
+
+		if !overridden {
 			log.Fatal("solving a block did not successfully lead to own chain override")
 		}
 
-		n.propagateChain(nil)
+		n.propagateBlock(mineReport.Block.ToProto(), nil)
 	}
 }
 
@@ -75,66 +95,326 @@ func (n *node) logBlock(block *chain.Block) {
 	}
 
 	minedBy := block.GetMinerPubkey()
-	if minedBy == n.pubkey {
+	if minedBy == n.getRewardAddr(n.chain.Length()-1) {
 		minedBy = fmt.Sprintf("%s (you)", minedBy)
 	}
 
 	log.Printf("%064x (%vs) [%s]\n", n.hasher.Hash(block), lastLinkDur.Seconds(), minedBy)
 }
 
+// setChain switches the node to the chain, should it have more work than its
+// own. Either way, a valid chain is kept in the tree, so that a side branch
+// can be switched to once it is extended past the chain of the node. n.mu must
+// be held.
 func (n *node) setChain(chain *chain.Chain, trusted bool) bool {
-	if (trusted || n.IsValid(chain)) && chain.Length() > n.chain.Length() {
-		n.chain = chain
-		n.updatePrevBlock(chain.LastLink())
+	if n.tree.Contains(chain.Hash(uint64(chain.Length() - 1))) {
+		return false
+	}
 
-		n.logBlock(chain.LastLink())
+	if !trusted {
+		if err := n.Validate(chain); err != nil {
+			log.Printf("rejected chain: %s", err)
+			return false
+		}
+	}
 
-		if (chain.Length()-1)%n.recalcPeriod == 0 {
-			actualAvgBlockDur, err := n.getRangeAvgBlockDur(n.chain, n.recalcPeriod)
-			if err != nil {
-				log.Println(err)
-			}
+	n.tree.AddChain(chain)
+
+	if !chain.HasMoreWork(n.chain) {
+		return false
+	}
+
+	prev := n.chain
+	n.chain = chain
+	n.updatePrevBlock(chain.LastLink())
+
+	n.logBlock(chain.LastLink())
+
+	// A node without a recalc period never recalculates the difficulty
+	recalculated := n.recalcPeriod > 0 && (chain.Length()-1)%n.recalcPeriod == 0
+
+	if recalculated {
+		actualAvgBlockDur, err := n.getRangeAvgBlockDur(n.chain, n.recalcPeriod)
+		if err != nil {
+			log.Println(err)
+		}
+
+		if _, err := n.statsF.Write([]byte(fmt.Sprintf("%v\t%v\n", actualAvgBlockDur.Seconds(), n.difficulty))); err != nil {
+			log.Printf("could not write to file: %s", err)
+		}
+	}
+
+	// The difficulty is that required of the next block of the chain, which
+	// after a reorganisation may differ even between recalculations
+	difficulty := n.requiredDifficulty(chain, uint64(chain.Length()))
+	if recalculated || difficulty != n.difficulty {
+		n.difficulty = difficulty
+		n.updateTarget(n.difficulty)
+	}
+
+	n.syncBalances(prev, chain)
+	n.syncUTXOs(prev, chain)
+	n.syncTxIndex(prev, chain)
+	n.reconcileTxpool(prev, chain)
+	n.updateMinerTxs()
+
+	if event, ok := reorgEvent(prev, chain); ok {
+		n.emitReorg(event)
+	}
+
+	return true
+}
+
+func (n *node) addTx(tx *pb.Tx) error {
+	if err := n.txpool.Add(tx); err != nil {
+		return err
+	}
+	n.txindex.Seen(tx)
+
+	n.updateMinerTxs()
+
+	return nil
+}
+
+// reconcileTxpool brings the txpool in line with a newly adopted chain. Txs of
+// blocks orphaned by the switch from prev are returned to the pool, then every
+// tx whose nonce is now used, or whose inputs are now spent, in the chain is
+// dropped, as is every tx its sender's credit no longer covers.
+func (n *node) reconcileTxpool(prev *chain.Chain, c *chain.Chain) {
+	if prev != nil {
+		for _, block := range prev.Pbc.Blocks[forkPoint(prev, c):] {
+			for _, tx := range block.GetTxs() {
+				if tx.GetSender() == "" {
+					continue
+				}
+
+				// Should it not make it back into the chain, it counts as dropped
+				n.txindex.Seen(tx)
 
-			if _, err := n.statsF.Write([]byte(fmt.Sprintf("%v\t%v\n", actualAvgBlockDur.Seconds(), n.difficulty))); err != nil {
-				log.Printf("could not write to file: %s", err)
+				// Txs of legacy form are only valid in the blocks they came in
+				if transactions.IsLegacy(tx) || tx.GetVersion() < canonical.TxVersion {
+					continue
+				}
+
+				if err := n.txpool.Add(tx); err != nil && !errors.Is(err, mempool.ErrExists) {
+					log.Printf("could not return orphaned tx %x to txpool: %s", tx.GetHash(), err)
+				}
 			}
+		}
+	}
+
+	if n.params.Ledger == params.LedgerUTXO {
+		n.txpool.Remove(n.staleUTXOTxs()...)
+		return
+	}
 
-			n.difficulty = n.calcDifficulty(actualAvgBlockDur, n.difficulty)
+	// The balances are already synced to the chain
+	stale := make([][]byte, 0)
+	bySender := make(map[string][]*pb.Tx)
 
-			n.updateTarget(n.difficulty)
+	for _, tx := range n.txpool.All() {
+		if tx.GetNonce() < n.balances.Nonce(tx.GetSender()) {
+			stale = append(stale, tx.GetHash())
+			continue
 		}
 
-		n.resetTxpool()
-		return true
+		bySender[tx.GetSender()] = append(bySender[tx.GetSender()], tx)
 	}
 
-	return false
+	// As when shared, each sender's mature credit must cover their pending txs.
+	// Those it no longer covers are dropped, along with the later txs of the
+	// sender, which wait on their nonces.
+	for sender, txs := range bySender {
+		sort.Slice(txs, func(i, j int) bool {
+			return txs[i].GetNonce() < txs[j].GetNonce()
+		})
+
+		credit := n.balances.Credit(sender)
+		debits := n.getImmatureFor(sender)
+
+		for i, tx := range txs {
+			total, ok := addAmounts(tx.GetValue(), tx.GetFee())
+			if ok {
+				debits, ok = addAmounts(debits, total)
+			}
+
+			if !ok || debits > credit {
+				for _, dropped := range txs[i:] {
+					stale = append(stale, dropped.GetHash())
+				}
+				break
+			}
+		}
+	}
+
+	n.txpool.Remove(stale...)
 }
 
-func (n *node) addTx(tx *pb.Tx) {
-	n.txpool = append(n.txpool, tx)
+// periodicExpireTxs drops txs which have waited in the txpool beyond its TTL.
+// Txs locked until a time mature between blocks, so the miners are also given
+// any which have since matured.
+func (n *node) periodicExpireTxs(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	mature := 0
+
+	for {
+		select {
+		case <-ticker.C:
+			n.mu.Lock()
+			height, now := uint64(n.chain.Length()), time.Now()
+			refresh := false
+
+			if expired := n.txpool.Expire(height, now); len(expired) > 0 {
+				log.Printf("Expired %d txs from txpool", len(expired))
+				refresh = true
+			}
+
+			if m := len(n.txpool.Mature(height, now)); m != mature {
+				mature = m
+				refresh = true
+			}
+
+			if refresh {
+				n.updateMinerTxs()
+			}
+			n.mu.Unlock()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (n *node) updateMinerTxs() {
+	txs := n.blockTemplate()
 
 	for _, miner := range n.miners {
-		miner.SetTxs(n.txpool[:])
+		miner.SetTxs(txs)
 	}
 }
 
-func (n *node) resetTxpool() {
+// blockTemplate builds the txs for the next block: the reward first, paying the
+// subsidy scheduled for its height plus the fees of every tx selected from the
+// txpool after it
+func (n *node) blockTemplate() []*pb.Tx {
+	var selected []*pb.Tx
+	if n.params.Ledger == params.LedgerUTXO {
+		selected = n.selectUTXOTxs(MaxBlockTxsSize)
+	} else {
+		selected = n.selectTxs(MaxBlockTxsSize)
+	}
+
+	height := uint64(n.chain.Length())
+	reward := n.params.Subsidy(height)
+	for _, tx := range selected {
+		reward += tx.GetFee()
+	}
+
 	rewardTx := &pb.Tx{
+		Version:   canonical.TxVersion,
 		Timestamp: ptypes.TimestampNow(),
-		Value:     100,
 		Sender:    "", // From thin air...
-		Recipient: n.pubkey,
 		Message:   "Block solve reward",
+		Height:    height,
 		Hash:      nil,
 	}
 
+	if n.params.Ledger == params.LedgerUTXO {
+		rewardTx.Outputs = []*pb.TxOut{
+			{
+				Recipient: n.getRewardAddr(n.chain.Length()),
+				Value:     reward,
+			},
+		}
+	} else {
+		rewardTx.Recipient = n.getRewardAddr(n.chain.Length())
+		rewardTx.Value = reward
+	}
+
 	transactions.SetHash(rewardTx)
 
-	n.txpool = []*pb.Tx{rewardTx}
+	return append([]*pb.Tx{rewardTx}, selected...)
+}
 
-	for _, miner := range n.miners {
-		miner.SetTxs(n.txpool[:])
+// selectTxs picks txs from the txpool for the next block, highest fee rate
+// first, until maxSize bytes are filled. A tx is held back until every lower
+// nonce of its sender is in the chain or selected before it, so a sender's
+// cheap tx can hold back their pricier later ones. Likewise, a locked tx holds
+// back its sender's later txs until it matures. Each tx is applied to the
+// ledger as of the chain and the txs selected before it, so that a tx failing,
+// and its sender's later ones, are left out rather than invalidating the block.
+func (n *node) selectTxs(maxSize int) []*pb.Tx {
+	height := uint64(n.chain.Length())
+	bySender := make(map[string][]*pb.Tx)
+	arrival := make(map[string]int)
+	addrs := make([]string, 0)
+
+	for i, tx := range n.txpool.Mature(height, time.Now()) {
+		sender := tx.GetSender()
+		if _, ok := bySender[sender]; !ok {
+			arrival[sender] = i
+		}
+		bySender[sender] = append(bySender[sender], tx)
+		addrs = append(addrs, sender, tx.GetRecipient())
+	}
+
+	accts := restoreAccounts(n.chain, n.balances, n.params.CoinbaseMaturity, addrs)
+
+	// Each sender's queue holds only the txs contiguous from their next nonce
+	for sender, txs := range bySender {
+		sort.Slice(txs, func(i, j int) bool {
+			return txs[i].GetNonce() < txs[j].GetNonce()
+		})
+
+		next := n.balances.Nonce(sender)
+		ready := 0
+		for _, tx := range txs {
+			if tx.GetNonce() != next {
+				break
+			}
+
+			ready++
+			next++
+		}
+
+		bySender[sender] = txs[:ready]
+	}
+
+	selected := make([]*pb.Tx, 0)
+	size := 0
+
+	for {
+		best := ""
+		for sender, txs := range bySender {
+			if len(txs) == 0 {
+				continue
+			}
+
+			if best == "" {
+				best = sender
+				continue
+			}
+
+			rate, bestRate := mempool.FeeRate(txs[0]), mempool.FeeRate(bySender[best][0])
+			if rate > bestRate || (rate == bestRate && arrival[sender] < arrival[best]) {
+				best = sender
+			}
+		}
+
+		if best == "" {
+			return selected
+		}
+
+		tx := bySender[best][0]
+		if txSize := proto.Size(tx); size+txSize <= maxSize && accts.ApplyTx(tx, height) == nil {
+			selected = append(selected, tx)
+			size += txSize
+			bySender[best] = bySender[best][1:]
+		} else {
+			// Later txs of the sender cannot be included without this one
+			delete(bySender, best)
+		}
 	}
 }
 
@@ -152,26 +432,143 @@ func (n *node) updatePrevBlock(block *chain.Block) {
 	}
 }
 
-func (n *node) IsValid(c *chain.Chain) bool {
+// Validate replays every block of the chain from a genesis carrying no txs,
+// checking each links
+// to the one before, declares and meets the target required of it and is
+// timestamped within bounds, and each tx against the ledger as of the txs
+// before it. The first rule broken is returned as a *ValidationError.
+func (n *node) Validate(c *chain.Chain) error {
+	return n.validateFrom(c, 1, accounts.New(n.params.CoinbaseMaturity))
+}
+
+// validateFrom validates the blocks of the chain from the height on, as
+// Validate does, trusting those below it. The txs are checked against accts,
+// the accounts ledger as of the trusted blocks. The UTXO set is rebuilt from
+// genesis, so a chain of the UTXO ledger must be validated from height 1.
+func (n *node) validateFrom(c *chain.Chain, from uint64, accts accounts.State) error {
 	if len(c.Pbc.Blocks) <= 0 {
-		return false
+		return errors.New("chain has no genesis block")
+	}
+
+	genesis := (*chain.Block)(c.Pbc.Blocks[0])
+	if err := checkGenesis(genesis); err != nil {
+		return &ValidationError{Height: 0, BlockHash: n.hasher.Hash(genesis), Tx: -1, Err: err}
 	}
 
-	for i, block := range c.Pbc.Blocks[1:] {
-		prev := c.Pbc.Blocks[i]
+	utxos := utxo.New(n.params.CoinbaseMaturity)
+	now := n.clock.Now()
+	difficulty := n.requiredDifficulty(c, from-1)
+
+	for height := from; height < uint64(c.Length()); height++ {
+		block := c.Pbc.Blocks[height]
+		prev := c.Pbc.Blocks[height-1]
 		prevhash := n.hasher.Hash((*chain.Block)(prev))
 		blockHash := n.hasher.Hash((*chain.Block)(block))
 
-		blockHashBI := new(big.Int).SetBytes(blockHash)
-		targetBI := new(big.Int).SetBytes(block.Target)
+		invalid := func(tx int, err error) error {
+			verr := &ValidationError{
+				Height:    height,
+				BlockHash: blockHash,
+				Tx:        tx,
+				Err:       err,
+			}
+			if tx >= 0 {
+				verr.TxHash = block.GetTxs()[tx].GetHash()
+			}
 
-		if !bytes.Equal(prevhash, block.Prevhash) ||
-			blockHashBI.Cmp(targetBI) == 1 {
-			return false
+			return verr
+		}
+
+		if err := checkLink((*chain.Block)(block), blockHash, prevhash); err != nil {
+			return invalid(-1, err)
+		}
+
+		if err := checkVersion((*chain.Block)(block), height, n.legacyHeight); err != nil {
+			return invalid(-1, err)
+		}
+
+		if err := checkTimestamp(c, height, now, n.params.MaxFutureDrift); err != nil {
+			return invalid(-1, err)
+		}
+
+		difficulty = n.nextDifficulty(c, height-1, difficulty)
+		if err := checkTarget((*chain.Block)(block), difficulty); err != nil {
+			return invalid(-1, err)
+		}
+
+		// Blocks from before merkle trees committed to their txs by a simpler
+		// root, which proves nothing of them and is left unchecked
+		if block.GetVersion() >= canonical.HeaderVersion && !bytes.Equal(block.GetMerkleRoot(), merkle.Root(block.GetTxs())) {
+			return invalid(-1, errors.New("merkle root does not match the txs"))
+		}
+
+		if err := checkCoinbase(block.GetTxs(), height); err != nil {
+			return invalid(-1, err)
+		}
+
+		if idx, err := verifyTxs(block.GetTxs(), n.params.Network); err != nil {
+			return invalid(idx, err)
+		}
+
+		if idx, err := checkLocks(block, height); err != nil {
+			return invalid(idx, err)
+		}
+
+		if idx, err := checkLegacy(block, height, n.legacyHeight); err != nil {
+			return invalid(idx, err)
+		}
+
+		for idx, tx := range block.GetTxs() {
+			var err error
+
+			switch n.params.Ledger {
+			case params.LedgerUTXO:
+				// Each tx is applied on its own, so the one failing can be named
+				_, err = utxos.ApplyBlock(&pb.Block{Txs: []*pb.Tx{tx}}, height)
+			default:
+				err = accts.ApplyTx(tx, height)
+			}
+
+			if err != nil {
+				return invalid(idx, err)
+			}
+		}
+
+		// The reward can claim no more than the scheduled subsidy plus the fees
+		// of the block
+		reward, allowed := uint64(0), n.params.Subsidy(height)
+		var ok bool
+
+		for idx, tx := range block.GetTxs() {
+			if tx.GetSender() != "" {
+				if allowed, ok = addAmounts(allowed, tx.GetFee()); !ok {
+					return invalid(idx, accounts.ErrOverflow)
+				}
+				continue
+			}
+
+			value, ok := rewardValue(tx)
+			if !ok {
+				return invalid(idx, accounts.ErrOverflow)
+			}
+
+			if reward, ok = addAmounts(reward, value); !ok {
+				return invalid(idx, accounts.ErrOverflow)
+			}
+		}
+
+		if reward > allowed {
+			return invalid(0, fmt.Errorf("block solve reward claims %d, more than the subsidy plus fees of %d", reward, allowed))
 		}
 	}
 
-	return true
+	return nil
+}
+
+// addAmounts sums two amounts in base units, reporting whether the sum fits
+func addAmounts(a, b uint64) (uint64, bool) {
+	sum := a + b
+	return sum, sum >= a
 }
 
 func (n *node) getRecalcRangeDur(c *chain.Chain, recalcPeriod int) (time.Duration, error) {
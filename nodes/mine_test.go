@@ -2,20 +2,100 @@ package nodes
 
 import (
 	"context"
+	"crypto/ed25519"
+	"errors"
 	"reflect"
 	"testing"
 	"time"
 
+	"github.com/asgaines/blockchain/accounts"
+	"github.com/asgaines/blockchain/address"
+	"github.com/asgaines/blockchain/canonical"
 	"github.com/asgaines/blockchain/chain"
 	"github.com/asgaines/blockchain/chain/mocks"
+	"github.com/asgaines/blockchain/mempool"
+	"github.com/asgaines/blockchain/merkle"
 	"github.com/asgaines/blockchain/mining"
 	mm "github.com/asgaines/blockchain/mining/mocks"
+	"github.com/asgaines/blockchain/nettime"
+	"github.com/asgaines/blockchain/params"
 	"github.com/asgaines/blockchain/protogo/blockchain"
 	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/asgaines/blockchain/transactions"
+	"github.com/asgaines/blockchain/txindex"
+	"github.com/asgaines/blockchain/utxo"
+	"github.com/asgaines/blockchain/wallet"
 	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/timestamp"
 )
 
+// testMiner is the address paid the block solve rewards of test blocks
+var testMiner = transactions.Address(ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)), address.Mainnet)
+
+// testReward returns a block solve reward paying nothing, as the first tx of
+// the block at the height
+func testReward(height uint64) *pb.Tx {
+	return &pb.Tx{
+		Recipient: testMiner,
+		Height:    height,
+	}
+}
+
+// testTimestamp returns the timestamp of the test block at the height, each a
+// minute after the one before
+func testTimestamp(height uint64) *timestamp.Timestamp {
+	return &timestamp.Timestamp{
+		Seconds: 646459200 + int64(height)*60,
+	}
+}
+
+// instantParams returns the mainnet params, but with block solve rewards
+// spendable within the block paying them, so test chains need not wait for
+// them to mature
+func instantParams() *params.Params {
+	p := *params.Mainnet
+	p.CoinbaseMaturity = 0
+
+	return &p
+}
+
+// testTarget is the target required of test blocks. Test nodes start at the
+// lowest difficulty, having no duration per block to aim for
+var testTarget = mining.MaxTarget.Bytes()
+
+// currentForm puts the blocks of the chain in the form of those mined now: of
+// the current header version, committing to their txs by their merkle root,
+// with txs of the current version. Test blocks are written without any, for
+// brevity.
+func currentForm(c *chain.Chain) *chain.Chain {
+	for _, block := range c.Pbc.Blocks {
+		currentBlock((*chain.Block)(block))
+	}
+
+	return c
+}
+
+// currentBlock puts the block in the form of those mined now, as currentForm
+func currentBlock(b *chain.Block) *chain.Block {
+	for _, tx := range b.Txs {
+		if tx.Version == 0 && !transactions.IsLegacy(tx) {
+			tx.Version = canonical.TxVersion
+		}
+	}
+
+	if b.Version == 0 {
+		b.Version = canonical.HeaderVersion
+		b.MerkleRoot = merkle.Root(b.Txs)
+	}
+
+	return b
+}
+
+// blockSubsidy is the subsidy of the first blocks of every network, before any
+// halving
+var blockSubsidy = params.Mainnet.InitialSubsidy
+
 func TestMine(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -628,8 +708,15 @@ func TestMine(t *testing.T) {
 				targetDurPerBlock: c.nodeSetup.targetDurPerBlock,
 				recalcPeriod:      c.nodeSetup.recalcPeriod,
 				difficulty:        c.nodeSetup.difficulty,
+				genesisDifficulty: c.nodeSetup.difficulty,
 				miners:            []mining.Miner{mockMiner},
 				hasher:            mockHasher,
+				params:            params.Mainnet,
+				clock:             nettime.New(),
+				txpool:            mempool.New(mempool.DefaultConfig),
+				txindex:           txindex.New(txindex.DefaultMaxSeen),
+				balances:          balancesOf(c.nodeSetup.chain),
+				tree:              chain.NewTree(c.nodeSetup.chain),
 			}
 
 			n.mine(ctx)
@@ -641,11 +728,16 @@ func TestMine(t *testing.T) {
 	}
 }
 
-func TestIsValid(t *testing.T) {
+func TestValidate(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	mockHasher := mocks.NewMockHasher(ctrl)
 
+	reward := testReward(1)
+
+	// The node requires a difficulty of 2, so that a hash can miss the target
+	target := mining.TargetFor(2)
+
 	type mockHashCall struct {
 		in  *chain.Block
 		out []byte
@@ -684,8 +776,9 @@ func TestIsValid(t *testing.T) {
 							},
 							Prevhash:   []byte{1, 2, 4},
 							Nonce:      456,
-							Target:     []byte{5, 5, 5},
+							Target:     target,
 							MerkleRoot: []byte{},
+							Txs:        []*pb.Tx{reward},
 						},
 					},
 				},
@@ -707,8 +800,9 @@ func TestIsValid(t *testing.T) {
 						},
 						Prevhash:   []byte{1, 2, 4},
 						Nonce:      456,
-						Target:     []byte{5, 5, 5},
+						Target:     target,
 						MerkleRoot: []byte{},
+						Txs:        []*pb.Tx{reward},
 					},
 					out: []byte{4, 5, 6},
 				},
@@ -732,8 +826,9 @@ func TestIsValid(t *testing.T) {
 							},
 							Prevhash:   []byte{1, 2, 3},
 							Nonce:      456,
-							Target:     []byte{4, 5, 7},
+							Target:     target,
 							MerkleRoot: []byte{},
+							Txs:        []*pb.Tx{reward},
 						},
 					},
 				},
@@ -755,8 +850,9 @@ func TestIsValid(t *testing.T) {
 						},
 						Prevhash:   []byte{1, 2, 3},
 						Nonce:      456,
-						Target:     []byte{4, 5, 7},
+						Target:     target,
 						MerkleRoot: []byte{},
+						Txs:        []*pb.Tx{reward},
 					},
 					out: []byte{4, 5, 6},
 				},
@@ -780,8 +876,9 @@ func TestIsValid(t *testing.T) {
 							},
 							Prevhash:   []byte{1, 2, 3},
 							Nonce:      456,
-							Target:     []byte{4, 5, 6},
+							Target:     target,
 							MerkleRoot: []byte{},
+							Txs:        []*pb.Tx{reward},
 						},
 					},
 				},
@@ -803,10 +900,11 @@ func TestIsValid(t *testing.T) {
 						},
 						Prevhash:   []byte{1, 2, 3},
 						Nonce:      456,
-						Target:     []byte{4, 5, 6},
+						Target:     target,
 						MerkleRoot: []byte{},
+						Txs:        []*pb.Tx{reward},
 					},
-					out: []byte{4, 5, 6},
+					out: target,
 				},
 			},
 			want: true,
@@ -828,8 +926,9 @@ func TestIsValid(t *testing.T) {
 							},
 							Prevhash:   []byte{1, 2, 3},
 							Nonce:      456,
-							Target:     []byte{9, 9},
+							Target:     target,
 							MerkleRoot: []byte{},
+							Txs:        []*pb.Tx{reward},
 						},
 					},
 				},
@@ -851,8 +950,57 @@ func TestIsValid(t *testing.T) {
 						},
 						Prevhash:   []byte{1, 2, 3},
 						Nonce:      456,
-						Target:     []byte{9, 9},
+						Target:     target,
 						MerkleRoot: []byte{},
+						Txs:        []*pb.Tx{reward},
+					},
+					out: testTarget,
+				},
+			},
+			want: false,
+		},
+		{
+			name: "Chain with valid prev hash and hash below target but an unsigned tx is not valid",
+			chain: &chain.Chain{
+				Pbc: &blockchain.Chain{
+					Blocks: []*pb.Block{
+						&pb.Block{
+							Nonce: 123,
+						},
+						&pb.Block{
+							Prevhash: []byte{1, 2, 3},
+							Nonce:    456,
+							Target:   target,
+							Txs: []*pb.Tx{
+								{
+									Value:     100,
+									Sender:    "f1e2d3c4b5a6978812345678901234567890abcdefabcdefabcdefabcdef0123",
+									Recipient: "BusterBluth",
+								},
+							},
+						},
+					},
+				},
+			},
+			mockHashCalls: []mockHashCall{
+				{
+					in: &chain.Block{
+						Nonce: 123,
+					},
+					out: []byte{1, 2, 3},
+				},
+				{
+					in: &chain.Block{
+						Prevhash: []byte{1, 2, 3},
+						Nonce:    456,
+						Target:   target,
+						Txs: []*pb.Tx{
+							{
+								Value:     100,
+								Sender:    "f1e2d3c4b5a6978812345678901234567890abcdefabcdefabcdefabcdef0123",
+								Recipient: "BusterBluth",
+							},
+						},
 					},
 					out: []byte{4, 5, 6},
 				},
@@ -864,17 +1012,20 @@ func TestIsValid(t *testing.T) {
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			for _, call := range c.mockHashCalls {
-				mockHasher.EXPECT().Hash(call.in).Return(call.out)
+				mockHasher.EXPECT().Hash(currentBlock(call.in)).Return(call.out)
 			}
 
 			n := node{
-				hasher: mockHasher,
+				params:            params.Mainnet,
+				clock:             nettime.New(),
+				hasher:            mockHasher,
+				genesisDifficulty: 2,
 			}
 
-			got := n.IsValid(c.chain)
+			err := n.Validate(currentForm(c.chain))
 
-			if got != c.want {
-				t.Errorf("want %v, got %v", c.want, got)
+			if got := err == nil; got != c.want {
+				t.Errorf("want %v, got %v", c.want, err)
 			}
 		})
 	}
@@ -922,7 +1073,7 @@ func TestSetChain(t *testing.T) {
 		expectedReplace bool
 	}{
 		{
-			name: "Valid chain of equal length does not replace node chain",
+			name: "Valid chain of equal work with a higher tip hash does not replace node chain",
 			nodeSetup: nodeSetup{
 				chain: &chain.Chain{
 					Pbc: &pb.Chain{
@@ -956,7 +1107,7 @@ func TestSetChain(t *testing.T) {
 			expectedReplace: false,
 		},
 		{
-			name: "Trusted chain (also valid) of equal length does not replace node chain",
+			name: "Trusted chain (also valid) of equal work with a higher tip hash does not replace node chain",
 			nodeSetup: nodeSetup{
 				chain: &chain.Chain{
 					Pbc: &pb.Chain{
@@ -989,6 +1140,52 @@ func TestSetChain(t *testing.T) {
 			},
 			expectedReplace: false,
 		},
+		{
+			name: "Trusted chain longer by easier blocks, with less work, does not replace node chain",
+			nodeSetup: nodeSetup{
+				chain: &chain.Chain{
+					Pbc: &pb.Chain{
+						Blocks: []*pb.Block{
+							&pb.Block{
+								Nonce: 123,
+							},
+							&pb.Block{
+								Nonce:  234,
+								Target: []byte{0x00, 0xff},
+							},
+						},
+					},
+				},
+				recalcPeriod: 1,
+			},
+			input: input{
+				chain: &chain.Chain{
+					Pbc: &pb.Chain{
+						Blocks: []*pb.Block{
+							&pb.Block{
+								Nonce: 123,
+							},
+							&pb.Block{
+								Nonce:  345,
+								Target: []byte{0xff, 0xff},
+							},
+							&pb.Block{
+								Nonce:  456,
+								Target: []byte{0xff, 0xff},
+							},
+						},
+					},
+				},
+				trusted: true,
+			},
+			mockHashCalls: []mockHashCall{},
+			mockMinerCalls: mockMinerCalls{
+				numUpdatePrevHash: 0,
+				numClearTxs:       0,
+				numSetTarget:      0,
+			},
+			expectedReplace: false,
+		},
 		{
 			name: "Valid chain of equal length does not replace old chain",
 			nodeSetup: nodeSetup{
@@ -1011,8 +1208,10 @@ func TestSetChain(t *testing.T) {
 								Nonce: 123,
 							},
 							&pb.Block{
-								Nonce:    234,
-								Prevhash: []byte{1, 2, 3},
+								Nonce:     234,
+								Timestamp: testTimestamp(1),
+								Txs:       []*pb.Tx{testReward(1)},
+								Prevhash:  []byte{1, 2, 3},
 							},
 						},
 					},
@@ -1028,8 +1227,10 @@ func TestSetChain(t *testing.T) {
 				},
 				{
 					in: &chain.Block{
-						Nonce:    234,
-						Prevhash: []byte{1, 2, 3},
+						Nonce:     234,
+						Timestamp: testTimestamp(1),
+						Txs:       []*pb.Tx{testReward(1)},
+						Prevhash:  []byte{1, 2, 3},
 					},
 					out: []byte{2, 3, 4},
 				},
@@ -1063,9 +1264,11 @@ func TestSetChain(t *testing.T) {
 								Nonce: 123,
 							},
 							&pb.Block{
-								Nonce:    234,
-								Prevhash: []byte{1, 2, 3},
-								Target:   []byte{2, 3, 4},
+								Nonce:     234,
+								Timestamp: testTimestamp(1),
+								Txs:       []*pb.Tx{testReward(1)},
+								Prevhash:  []byte{1, 2, 3},
+								Target:    testTarget,
 							},
 						},
 					},
@@ -1081,26 +1284,32 @@ func TestSetChain(t *testing.T) {
 				},
 				{
 					in: &chain.Block{
-						Nonce:    234,
-						Prevhash: []byte{1, 2, 3},
-						Target:   []byte{2, 3, 4},
+						Nonce:     234,
+						Timestamp: testTimestamp(1),
+						Txs:       []*pb.Tx{testReward(1)},
+						Prevhash:  []byte{1, 2, 3},
+						Target:    testTarget,
 					},
 					out: []byte{2, 3, 4},
 				},
 				// Next two for non-validation hashes
 				{
 					in: &chain.Block{
-						Nonce:    234,
-						Prevhash: []byte{1, 2, 3},
-						Target:   []byte{2, 3, 4},
+						Nonce:     234,
+						Timestamp: testTimestamp(1),
+						Txs:       []*pb.Tx{testReward(1)},
+						Prevhash:  []byte{1, 2, 3},
+						Target:    testTarget,
 					},
 					out: []byte{2, 3, 4},
 				},
 				{
 					in: &chain.Block{
-						Nonce:    234,
-						Prevhash: []byte{1, 2, 3},
-						Target:   []byte{2, 3, 4},
+						Nonce:     234,
+						Timestamp: testTimestamp(1),
+						Txs:       []*pb.Tx{testReward(1)},
+						Prevhash:  []byte{1, 2, 3},
+						Target:    testTarget,
 					},
 					out: []byte{2, 3, 4},
 				},
@@ -1134,19 +1343,25 @@ func TestSetChain(t *testing.T) {
 								Nonce: 123,
 							},
 							&pb.Block{
-								Nonce:    234,
-								Prevhash: []byte{1, 2, 3},
-								Target:   []byte{2, 3, 4},
+								Nonce:     234,
+								Timestamp: testTimestamp(1),
+								Txs:       []*pb.Tx{testReward(1)},
+								Prevhash:  []byte{1, 2, 3},
+								Target:    testTarget,
 							},
 							&pb.Block{
-								Nonce:    345,
-								Prevhash: []byte{2, 3, 4},
-								Target:   []byte{3, 4, 5},
+								Nonce:     345,
+								Timestamp: testTimestamp(2),
+								Txs:       []*pb.Tx{testReward(2)},
+								Prevhash:  []byte{2, 3, 4},
+								Target:    testTarget,
 							},
 							&pb.Block{
-								Nonce:    456,
-								Prevhash: []byte{3, 4, 5},
-								Target:   []byte{4, 5, 6},
+								Nonce:     456,
+								Timestamp: testTimestamp(3),
+								Txs:       []*pb.Tx{testReward(3)},
+								Prevhash:  []byte{3, 4, 5},
+								Target:    testTarget,
 							},
 						},
 					},
@@ -1162,58 +1377,72 @@ func TestSetChain(t *testing.T) {
 				},
 				{
 					in: &chain.Block{
-						Nonce:    234,
-						Prevhash: []byte{1, 2, 3},
-						Target:   []byte{2, 3, 4},
+						Nonce:     234,
+						Timestamp: testTimestamp(1),
+						Txs:       []*pb.Tx{testReward(1)},
+						Prevhash:  []byte{1, 2, 3},
+						Target:    testTarget,
 					},
 					out: []byte{2, 3, 4},
 				},
 				{
 					in: &chain.Block{
-						Nonce:    234,
-						Prevhash: []byte{1, 2, 3},
-						Target:   []byte{2, 3, 4},
+						Nonce:     234,
+						Timestamp: testTimestamp(1),
+						Txs:       []*pb.Tx{testReward(1)},
+						Prevhash:  []byte{1, 2, 3},
+						Target:    testTarget,
 					},
 					out: []byte{2, 3, 4},
 				},
 				{
 					in: &chain.Block{
-						Nonce:    345,
-						Prevhash: []byte{2, 3, 4},
-						Target:   []byte{3, 4, 5},
+						Nonce:     345,
+						Timestamp: testTimestamp(2),
+						Txs:       []*pb.Tx{testReward(2)},
+						Prevhash:  []byte{2, 3, 4},
+						Target:    testTarget,
 					},
 					out: []byte{3, 4, 5},
 				},
 				{
 					in: &chain.Block{
-						Nonce:    345,
-						Prevhash: []byte{2, 3, 4},
-						Target:   []byte{3, 4, 5},
+						Nonce:     345,
+						Timestamp: testTimestamp(2),
+						Txs:       []*pb.Tx{testReward(2)},
+						Prevhash:  []byte{2, 3, 4},
+						Target:    testTarget,
 					},
 					out: []byte{3, 4, 5},
 				},
 				{
 					in: &chain.Block{
-						Nonce:    456,
-						Prevhash: []byte{3, 4, 5},
-						Target:   []byte{4, 5, 6},
+						Nonce:     456,
+						Timestamp: testTimestamp(3),
+						Txs:       []*pb.Tx{testReward(3)},
+						Prevhash:  []byte{3, 4, 5},
+						Target:    testTarget,
 					},
 					out: []byte{4, 5, 6},
 				},
 				// Next two for non-validation hashes
 				{
 					in: &chain.Block{
-						Nonce:    456,
-						Prevhash: []byte{3, 4, 5},
-						Target:   []byte{4, 5, 6},
+						Nonce:     456,
+						Timestamp: testTimestamp(3),
+						Txs:       []*pb.Tx{testReward(3)},
+						Prevhash:  []byte{3, 4, 5},
+						Target:    testTarget,
 					},
 					out: []byte{4, 5, 6},
 				},
 				{
 					in: &chain.Block{
-						Nonce:    456,
-						Prevhash: []byte{3, 4, 5},
-						Target:   []byte{4, 5, 6},
+						Nonce:     456,
+						Timestamp: testTimestamp(3),
+						Txs:       []*pb.Tx{testReward(3)},
+						Prevhash:  []byte{3, 4, 5},
+						Target:    testTarget,
 					},
 					out: []byte{4, 5, 6},
 				},
@@ -1247,9 +1476,11 @@ func TestSetChain(t *testing.T) {
 								Nonce: 123,
 							},
 							&pb.Block{
-								Nonce:    234,
-								Prevhash: []byte{1, 2, 3},
-								Target:   []byte{2, 3, 4},
+								Nonce:     234,
+								Timestamp: testTimestamp(1),
+								Txs:       []*pb.Tx{testReward(1)},
+								Prevhash:  []byte{1, 2, 3},
+								Target:    testTarget,
 							},
 						},
 					},
@@ -1265,26 +1496,111 @@ func TestSetChain(t *testing.T) {
 				},
 				{
 					in: &chain.Block{
-						Nonce:    234,
-						Prevhash: []byte{1, 2, 3},
-						Target:   []byte{2, 3, 4},
+						Nonce:     234,
+						Timestamp: testTimestamp(1),
+						Txs:       []*pb.Tx{testReward(1)},
+						Prevhash:  []byte{1, 2, 3},
+						Target:    testTarget,
 					},
 					out: []byte{2, 3, 4},
 				},
 				// Next two for non-validation hashes
 				{
 					in: &chain.Block{
-						Nonce:    234,
-						Prevhash: []byte{1, 2, 3},
-						Target:   []byte{2, 3, 4},
+						Nonce:     234,
+						Timestamp: testTimestamp(1),
+						Txs:       []*pb.Tx{testReward(1)},
+						Prevhash:  []byte{1, 2, 3},
+						Target:    testTarget,
 					},
 					out: []byte{2, 3, 4},
 				},
 				{
 					in: &chain.Block{
-						Nonce:    234,
-						Prevhash: []byte{1, 2, 3},
-						Target:   []byte{2, 3, 4},
+						Nonce:     234,
+						Timestamp: testTimestamp(1),
+						Txs:       []*pb.Tx{testReward(1)},
+						Prevhash:  []byte{1, 2, 3},
+						Target:    testTarget,
+					},
+					out: []byte{2, 3, 4},
+				},
+			},
+			mockMinerCalls: mockMinerCalls{
+				numUpdatePrevHash: 1,
+				numClearTxs:       1,
+				numSetTarget:      0,
+			},
+			expectedReplace: true,
+		},
+		{
+			name: "Valid chain replaces old chain of a node without a recalc period, holding the difficulty",
+			nodeSetup: nodeSetup{
+				chain: &chain.Chain{
+					Pbc: &pb.Chain{
+						Blocks: []*pb.Block{
+							&pb.Block{
+								Nonce: 123,
+							},
+						},
+					},
+				},
+				recalcPeriod: 0,
+			},
+			input: input{
+				chain: &chain.Chain{
+					Pbc: &pb.Chain{
+						Blocks: []*pb.Block{
+							&pb.Block{
+								Nonce: 123,
+							},
+							&pb.Block{
+								Nonce:     234,
+								Timestamp: testTimestamp(1),
+								Txs:       []*pb.Tx{testReward(1)},
+								Prevhash:  []byte{1, 2, 3},
+								Target:    testTarget,
+							},
+						},
+					},
+				},
+				trusted: false,
+			},
+			mockHashCalls: []mockHashCall{
+				{
+					in: &chain.Block{
+						Nonce: 123,
+					},
+					out: []byte{1, 2, 3},
+				},
+				{
+					in: &chain.Block{
+						Nonce:     234,
+						Timestamp: testTimestamp(1),
+						Txs:       []*pb.Tx{testReward(1)},
+						Prevhash:  []byte{1, 2, 3},
+						Target:    testTarget,
+					},
+					out: []byte{2, 3, 4},
+				},
+				// Next two for non-validation hashes
+				{
+					in: &chain.Block{
+						Nonce:     234,
+						Timestamp: testTimestamp(1),
+						Txs:       []*pb.Tx{testReward(1)},
+						Prevhash:  []byte{1, 2, 3},
+						Target:    testTarget,
+					},
+					out: []byte{2, 3, 4},
+				},
+				{
+					in: &chain.Block{
+						Nonce:     234,
+						Timestamp: testTimestamp(1),
+						Txs:       []*pb.Tx{testReward(1)},
+						Prevhash:  []byte{1, 2, 3},
+						Target:    testTarget,
 					},
 					out: []byte{2, 3, 4},
 				},
@@ -1301,9 +1617,11 @@ func TestSetChain(t *testing.T) {
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			for _, call := range c.mockHashCalls {
-				mockHasher.EXPECT().Hash(call.in).Return(call.out)
+				mockHasher.EXPECT().Hash(currentBlock(call.in)).Return(call.out)
 			}
 
+			currentForm(c.nodeSetup.chain)
+
 			mockMiner.EXPECT().UpdatePrevHash(gomock.Any()).Times(c.mockMinerCalls.numUpdatePrevHash)
 			mockMiner.EXPECT().SetTarget(gomock.Any()).Times(c.mockMinerCalls.numSetTarget)
 			mockMiner.EXPECT().SetTxs(gomock.Any()).Times(c.mockMinerCalls.numClearTxs)
@@ -1311,11 +1629,18 @@ func TestSetChain(t *testing.T) {
 			n := node{
 				chain:        c.nodeSetup.chain,
 				recalcPeriod: c.nodeSetup.recalcPeriod,
+				difficulty:   MinDifficulty,
 				miners:       []mining.Miner{mockMiner},
 				hasher:       mockHasher,
+				params:       params.Mainnet,
+				clock:        nettime.New(),
+				txpool:       mempool.New(mempool.DefaultConfig),
+				txindex:      txindex.New(txindex.DefaultMaxSeen),
+				balances:     balancesOf(c.nodeSetup.chain),
+				tree:         chain.NewTree(c.nodeSetup.chain),
 			}
 
-			got := n.setChain(c.input.chain, c.input.trusted)
+			got := n.setChain(currentForm(c.input.chain), c.input.trusted)
 
 			if got != c.expectedReplace {
 				t.Errorf("expected replacement: %v, got %v", c.expectedReplace, got)
@@ -1337,14 +1662,14 @@ func TestSetChain(t *testing.T) {
 func TestCalcDifficulty(t *testing.T) {
 	cases := []struct {
 		name           string
-		node           node
+		node           *node
 		actualDur      time.Duration
 		currDifficulty float64
 		expected       float64
 	}{
 		{
 			name: "An exact match between actual and desired duration returns the same difficulty",
-			node: node{
+			node: &node{
 				targetDurPerBlock: 10 * time.Minute,
 			},
 			actualDur:      10 * time.Minute,
@@ -1353,7 +1678,7 @@ func TestCalcDifficulty(t *testing.T) {
 		},
 		{
 			name: "An actual duration half of expected returns a difficulty twice of the current value",
-			node: node{
+			node: &node{
 				targetDurPerBlock: 10 * time.Minute,
 			},
 			actualDur:      5 * time.Minute,
@@ -1362,7 +1687,7 @@ func TestCalcDifficulty(t *testing.T) {
 		},
 		{
 			name: "An actual duration twice of expected returns a difficulty half of the current value",
-			node: node{
+			node: &node{
 				targetDurPerBlock: 10 * time.Minute,
 			},
 			actualDur:      20 * time.Minute,
@@ -1371,7 +1696,7 @@ func TestCalcDifficulty(t *testing.T) {
 		},
 		{
 			name: "An actual duration 1.5 times of expected returns a difficulty quotient of 1.5 of the current value",
-			node: node{
+			node: &node{
 				targetDurPerBlock: 10 * time.Minute,
 			},
 			actualDur:      15 * time.Minute,
@@ -1380,7 +1705,7 @@ func TestCalcDifficulty(t *testing.T) {
 		},
 		{
 			name: "An actual duration 10 times of expected returns a difficulty confined to 1/4 the previous amount, even though the calculation would be 1/10",
-			node: node{
+			node: &node{
 				targetDurPerBlock: 10 * time.Minute,
 			},
 			actualDur:      100 * time.Minute,
@@ -1389,7 +1714,7 @@ func TestCalcDifficulty(t *testing.T) {
 		},
 		{
 			name: "An actual duration 1/10 of expected returns a difficulty confined to 4 times the previous amount, even though the calculation would be x10",
-			node: node{
+			node: &node{
 				targetDurPerBlock: 10 * time.Minute,
 			},
 			actualDur:      1 * time.Minute,
@@ -1398,7 +1723,7 @@ func TestCalcDifficulty(t *testing.T) {
 		},
 		{
 			name: "Actual duration very close (slightly longer) to desired adjusts slightly, highlighting math accuracy",
-			node: node{
+			node: &node{
 				targetDurPerBlock: 10 * time.Minute,
 			},
 			actualDur:      10*time.Minute + 4*time.Second + 563*time.Millisecond,
@@ -1407,7 +1732,7 @@ func TestCalcDifficulty(t *testing.T) {
 		},
 		{
 			name: "Actual duration very close (slightly shorter) to desired adjusts slightly, highlighting math accuracy",
-			node: node{
+			node: &node{
 				targetDurPerBlock: 10 * time.Millisecond,
 			},
 			actualDur:      9*time.Millisecond + 981_613*time.Nanosecond,
@@ -1975,7 +2300,7 @@ func TestGetCreditFor(t *testing.T) {
 		pubkey   string
 		chain    *chain.Chain
 		txpool   []*pb.Tx
-		expected float64
+		expected uint64
 	}{
 		{
 			name:   "Empty chain and txpool means credit of 0",
@@ -1998,6 +2323,7 @@ func TestGetCreditFor(t *testing.T) {
 							Txs: []*pb.Tx{
 								{
 									Value:     25,
+									Sender:    "xyz789",
 									Recipient: "abc123",
 								},
 							},
@@ -2018,6 +2344,7 @@ func TestGetCreditFor(t *testing.T) {
 							Txs: []*pb.Tx{
 								{
 									Value:     25,
+									Sender:    "xyz789",
 									Recipient: "abc123",
 								},
 							},
@@ -2046,6 +2373,7 @@ func TestGetCreditFor(t *testing.T) {
 							Txs: []*pb.Tx{
 								{
 									Value:     25,
+									Sender:    "xyz789",
 									Recipient: "abc123",
 								},
 							},
@@ -2071,6 +2399,7 @@ func TestGetCreditFor(t *testing.T) {
 							Txs: []*pb.Tx{
 								{
 									Value:     25,
+									Sender:    "xyz789",
 									Recipient: "abc123",
 								},
 							},
@@ -2081,18 +2410,45 @@ func TestGetCreditFor(t *testing.T) {
 			txpool: []*pb.Tx{
 				{
 					Value:     10,
+					Sender:    "xyz789",
 					Recipient: "abc123",
 				},
 			},
 			expected: 25,
 		},
+		{
+			name:   "Chain with a block solve reward too recent to spend leaves it out of the available credit",
+			pubkey: "abc123",
+			chain: &chain.Chain{
+				Pbc: &blockchain.Chain{
+					Blocks: []*pb.Block{
+						{},
+						{
+							Txs: []*pb.Tx{
+								{
+									Value:     25,
+									Recipient: "abc123",
+									Height:    1,
+								},
+							},
+						},
+					},
+				},
+			},
+			txpool:   nil,
+			expected: 0,
+		},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			n := node{
-				chain:  c.chain,
-				txpool: c.txpool,
+				chain:    c.chain,
+				params:   params.Mainnet,
+				clock:    nettime.New(),
+				txpool:   txpoolOf(t, c.txpool...),
+				txindex:  txindex.New(txindex.DefaultMaxSeen),
+				balances: balancesOf(c.chain),
 			}
 
 			got := n.getCreditFor(c.pubkey)
@@ -2102,3 +2458,1542 @@ func TestGetCreditFor(t *testing.T) {
 		})
 	}
 }
+
+func TestResetTxpoolRewardAddr(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	master, err := wallet.NewMaster(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	account, err := wallet.NewAccount(master, 0, address.Mainnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	derivedAt := func(index uint32) string {
+		addr, err := account.Address(index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return addr
+	}
+
+	cases := []struct {
+		name          string
+		rewardAccount *wallet.Account
+		chainLen      int
+		expected      string
+	}{
+		{
+			name:          "Without an HD account the reward goes to the node's address",
+			rewardAccount: nil,
+			chainLen:      3,
+			expected:      "SteveHolt",
+		},
+		{
+			name:          "With an HD account the reward goes to the address derived at the next block height",
+			rewardAccount: account,
+			chainLen:      3,
+			expected:      derivedAt(3),
+		},
+		{
+			name:          "Each block height pays a fresh address",
+			rewardAccount: account,
+			chainLen:      4,
+			expected:      derivedAt(4),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mockMiner := mm.NewMockMiner(ctrl)
+
+			var got []*pb.Tx
+			mockMiner.EXPECT().SetTxs(gomock.Any()).Do(func(txs []*pb.Tx) {
+				got = txs
+			})
+
+			n := node{
+				pubkey:        "SteveHolt",
+				rewardAccount: c.rewardAccount,
+				chain: &chain.Chain{
+					Pbc: &pb.Chain{
+						Blocks: make([]*pb.Block, c.chainLen),
+					},
+				},
+				miners:   []mining.Miner{mockMiner},
+				params:   params.Mainnet,
+				clock:    nettime.New(),
+				txpool:   mempool.New(mempool.DefaultConfig),
+				txindex:  txindex.New(txindex.DefaultMaxSeen),
+				balances: chain.NewBalances(),
+			}
+
+			n.updateMinerTxs()
+
+			if len(got) != 1 || got[0].GetRecipient() != c.expected {
+				t.Errorf("expected reward to %s, got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestValidateNonces(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHasher := mocks.NewMockHasher(ctrl)
+	mockHasher.EXPECT().Hash(gomock.Any()).Return([]byte{1}).AnyTimes()
+
+	alice, err := transactions.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bob, err := transactions.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signedTx := func(priv ed25519.PrivateKey, nonce uint64) *pb.Tx {
+		tx := &pb.Tx{
+			Timestamp: &timestamp.Timestamp{
+				Seconds: 646459200,
+			},
+			Value:     1,
+			Recipient: transactions.Address(bob, address.Mainnet),
+			Nonce:     nonce,
+		}
+		transactions.Sign(tx, priv, address.Mainnet)
+		return tx
+	}
+
+	// Alice is paid every reward, so she can cover her txs
+	reward := func(height uint64) *pb.Tx {
+		return &pb.Tx{
+			Recipient: transactions.Address(alice, address.Mainnet),
+			Value:     blockSubsidy,
+			Height:    height,
+		}
+	}
+
+	// All blocks hash to and link to {1}, and meet the test target, so only the
+	// txs decide validity
+	chainOf := func(blocksTxs ...[]*pb.Tx) *chain.Chain {
+		blocks := []*pb.Block{{}}
+		for i, txs := range blocksTxs {
+			blocks = append(blocks, &pb.Block{
+				Prevhash:  []byte{1},
+				Target:    testTarget,
+				Timestamp: testTimestamp(uint64(i + 1)),
+				Txs:       append([]*pb.Tx{reward(uint64(i + 1))}, txs...),
+			})
+		}
+
+		return &chain.Chain{
+			Pbc: &pb.Chain{
+				Blocks: blocks,
+			},
+		}
+	}
+
+	replayed := signedTx(alice, 0)
+
+	cases := []struct {
+		name  string
+		chain *chain.Chain
+		want  bool
+	}{
+		{
+			name: "Sequential nonces across blocks and senders are valid",
+			chain: chainOf(
+				[]*pb.Tx{signedTx(alice, 0), signedTx(bob, 0), signedTx(alice, 1)},
+				[]*pb.Tx{signedTx(alice, 2)},
+			),
+			want: true,
+		},
+		{
+			name:  "A sender's first tx must have nonce 0",
+			chain: chainOf([]*pb.Tx{signedTx(alice, 1)}),
+			want:  false,
+		},
+		{
+			name: "Skipping a nonce is not valid",
+			chain: chainOf(
+				[]*pb.Tx{signedTx(alice, 0)},
+				[]*pb.Tx{signedTx(alice, 2)},
+			),
+			want: false,
+		},
+		{
+			name: "Replaying a tx in a later block is not valid",
+			chain: chainOf(
+				[]*pb.Tx{replayed},
+				[]*pb.Tx{replayed},
+			),
+			want: false,
+		},
+		{
+			name:  "Reusing a nonce for a different tx is not valid",
+			chain: chainOf([]*pb.Tx{signedTx(alice, 0), signedTx(alice, 0)}),
+			want:  false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			n := node{
+				hasher: mockHasher,
+				params: instantParams(),
+				clock:  nettime.New(),
+			}
+
+			err := n.Validate(currentForm(c.chain))
+
+			if got := err == nil; got != c.want {
+				t.Errorf("want %v, got %v", c.want, err)
+			}
+		})
+	}
+}
+
+func TestValidateReward(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHasher := mocks.NewMockHasher(ctrl)
+	mockHasher.EXPECT().Hash(gomock.Any()).Return([]byte{1}).AnyTimes()
+
+	priv, err := transactions.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	miner := transactions.Address(priv, address.Mainnet)
+
+	feeTx := &pb.Tx{
+		Timestamp: &timestamp.Timestamp{
+			Seconds: 646459200,
+		},
+		Value:     1,
+		Fee:       2,
+		Recipient: miner,
+	}
+	transactions.Sign(feeTx, priv, address.Mainnet)
+
+	cases := []struct {
+		name string
+		txs  []*pb.Tx
+		want bool
+	}{
+		{
+			name: "A reward of the subsidy alone is valid",
+			txs: []*pb.Tx{
+				{Recipient: miner, Height: 1, Value: blockSubsidy},
+			},
+			want: true,
+		},
+		{
+			name: "A reward of the subsidy plus fees is valid",
+			txs: []*pb.Tx{
+				{Recipient: miner, Height: 1, Value: blockSubsidy + 2},
+				feeTx,
+			},
+			want: true,
+		},
+		{
+			name: "A reward beyond the subsidy plus fees is not valid",
+			txs: []*pb.Tx{
+				{Recipient: miner, Height: 1, Value: blockSubsidy + 3},
+				feeTx,
+			},
+			want: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			n := node{
+				hasher: mockHasher,
+				params: instantParams(),
+				clock:  nettime.New(),
+			}
+
+			err := n.Validate(currentForm(&chain.Chain{
+				Pbc: &pb.Chain{
+					Blocks: []*pb.Block{
+						{},
+						{
+							Prevhash:  []byte{1},
+							Target:    testTarget,
+							Timestamp: testTimestamp(1),
+							Txs:       c.txs,
+						},
+					},
+				},
+			}))
+
+			if got := err == nil; got != c.want {
+				t.Errorf("want %v, got %v", c.want, err)
+			}
+		})
+	}
+}
+
+func TestSelectTxs(t *testing.T) {
+	lucille := &pb.Tx{Sender: "Lucille", Recipient: "Gob", Value: 1, Nonce: 0, Fee: 2}
+	transactions.SetHash(lucille)
+
+	// Every sender is paid 100 in the first block, spendable from the next
+	funding := &pb.Block{
+		Txs: []*pb.Tx{
+			{Recipient: "Lucille", Value: 100},
+			{Recipient: "Oscar", Value: 100},
+			{Recipient: "Buster", Value: 100},
+		},
+	}
+
+	fundedChain := &chain.Chain{
+		Pbc: &pb.Chain{
+			Blocks: []*pb.Block{{}, funding},
+		},
+	}
+
+	selectParams := *params.Mainnet
+	selectParams.CoinbaseMaturity = 1
+
+	cases := []struct {
+		name     string
+		chain    *chain.Chain
+		txpool   []*pb.Tx
+		maxSize  int
+		expected []int
+	}{
+		{
+			name:  "A sender's txs are ordered by nonce",
+			chain: fundedChain,
+			txpool: []*pb.Tx{
+				{Sender: "Lucille", Recipient: "Gob", Value: 1, Nonce: 1},
+				{Sender: "Lucille", Recipient: "Gob", Value: 1, Nonce: 0},
+			},
+			maxSize:  MaxBlockTxsSize,
+			expected: []int{1, 0},
+		},
+		{
+			name:  "A tx is held back while an earlier nonce is missing",
+			chain: fundedChain,
+			txpool: []*pb.Tx{
+				{Sender: "Lucille", Recipient: "Gob", Value: 1, Nonce: 0},
+				{Sender: "Lucille", Recipient: "Gob", Value: 1, Nonce: 2},
+				{Sender: "Oscar", Recipient: "Gob", Value: 1, Nonce: 0},
+			},
+			maxSize:  MaxBlockTxsSize,
+			expected: []int{0, 2},
+		},
+		{
+			name: "Nonces continue on from the sender's txs in the chain",
+			chain: &chain.Chain{
+				Pbc: &pb.Chain{
+					Blocks: []*pb.Block{
+						{},
+						funding,
+						{
+							Txs: []*pb.Tx{
+								{Sender: "Lucille", Recipient: "Gob", Value: 1, Nonce: 0},
+							},
+						},
+					},
+				},
+			},
+			txpool: []*pb.Tx{
+				{Sender: "Lucille", Recipient: "Gob", Value: 1, Nonce: 1},
+			},
+			maxSize:  MaxBlockTxsSize,
+			expected: []int{0},
+		},
+		{
+			name:  "Txs paying a higher fee are selected first",
+			chain: fundedChain,
+			txpool: []*pb.Tx{
+				{Sender: "Lucille", Recipient: "Gob", Value: 1, Nonce: 0, Fee: 1},
+				{Sender: "Oscar", Recipient: "Gob", Value: 1, Nonce: 0, Fee: 3},
+				{Sender: "Buster", Recipient: "Gob", Value: 1, Nonce: 0, Fee: 2},
+			},
+			maxSize:  MaxBlockTxsSize,
+			expected: []int{1, 2, 0},
+		},
+		{
+			name:  "A high fee tx waits for the lower nonce it follows",
+			chain: fundedChain,
+			txpool: []*pb.Tx{
+				{Sender: "Lucille", Recipient: "Gob", Value: 1, Nonce: 1, Fee: 5},
+				{Sender: "Lucille", Recipient: "Gob", Value: 1, Nonce: 0, Fee: 1},
+				{Sender: "Oscar", Recipient: "Gob", Value: 1, Nonce: 0, Fee: 2},
+			},
+			maxSize:  MaxBlockTxsSize,
+			expected: []int{2, 1, 0},
+		},
+		{
+			name:  "Equal fee rates keep the order of arrival",
+			chain: fundedChain,
+			txpool: []*pb.Tx{
+				{Sender: "Oscar", Recipient: "Gob", Value: 1, Nonce: 0},
+				{Sender: "Lucille", Recipient: "Gob", Value: 1, Nonce: 0},
+			},
+			maxSize:  MaxBlockTxsSize,
+			expected: []int{0, 1},
+		},
+		{
+			name:  "A locked tx holds back its sender's later txs until it matures",
+			chain: fundedChain,
+			txpool: []*pb.Tx{
+				{Sender: "Lucille", Recipient: "Gob", Value: 1, Nonce: 0, Lock: 3},
+				{Sender: "Lucille", Recipient: "Gob", Value: 1, Nonce: 1},
+				{Sender: "Oscar", Recipient: "Gob", Value: 1, Nonce: 0, Lock: 2},
+			},
+			maxSize:  MaxBlockTxsSize,
+			expected: []int{2},
+		},
+		{
+			name:  "A tx its sender's credit does not cover is left out, with their later txs",
+			chain: fundedChain,
+			txpool: []*pb.Tx{
+				{Sender: "Lucille", Recipient: "Gob", Value: 60, Nonce: 0},
+				{Sender: "Lucille", Recipient: "Gob", Value: 60, Nonce: 1},
+				{Sender: "Lucille", Recipient: "Gob", Value: 1, Nonce: 2},
+				{Sender: "Oscar", Recipient: "Gob", Value: 1, Nonce: 0},
+			},
+			maxSize:  MaxBlockTxsSize,
+			expected: []int{0, 3},
+		},
+		{
+			name:  "Credit paid by a tx selected before can be spent",
+			chain: fundedChain,
+			txpool: []*pb.Tx{
+				{Sender: "Oscar", Recipient: "Lucille", Value: 50, Nonce: 0, Fee: 1},
+				{Sender: "Lucille", Recipient: "Gob", Value: 150, Nonce: 0},
+			},
+			maxSize:  MaxBlockTxsSize,
+			expected: []int{0, 1},
+		},
+		{
+			name:  "Txs beyond the size limit are left out",
+			chain: fundedChain,
+			txpool: []*pb.Tx{
+				lucille,
+				{Sender: "Oscar", Recipient: "Gob", Value: 1, Nonce: 0, Fee: 1},
+			},
+			maxSize:  proto.Size(lucille),
+			expected: []int{0},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			n := node{
+				chain:    c.chain,
+				params:   &selectParams,
+				clock:    nettime.New(),
+				txpool:   txpoolOf(t, c.txpool...),
+				txindex:  txindex.New(txindex.DefaultMaxSeen),
+				balances: balancesOf(c.chain),
+			}
+
+			got := n.selectTxs(c.maxSize)
+
+			expected := make([]*pb.Tx, 0, len(c.expected))
+			for _, i := range c.expected {
+				expected = append(expected, c.txpool[i])
+			}
+
+			if len(got) != len(expected) {
+				t.Fatalf("expected %v, got %v", expected, got)
+			}
+
+			for i := range got {
+				if got[i] != expected[i] {
+					t.Errorf("expected %v, got %v", expected, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestBlockTemplateReward(t *testing.T) {
+	c := &chain.Chain{
+		Pbc: &pb.Chain{
+			Blocks: []*pb.Block{
+				{},
+				{Txs: []*pb.Tx{{Recipient: "Lucille", Value: 100}, {Recipient: "Oscar", Value: 100}}},
+			},
+		},
+	}
+
+	templateParams := *params.Mainnet
+	templateParams.CoinbaseMaturity = 1
+
+	n := node{
+		pubkey:   "Gob",
+		chain:    c,
+		balances: balancesOf(c),
+		params:   &templateParams,
+		clock:    nettime.New(),
+		txpool: txpoolOf(t,
+			&pb.Tx{Sender: "Lucille", Recipient: "Gob", Value: 1, Nonce: 0, Fee: 15},
+			&pb.Tx{Sender: "Oscar", Recipient: "Gob", Value: 1, Nonce: 0, Fee: 2},
+		),
+	}
+
+	got := n.blockTemplate()
+
+	if len(got) != 3 {
+		t.Fatalf("expected reward and 2 txs, got %v", got)
+	}
+
+	if got[0].GetSender() != "" || got[0].GetRecipient() != "Gob" {
+		t.Errorf("expected reward to Gob first, got %v", got[0])
+	}
+
+	if expected := blockSubsidy + 17; got[0].GetValue() != expected {
+		t.Errorf("expected reward of %v, got %v", expected, got[0].GetValue())
+	}
+}
+
+func TestGetNextNonce(t *testing.T) {
+	c := &chain.Chain{
+		Pbc: &pb.Chain{
+			Blocks: []*pb.Block{
+				{
+					Txs: []*pb.Tx{
+						{Sender: "Annyong", Nonce: 0},
+					},
+				},
+			},
+		},
+	}
+
+	n := node{
+		chain:    c,
+		balances: balancesOf(c),
+		params:   params.Mainnet,
+		clock:    nettime.New(),
+		txpool: txpoolOf(t,
+			&pb.Tx{Sender: "Annyong", Nonce: 1},
+			&pb.Tx{Sender: "Annyong", Nonce: 3},
+		),
+	}
+
+	// Nonce 2 is the gap in the pending txs
+	if got := n.getNextNonce("Annyong"); got != 2 {
+		t.Errorf("expected %v, got %v", 2, got)
+	}
+}
+
+// txpoolOf returns a txpool holding the txs, hashing any not yet hashed
+func txpoolOf(t *testing.T, txs ...*pb.Tx) mempool.Mempool {
+	txpool := mempool.New(mempool.DefaultConfig)
+
+	for _, tx := range txs {
+		if tx.GetHash() == nil {
+			transactions.SetHash(tx)
+		}
+
+		if err := txpool.Add(tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return txpool
+}
+
+// balancesOf returns the balances of the chain, with all of its blocks connected
+func balancesOf(c *chain.Chain) chain.Balances {
+	balances := chain.NewBalances()
+	for height := 0; height < c.Length(); height++ {
+		balances.ConnectBlock(c.BlockByIdx(height), c.Hash(uint64(height)))
+	}
+
+	return balances
+}
+
+func TestReconcileTxpool(t *testing.T) {
+	orphaned := &pb.Tx{Sender: "Kitty", Recipient: "Gob", Nonce: 0, Value: 1, Version: canonical.TxVersion}
+	included := &pb.Tx{Sender: "Barry", Recipient: "Gob", Nonce: 0, Value: 2, Version: canonical.TxVersion}
+	pending := &pb.Tx{Sender: "Barry", Recipient: "Gob", Nonce: 1, Value: 3, Version: canonical.TxVersion}
+	// Spends credit paid to Lindsay only in the orphaned blocks
+	uncovered := &pb.Tx{Sender: "Lindsay", Recipient: "Gob", Nonce: 0, Value: 50, Version: canonical.TxVersion}
+	for _, tx := range []*pb.Tx{orphaned, included, pending, uncovered} {
+		transactions.SetHash(tx)
+	}
+
+	genesis := &pb.Block{Nonce: 1}
+
+	prev := &chain.Chain{
+		Pbc: &pb.Chain{
+			Blocks: []*pb.Block{
+				genesis,
+				{Nonce: 2, Txs: []*pb.Tx{{Recipient: "Lindsay", Value: 100}, orphaned}},
+				{Nonce: 5, Txs: []*pb.Tx{{Recipient: "Gob", Value: 100}, uncovered}},
+			},
+		},
+	}
+
+	next := &chain.Chain{
+		Pbc: &pb.Chain{
+			Blocks: []*pb.Block{
+				genesis,
+				{Nonce: 3, Txs: []*pb.Tx{{Recipient: "Kitty", Value: 100}, {Recipient: "Barry", Value: 100}}},
+				{Nonce: 4, Txs: []*pb.Tx{{Recipient: "Gob", Value: 100}, included}},
+				{Nonce: 6},
+			},
+		},
+	}
+
+	reconcileParams := *params.Mainnet
+	reconcileParams.CoinbaseMaturity = 1
+
+	// The chain and balances are synced to the new chain before the txpool is
+	// reconciled
+	n := node{
+		chain:    next,
+		params:   &reconcileParams,
+		clock:    nettime.New(),
+		txpool:   txpoolOf(t, included, pending),
+		txindex:  txindex.New(txindex.DefaultMaxSeen),
+		balances: balancesOf(next),
+	}
+
+	n.reconcileTxpool(prev, next)
+
+	if !n.txpool.Has(orphaned.GetHash()) {
+		t.Errorf("expected tx of orphaned block to be returned to txpool")
+	}
+
+	if n.txpool.Has(uncovered.GetHash()) {
+		t.Errorf("expected tx of orphaned block no longer covered by its sender's credit to be dropped")
+	}
+
+	if n.txpool.Has(included.GetHash()) {
+		t.Errorf("expected tx included in the new chain to be dropped from txpool")
+	}
+
+	if !n.txpool.Has(pending.GetHash()) {
+		t.Errorf("expected tx not yet included to remain in txpool")
+	}
+
+	if n.txpool.Len() != 2 {
+		t.Errorf("expected 2 txs in txpool, got %v", n.txpool.All())
+	}
+}
+
+func TestReconcileTxpoolLegacy(t *testing.T) {
+	// A version 0 tx, as of the blocks of stored chains
+	legacy := &pb.Tx{Sender: "Kitty", Recipient: "Gob", Nonce: 0, Value: 1}
+	transactions.SetHash(legacy)
+
+	genesis := &pb.Block{Nonce: 1}
+	funding := &pb.Block{Nonce: 2, Txs: []*pb.Tx{{Recipient: "Kitty", Value: 100}}}
+
+	prev := &chain.Chain{
+		Pbc: &pb.Chain{
+			Blocks: []*pb.Block{genesis, funding, {Nonce: 3, Txs: []*pb.Tx{legacy}}},
+		},
+	}
+
+	next := &chain.Chain{
+		Pbc: &pb.Chain{
+			Blocks: []*pb.Block{genesis, funding, {Nonce: 4}, {Nonce: 5}},
+		},
+	}
+
+	reconcileParams := *params.Mainnet
+	reconcileParams.CoinbaseMaturity = 1
+
+	n := node{
+		chain:    next,
+		params:   &reconcileParams,
+		clock:    nettime.New(),
+		txpool:   txpoolOf(t),
+		txindex:  txindex.New(txindex.DefaultMaxSeen),
+		balances: balancesOf(next),
+	}
+
+	n.reconcileTxpool(prev, next)
+
+	if n.txpool.Has(legacy.GetHash()) {
+		t.Errorf("expected legacy tx of orphaned block to be left out of the txpool")
+	}
+}
+
+func TestValidateUTXO(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHasher := mocks.NewMockHasher(ctrl)
+	mockHasher.EXPECT().Hash(gomock.Any()).Return([]byte{1}).AnyTimes()
+
+	alice, err := transactions.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	aliceAddr := transactions.Address(alice, address.Utxonet)
+
+	bob, err := transactions.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobAddr := transactions.Address(bob, address.Utxonet)
+
+	rewardTx := func(recipient string, value uint64, height uint64) *pb.Tx {
+		tx := &pb.Tx{
+			Outputs: []*pb.TxOut{{Recipient: recipient, Value: value}},
+			Height:  height,
+		}
+		transactions.SetHash(tx)
+		return tx
+	}
+
+	aliceReward, bobReward := rewardTx(aliceAddr, blockSubsidy, 1), rewardTx(bobAddr, blockSubsidy, 1)
+
+	spendTx := func(priv ed25519.PrivateKey, from *pb.Tx, to string, value uint64, fee uint64) *pb.Tx {
+		tx := &pb.Tx{
+			Timestamp: &timestamp.Timestamp{
+				Seconds: 646459200,
+			},
+			Inputs:  []*pb.OutPoint{{TxHash: from.GetHash(), Index: 0}},
+			Outputs: []*pb.TxOut{{Recipient: to, Value: value}},
+			Fee:     fee,
+		}
+		transactions.Sign(tx, priv, address.Utxonet)
+		return tx
+	}
+
+	spend := spendTx(alice, aliceReward, bobAddr, blockSubsidy-2, 2)
+
+	accountTx := &pb.Tx{
+		Timestamp: &timestamp.Timestamp{
+			Seconds: 646459200,
+		},
+		Value:     1,
+		Recipient: bobAddr,
+	}
+	transactions.Sign(accountTx, alice, address.Utxonet)
+
+	// All blocks hash to and link to {1}, and meet the test target, so only the
+	// txs decide validity
+	chainOf := func(blocksTxs ...[]*pb.Tx) *chain.Chain {
+		blocks := []*pb.Block{{}}
+		for i, txs := range blocksTxs {
+			blocks = append(blocks, &pb.Block{
+				Prevhash:  []byte{1},
+				Target:    testTarget,
+				Timestamp: testTimestamp(uint64(i + 1)),
+				Txs:       txs,
+			})
+		}
+
+		return &chain.Chain{
+			Pbc: &pb.Chain{
+				Blocks: blocks,
+			},
+		}
+	}
+
+	// Rewards can be spent from the block after the one paying them
+	utxoParams := *params.Utxonet
+	utxoParams.CoinbaseMaturity = 1
+
+	cases := []struct {
+		name  string
+		chain *chain.Chain
+		want  bool
+	}{
+		{
+			name: "Spending an owned output of an earlier block is valid",
+			chain: chainOf(
+				[]*pb.Tx{aliceReward},
+				[]*pb.Tx{rewardTx(bobAddr, blockSubsidy+2, 2), spend},
+			),
+			want: true,
+		},
+		{
+			name: "Spending an output twice is not valid",
+			chain: chainOf(
+				[]*pb.Tx{aliceReward},
+				[]*pb.Tx{rewardTx(bobAddr, blockSubsidy+2, 2), spend},
+				[]*pb.Tx{rewardTx(bobAddr, blockSubsidy+2, 3), spend},
+			),
+			want: false,
+		},
+		{
+			name: "Spending an output owned by somebody else is not valid",
+			chain: chainOf(
+				[]*pb.Tx{bobReward},
+				[]*pb.Tx{rewardTx(aliceAddr, blockSubsidy, 2), spendTx(alice, bobReward, aliceAddr, blockSubsidy, 0)},
+			),
+			want: false,
+		},
+		{
+			name: "Creating more than is spent is not valid",
+			chain: chainOf(
+				[]*pb.Tx{aliceReward},
+				[]*pb.Tx{rewardTx(bobAddr, blockSubsidy, 2), spendTx(alice, aliceReward, bobAddr, blockSubsidy+1, 0)},
+			),
+			want: false,
+		},
+		{
+			name: "Spending a reward before it matures is not valid",
+			chain: chainOf(
+				[]*pb.Tx{aliceReward, spend},
+			),
+			want: false,
+		},
+		{
+			name: "A reward beyond the subsidy plus fees is not valid",
+			chain: chainOf(
+				[]*pb.Tx{aliceReward},
+				[]*pb.Tx{rewardTx(bobAddr, blockSubsidy+3, 2), spend},
+			),
+			want: false,
+		},
+		{
+			name:  "A tx of the account ledger is not valid",
+			chain: chainOf([]*pb.Tx{aliceReward, accountTx}),
+			want:  false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			n := node{
+				hasher: mockHasher,
+				params: &utxoParams,
+				clock:  nettime.New(),
+			}
+
+			err := n.Validate(currentForm(c.chain))
+
+			if got := err == nil; got != c.want {
+				t.Errorf("want %v, got %v", c.want, err)
+			}
+		})
+	}
+}
+
+func TestSyncUTXOs(t *testing.T) {
+	rewardTx := func(recipient string, message string) *pb.Tx {
+		tx := &pb.Tx{
+			Message: message,
+			Outputs: []*pb.TxOut{{Recipient: recipient, Value: blockSubsidy}},
+		}
+		transactions.SetHash(tx)
+		return tx
+	}
+
+	genesis := &pb.Block{Nonce: 1}
+
+	prev := &chain.Chain{
+		Pbc: &pb.Chain{
+			Blocks: []*pb.Block{
+				genesis,
+				{Nonce: 2, Txs: []*pb.Tx{rewardTx("Kitty", "Marry me!")}},
+			},
+		},
+	}
+
+	next := &chain.Chain{
+		Pbc: &pb.Chain{
+			Blocks: []*pb.Block{
+				genesis,
+				{Nonce: 3, Txs: []*pb.Tx{rewardTx("Barry", "Bob Loblaw")}},
+				{Nonce: 4, Txs: []*pb.Tx{rewardTx("Barry", "Law Blog")}},
+			},
+		},
+	}
+
+	n := node{
+		params: params.Utxonet,
+		clock:  nettime.New(),
+		utxos:  utxo.New(params.Utxonet.CoinbaseMaturity),
+	}
+
+	n.syncUTXOs(nil, prev)
+	if n.utxos.Balance("Kitty") != blockSubsidy {
+		t.Fatalf("expected reward of chain to be unspent")
+	}
+
+	n.syncUTXOs(prev, next)
+
+	if n.utxos.Balance("Kitty") != 0 {
+		t.Errorf("expected reward of orphaned block to be undone")
+	}
+
+	if n.utxos.Balance("Barry") != 2*blockSubsidy {
+		t.Errorf("expected rewards of new chain to be unspent, got %d", n.utxos.Balance("Barry"))
+	}
+
+	if len(n.undos) != next.Length() {
+		t.Errorf("expected an undo per block, got %d", len(n.undos))
+	}
+}
+
+func TestValidateLocks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHasher := mocks.NewMockHasher(ctrl)
+	mockHasher.EXPECT().Hash(gomock.Any()).Return([]byte{1}).AnyTimes()
+
+	priv, err := transactions.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unlockTime := time.Date(2019, 5, 26, 0, 0, 0, 0, time.UTC)
+
+	lockedTx := func(lock uint64) *pb.Tx {
+		tx := &pb.Tx{
+			Timestamp: &timestamp.Timestamp{
+				Seconds: 646459200,
+			},
+			Value:     1,
+			Recipient: transactions.Address(priv, address.Mainnet),
+			Lock:      lock,
+		}
+		transactions.Sign(tx, priv, address.Mainnet)
+		return tx
+	}
+
+	// The tx is included in the block at height 2, timestamped at blockTime
+	chainOf := func(tx *pb.Tx, blockTime time.Time) *chain.Chain {
+		return &chain.Chain{
+			Pbc: &pb.Chain{
+				Blocks: []*pb.Block{
+					{},
+					{
+						Prevhash:  []byte{1},
+						Target:    testTarget,
+						Timestamp: testTimestamp(1),
+						Txs:       []*pb.Tx{{Recipient: transactions.Address(priv, address.Mainnet), Value: blockSubsidy, Height: 1}},
+					},
+					{
+						Prevhash:  []byte{1},
+						Target:    testTarget,
+						Timestamp: &timestamp.Timestamp{Seconds: blockTime.Unix()},
+						Txs:       []*pb.Tx{testReward(2), tx},
+					},
+				},
+			},
+		}
+	}
+
+	cases := []struct {
+		name  string
+		chain *chain.Chain
+		want  bool
+	}{
+		{
+			name:  "A tx locked until the height of its block is valid",
+			chain: chainOf(lockedTx(2), unlockTime),
+			want:  true,
+		},
+		{
+			name:  "A tx locked until a later height is not valid",
+			chain: chainOf(lockedTx(3), unlockTime),
+			want:  false,
+		},
+		{
+			name:  "A tx locked until the timestamp of its block is valid",
+			chain: chainOf(lockedTx(uint64(unlockTime.Unix())), unlockTime),
+			want:  true,
+		},
+		{
+			name:  "A tx locked until a later time is not valid",
+			chain: chainOf(lockedTx(uint64(unlockTime.Unix())), unlockTime.Add(-time.Second)),
+			want:  false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			n := node{
+				hasher: mockHasher,
+				params: instantParams(),
+				clock:  nettime.New(),
+			}
+
+			err := n.Validate(currentForm(c.chain))
+
+			if got := err == nil; got != c.want {
+				t.Errorf("want %v, got %v", c.want, err)
+			}
+		})
+	}
+}
+
+func TestValidateLegacy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHasher := mocks.NewMockHasher(ctrl)
+	mockHasher.EXPECT().Hash(gomock.Any()).Return([]byte{1}).AnyTimes()
+
+	// The reward of the block at height 2 claims its whole coin in legacy form
+	legacyReward := testReward(2)
+	legacyReward.LegacyValue = 1
+	transactions.Migrate(legacyReward)
+
+	legacyChain := &chain.Chain{
+		Pbc: &pb.Chain{
+			Blocks: []*pb.Block{
+				{},
+				{
+					Prevhash:  []byte{1},
+					Target:    testTarget,
+					Timestamp: testTimestamp(1),
+					Txs:       []*pb.Tx{testReward(1)},
+				},
+				{
+					Prevhash:  []byte{1},
+					Target:    testTarget,
+					Timestamp: testTimestamp(2),
+					Txs:       []*pb.Tx{legacyReward},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		name         string
+		legacyHeight uint64
+		want         bool
+	}{
+		{
+			name:         "A legacy tx in a block of the migrated stored chain is valid",
+			legacyHeight: 2,
+			want:         true,
+		},
+		{
+			name:         "A legacy tx in a block above the migrated stored chain is not valid",
+			legacyHeight: 1,
+			want:         false,
+		},
+		{
+			name:         "A legacy tx in a chain never migrated is not valid",
+			legacyHeight: 0,
+			want:         false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			n := node{
+				hasher:       mockHasher,
+				params:       instantParams(),
+				clock:        nettime.New(),
+				legacyHeight: c.legacyHeight,
+			}
+
+			err := n.Validate(currentForm(legacyChain))
+
+			if got := err == nil; got != c.want {
+				t.Errorf("want %v, got %v", c.want, err)
+			}
+
+			if !c.want && !errors.Is(err, transactions.ErrLegacyTx) {
+				t.Errorf("expected %v, got %v", transactions.ErrLegacyTx, err)
+			}
+		})
+	}
+}
+
+func TestValidateHeaderVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHasher := mocks.NewMockHasher(ctrl)
+	mockHasher.EXPECT().Hash(gomock.Any()).Return([]byte{1}).AnyTimes()
+
+	// The block at height 2 is of a version 0 header, which does not commit
+	// to its txs
+	unversioned := &pb.Block{
+		Prevhash:  []byte{1},
+		Target:    testTarget,
+		Timestamp: testTimestamp(2),
+		Txs:       []*pb.Tx{testReward(2)},
+	}
+
+	c := &chain.Chain{
+		Pbc: &pb.Chain{
+			Blocks: []*pb.Block{
+				{},
+				(*pb.Block)(currentBlock(&chain.Block{
+					Prevhash:  []byte{1},
+					Target:    testTarget,
+					Timestamp: testTimestamp(1),
+					Txs:       []*pb.Tx{testReward(1)},
+				})),
+				unversioned,
+			},
+		},
+	}
+
+	headers := make([]*pb.BlockHeader, c.Length())
+	for i, block := range c.Pbc.Blocks {
+		headers[i] = (*chain.Block)(block).Header(uint64(i))
+	}
+
+	cases := []struct {
+		name         string
+		legacyHeight uint64
+		err          error
+	}{
+		{
+			name:         "A block of a version 0 header in the stored chain is valid",
+			legacyHeight: 2,
+		},
+		{
+			name:         "A block of a version 0 header above the stored chain is not valid",
+			legacyHeight: 1,
+			err:          ErrLegacyHeader,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			n := node{
+				hasher:       mockHasher,
+				params:       instantParams(),
+				clock:        nettime.New(),
+				legacyHeight: tc.legacyHeight,
+			}
+
+			for _, err := range []error{n.Validate(c), n.validateHeaders(headers)} {
+				if tc.err == nil {
+					if err != nil {
+						t.Errorf("expected chain to be valid, got %s", err)
+					}
+					continue
+				}
+
+				var verr *ValidationError
+				if !errors.As(err, &verr) || verr.Height != 2 || !errors.Is(err, tc.err) {
+					t.Errorf("expected block 2 to fail with %v, got %v", tc.err, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateGenesis(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHasher := mocks.NewMockHasher(ctrl)
+	mockHasher.EXPECT().Hash(gomock.Any()).Return([]byte{1}).AnyTimes()
+
+	chainFrom := func(genesis *pb.Block) *chain.Chain {
+		return &chain.Chain{
+			Pbc: &pb.Chain{
+				Blocks: []*pb.Block{
+					genesis,
+					(*pb.Block)(currentBlock(&chain.Block{
+						Prevhash:  []byte{1},
+						Target:    testTarget,
+						Timestamp: testTimestamp(1),
+						Txs:       []*pb.Tx{testReward(1)},
+					})),
+				},
+			},
+		}
+	}
+
+	// Pays the miner from thin air, which no block solve reward accounts for
+	payout := &pb.Tx{Recipient: testMiner, Value: blockSubsidy, Version: canonical.TxVersion}
+	transactions.SetHash(payout)
+
+	cases := []struct {
+		name    string
+		genesis *pb.Block
+		err     error
+	}{
+		{
+			name:    "A genesis block without txs is valid",
+			genesis: (*pb.Block)(currentBlock(&chain.Block{})),
+		},
+		{
+			name:    "A genesis block of a version 0 header without txs is valid",
+			genesis: &pb.Block{},
+		},
+		{
+			name:    "A genesis block with txs is not valid",
+			genesis: (*pb.Block)(currentBlock(&chain.Block{Txs: []*pb.Tx{payout}})),
+			err:     ErrGenesisTxs,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			n := node{
+				hasher: mockHasher,
+				params: instantParams(),
+				clock:  nettime.New(),
+			}
+
+			c := chainFrom(tc.genesis)
+
+			headers := make([]*pb.BlockHeader, c.Length())
+			for i, block := range c.Pbc.Blocks {
+				headers[i] = (*chain.Block)(block).Header(uint64(i))
+			}
+
+			for _, err := range []error{n.Validate(c), n.validateHeaders(headers)} {
+				if tc.err == nil {
+					if err != nil {
+						t.Errorf("expected chain to be valid, got %s", err)
+					}
+					continue
+				}
+
+				var verr *ValidationError
+				if !errors.As(err, &verr) || verr.Height != 0 || !errors.Is(err, tc.err) {
+					t.Errorf("expected genesis block to fail with %v, got %v", tc.err, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateTxVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHasher := mocks.NewMockHasher(ctrl)
+	mockHasher.EXPECT().Hash(gomock.Any()).Return([]byte{1}).AnyTimes()
+
+	c := currentForm(&chain.Chain{
+		Pbc: &pb.Chain{
+			Blocks: []*pb.Block{
+				{},
+				{
+					Prevhash:  []byte{1},
+					Target:    testTarget,
+					Timestamp: testTimestamp(1),
+					Txs:       []*pb.Tx{testReward(1)},
+				},
+				{
+					Prevhash:  []byte{1},
+					Target:    testTarget,
+					Timestamp: testTimestamp(2),
+					Txs:       []*pb.Tx{testReward(2)},
+				},
+			},
+		},
+	})
+
+	// The reward of the block at height 2 is of version 0, hashed without the
+	// canonical encoding
+	c.Pbc.Blocks[2].Txs[0].Version = 0
+
+	cases := []struct {
+		name         string
+		legacyHeight uint64
+		want         bool
+	}{
+		{
+			name:         "A version 0 tx in a block of the stored chain is valid",
+			legacyHeight: 2,
+			want:         true,
+		},
+		{
+			name:         "A version 0 tx in a block above the stored chain is not valid",
+			legacyHeight: 1,
+			want:         false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			n := node{
+				hasher:       mockHasher,
+				params:       instantParams(),
+				clock:        nettime.New(),
+				legacyHeight: tc.legacyHeight,
+			}
+
+			err := n.Validate(c)
+
+			if got := err == nil; got != tc.want {
+				t.Errorf("want %v, got %v", tc.want, err)
+			}
+
+			if !tc.want && !errors.Is(err, transactions.ErrUnversionedTx) {
+				t.Errorf("expected %v, got %v", transactions.ErrUnversionedTx, err)
+			}
+		})
+	}
+}
+
+func TestValidateSubsidySchedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHasher := mocks.NewMockHasher(ctrl)
+	mockHasher.EXPECT().Hash(gomock.Any()).Return([]byte{1}).AnyTimes()
+
+	priv, err := transactions.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	miner := transactions.Address(priv, address.Mainnet)
+
+	// The subsidy halves every block, and stops at 175: 100 + 50 + 25
+	schedule := &params.Params{
+		Network:         address.Mainnet,
+		Ledger:          params.LedgerAccount,
+		InitialSubsidy:  100,
+		HalvingInterval: 1,
+		MaxSupply:       175,
+	}
+
+	chainOf := func(rewards ...uint64) *chain.Chain {
+		blocks := []*pb.Block{{}}
+		for i, reward := range rewards {
+			blocks = append(blocks, &pb.Block{
+				Prevhash:  []byte{1},
+				Target:    testTarget,
+				Timestamp: testTimestamp(uint64(i + 1)),
+				Txs: []*pb.Tx{
+					{Recipient: miner, Value: reward, Height: uint64(i + 1)},
+				},
+			})
+		}
+
+		return &chain.Chain{
+			Pbc: &pb.Chain{
+				Blocks: blocks,
+			},
+		}
+	}
+
+	cases := []struct {
+		name  string
+		chain *chain.Chain
+		want  bool
+	}{
+		{
+			name:  "Rewards following the schedule are valid",
+			chain: chainOf(100, 50, 25),
+			want:  true,
+		},
+		{
+			name:  "A reward of less than the schedule is valid",
+			chain: chainOf(100, 10),
+			want:  true,
+		},
+		{
+			name:  "A reward ignoring the halving is not valid",
+			chain: chainOf(100, 100),
+			want:  false,
+		},
+		{
+			name:  "A reward beyond the supply cap is not valid",
+			chain: chainOf(100, 50, 25, 1),
+			want:  false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			n := node{
+				hasher: mockHasher,
+				params: schedule,
+				clock:  nettime.New(),
+			}
+
+			err := n.Validate(currentForm(c.chain))
+
+			if got := err == nil; got != c.want {
+				t.Errorf("want %v, got %v", c.want, err)
+			}
+		})
+	}
+}
+
+func TestValidateLedger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHasher := mocks.NewMockHasher(ctrl)
+	mockHasher.EXPECT().Hash(gomock.Any()).Return([]byte{1}).AnyTimes()
+
+	gob, err := transactions.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gobAddr := transactions.Address(gob, address.Mainnet)
+
+	sendTx := func(value uint64, recipient string, nonce uint64) *pb.Tx {
+		tx := &pb.Tx{
+			Timestamp: &timestamp.Timestamp{
+				Seconds: 646459200,
+			},
+			Value:     value,
+			Recipient: recipient,
+			Nonce:     nonce,
+		}
+		transactions.Sign(tx, gob, address.Mainnet)
+		return tx
+	}
+
+	// Gob is paid the reward of the first block, spendable from the second
+	chainOf := func(blocksTxs ...[]*pb.Tx) *chain.Chain {
+		blocks := []*pb.Block{
+			{},
+			{
+				Prevhash:  []byte{1},
+				Target:    testTarget,
+				Timestamp: testTimestamp(1),
+				Txs:       []*pb.Tx{{Recipient: gobAddr, Value: blockSubsidy, Height: 1}},
+			},
+		}
+		for i, txs := range blocksTxs {
+			blocks = append(blocks, &pb.Block{
+				Prevhash:  []byte{1},
+				Target:    testTarget,
+				Timestamp: testTimestamp(uint64(i + 2)),
+				Txs:       append([]*pb.Tx{testReward(uint64(i + 2))}, txs...),
+			})
+		}
+
+		return &chain.Chain{
+			Pbc: &pb.Chain{
+				Blocks: blocks,
+			},
+		}
+	}
+
+	ledgerParams := *params.Mainnet
+	ledgerParams.CoinbaseMaturity = 1
+
+	unknownVersion := chainOf()
+	unknownVersion.Pbc.Blocks[1].Version = canonical.HeaderVersion + 1
+
+	committed := chainOf([]*pb.Tx{sendTx(1, testMiner, 0)})
+	for _, block := range committed.Pbc.Blocks[1:] {
+		block.Version = canonical.HeaderVersion
+		block.MerkleRoot = merkle.Root(block.GetTxs())
+	}
+
+	misrooted := chainOf([]*pb.Tx{sendTx(1, testMiner, 0)})
+	for _, block := range misrooted.Pbc.Blocks[1:] {
+		block.Version = canonical.HeaderVersion
+		block.MerkleRoot = merkle.Root(block.GetTxs()[:1])
+	}
+
+	cases := []struct {
+		name   string
+		chain  *chain.Chain
+		height uint64
+		tx     int
+		err    error
+	}{
+		{
+			name: "Spending mature credit is valid",
+			chain: chainOf(
+				[]*pb.Tx{sendTx(blockSubsidy/2, testMiner, 0)},
+				[]*pb.Tx{sendTx(blockSubsidy/2, testMiner, 1)},
+			),
+			err: nil,
+		},
+		{
+			name: "Spending more than is owned is not valid",
+			chain: chainOf(
+				[]*pb.Tx{sendTx(blockSubsidy/2, testMiner, 0)},
+				[]*pb.Tx{sendTx(blockSubsidy/2+1, testMiner, 1)},
+			),
+			height: 3,
+			tx:     1,
+			err:    accounts.ErrInsufficientCredit,
+		},
+		{
+			name: "Spending credit across txs of a block is not valid once spent",
+			chain: chainOf(
+				[]*pb.Tx{sendTx(blockSubsidy/2, testMiner, 0), sendTx(blockSubsidy/2+1, testMiner, 1)},
+			),
+			height: 2,
+			tx:     2,
+			err:    accounts.ErrInsufficientCredit,
+		},
+		{
+			name: "Paying no value is not valid",
+			chain: chainOf(
+				[]*pb.Tx{sendTx(0, testMiner, 0)},
+			),
+			height: 2,
+			tx:     1,
+			err:    accounts.ErrZeroValue,
+		},
+		{
+			name: "Paying nobody is not valid",
+			chain: chainOf(
+				[]*pb.Tx{sendTx(1, testMiner, 0), sendTx(1, "", 1)},
+			),
+			height: 2,
+			tx:     2,
+		},
+		{
+			name:  "Blocks committing to their txs by merkle root are valid",
+			chain: committed,
+			err:   nil,
+		},
+		{
+			name:   "A block whose merkle root does not match its txs is not valid",
+			chain:  misrooted,
+			height: 2,
+			tx:     -1,
+		},
+		{
+			name:   "A block of an unknown encoding version is not valid",
+			chain:  unknownVersion,
+			height: 1,
+			tx:     -1,
+			err:    canonical.ErrUnknownVersion,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			n := node{
+				hasher: mockHasher,
+				params: &ledgerParams,
+				clock:  nettime.New(),
+			}
+
+			err := n.Validate(currentForm(c.chain))
+
+			if c.height == 0 {
+				if err != nil {
+					t.Errorf("expected chain to be valid, got %v", err)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected a validation error, got %v", err)
+			}
+
+			if verr.Height != c.height || verr.Tx != c.tx {
+				t.Errorf("expected block %d tx %d to fail, got block %d tx %d", c.height, c.tx, verr.Height, verr.Tx)
+			}
+
+			if c.err != nil && !errors.Is(err, c.err) {
+				t.Errorf("expected %v, got %v", c.err, err)
+			}
+		})
+	}
+}
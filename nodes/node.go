@@ -10,45 +10,57 @@ import (
 
 	"github.com/asgaines/blockchain/chain"
 	"github.com/asgaines/blockchain/dmaps"
+	"github.com/asgaines/blockchain/mempool"
 	"github.com/asgaines/blockchain/mining"
+	"github.com/asgaines/blockchain/nettime"
+	"github.com/asgaines/blockchain/orphans"
+	"github.com/asgaines/blockchain/params"
 	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/asgaines/blockchain/txindex"
+	"github.com/asgaines/blockchain/utxo"
+	"github.com/asgaines/blockchain/wallet"
 )
 
-// InitialExpectedHashrate is the seed of how many hashes are possible per second.
-// The variable set by it is overridden by real data once it comes through.
-//
-// Setting it too high could lead to the genesis block solve taking a long time
-// before the difficulty is adjusted.
-const InitialExpectedHashrate = float64(50) // ultra: 700_000)
-
 // Node represents a blockchain node; a peer within the network.
 // It preserves a copy of the blockchain, competes for new block additions by mining,
 // and verifies work of peer nodes.
 type Node interface {
 	Run(ctx context.Context)
 	Ready() chan struct{}
+	// Reorgs delivers an event for each switch of the node to a branch
+	// forking below the tip of its chain
+	Reorgs() <-chan ReorgEvent
 
 	pb.NodeServer
 }
 
 // NewNode instantiates a Node; a blockchain client/peer for mining
 // and propagating new blocks/transactions
-func NewNode(miners []mining.Miner, pubkey string, poolID int, minPeers int, maxPeers int, targetDurPerBlock time.Duration, recalcPeriod int, returnAddr string, seedAddrs []string, speed mining.HashSpeed, filesPrefix string, hasher chain.Hasher) Node {
+func NewNode(miners []mining.Miner, txpool mempool.Mempool, pubkey string, netParams *params.Params, rewardAccount *wallet.Account, poolID int, minPeers int, maxPeers int, returnAddr string, seedAddrs []string, speed mining.HashSpeed, filesPrefix string, hasher chain.Hasher, syncMode SyncMode) Node {
 	n := node{
 		miners:            miners,
 		pubkey:            pubkey,
+		params:            netParams,
+		utxos:             utxo.New(netParams.CoinbaseMaturity),
+		txindex:           txindex.New(txindex.DefaultMaxSeen),
+		orphans:           orphans.New(orphans.DefaultConfig),
+		clock:             nettime.New(),
+		rewardAccount:     rewardAccount,
 		poolID:            poolID,
-		txpool:            make([]*pb.Tx, 0),
+		txpool:            txpool,
 		peers:             make(map[NodeID]Peer),
 		knownAddrs:        dmaps.New(),
 		minPeers:          minPeers,
 		maxPeers:          maxPeers,
-		targetDurPerBlock: targetDurPerBlock,
-		recalcPeriod:      recalcPeriod,
+		targetDurPerBlock: netParams.TargetDurPerBlock,
+		recalcPeriod:      netParams.RecalcPeriod,
+		genesisDifficulty: netParams.GenesisDifficulty,
 		returnAddr:        returnAddr,
 		filesPrefix:       filesPrefix,
 		hasher:            hasher,
 		seedAddrs:         seedAddrs,
+		syncMode:          syncMode,
+		reorgs:            make(chan ReorgEvent, ReorgEventsBuffer),
 		ready:             make(chan struct{}),
 	}
 
@@ -71,16 +83,51 @@ func NewNode(miners []mining.Miner, pubkey string, poolID int, minPeers int, max
 }
 
 type node struct {
-	// pubkey is the public key for the node's miner.
+	// pubkey is the address of the public key for the node's miner.
 	// The rewards for mining a block by this node will be attributed to this
-	// public key. The miner can run multiple nodes with the same pubkey by
+	// address. The miner can run multiple nodes with the same pubkey by
 	// modifying the poolID
 	pubkey string
+	// params are the consensus rules of the network the node participates in.
+	// Only addresses belonging to it are accepted in transactions
+	params *params.Params
+	// utxos indexes the unspent outputs of the chain, on networks with a UTXO
+	// ledger. undos holds what applying each block of the chain changed in it
+	utxos utxo.Set
+	undos []*utxo.Undo
+	// txindex locates the txs of the chain, and remembers those seen pending
+	txindex txindex.Index
+	// balances holds the credit and next nonce of every address of the chain
+	balances chain.Balances
+	// tree holds the blocks of every valid chain known, chain being the branch
+	// of it with the most work
+	tree   chain.Tree
+	reorgs chan ReorgEvent
+	// orphans holds the blocks announced before their parent
+	orphans orphans.Pool
+	// clock tells the network-adjusted time, against which the timestamps of
+	// blocks are checked
+	clock nettime.Clock
+	// mu guards the chain and the state kept in line with it: the tree,
+	// balances, UTXO set, txindex, difficulty and the txpool as reconciled
+	// with them. Blocks, chains and txs are adopted holding it, and the state
+	// read holding it for reading. Nothing is sent to peers while it is held,
+	// as they may be sending to the node in turn.
+	mu sync.RWMutex
+	// peersMu guards peers, which are added and removed while others range
+	// over them
+	peersMu sync.RWMutex
+	// legacyHeight is the height of the last block of the stored chain of
+	// legacy form. No block above it may be of legacy form.
+	legacyHeight uint64
+	// rewardAccount, if set, is the HD wallet account from which a fresh
+	// address is derived to receive the reward of each block
+	rewardAccount *wallet.Account
 	// poolID allows a single pubkey to be used across multiple nodes. Each
 	// node within the single miner's pool should have a unique ID.
 	poolID            int
 	miners            []mining.Miner
-	txpool            []*pb.Tx
+	txpool            mempool.Mempool
 	peers             map[NodeID]Peer
 	knownAddrs        dmaps.Dmap
 	minPeers          int
@@ -93,8 +140,12 @@ type node struct {
 	statsF            *os.File
 	filesPrefix       string
 	difficulty        float64
+	// genesisDifficulty is the difficulty required of the blocks after the
+	// genesis block, until the first recalculation, as set by the params
+	genesisDifficulty float64
 	hasher            chain.Hasher
 	seedAddrs         []string
+	syncMode          SyncMode
 	ready             chan struct{}
 }
 
@@ -105,9 +156,16 @@ type nodeID struct {
 
 func (n *node) Run(ctx context.Context) {
 	defer func() {
+		n.mu.RLock()
+		defer n.mu.RUnlock()
+
 		if err := n.chain.Store(n.filesPrefix); err != nil {
 			log.Println(err)
 		}
+
+		if err := n.balances.Store(n.filesPrefix); err != nil {
+			log.Println(err)
+		}
 	}()
 	defer n.close()
 
@@ -115,19 +173,20 @@ func (n *node) Run(ctx context.Context) {
 	n.discoverPeers(ctx)
 
 	log.Println("Fetching initial state from peers...")
-	c, diff, err := n.getInitState(ctx)
+	c, err := n.getInitState(ctx)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	if !n.IsValid(c) {
-		c = chain.NewChain(n.hasher)
-	}
+	c = n.initLedger(c, chain.InitBalances(c, n.filesPrefix))
 
-	n.difficulty = diff
 	n.chain = c
+	n.difficulty = n.requiredDifficulty(c, uint64(c.Length()))
 
-	n.resetTxpool()
+	n.tree = chain.NewTree(c)
+	n.syncUTXOs(nil, c)
+	n.syncTxIndex(nil, c)
+	n.updateMinerTxs()
 
 	log.Println("Initializing mining...")
 	prevHash := n.hasher.Hash(n.chain.LastLink())
@@ -144,6 +203,18 @@ func (n *node) Run(ctx context.Context) {
 		go n.periodicDiscoverPeers(ctx)
 	}()
 
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		n.periodicExpireTxs(ctx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		n.periodicExpireOrphans(ctx)
+	}()
+
 	log.Println("Mining started...")
 	wg.Add(1)
 	go func() {
@@ -162,7 +233,7 @@ func (n *node) Ready() chan struct{} {
 }
 
 func (n *node) close() {
-	for _, peer := range n.peers {
+	for _, peer := range n.getPeers() {
 		if err := peer.Close(); err != nil {
 			log.Println(err)
 		}
@@ -177,19 +248,21 @@ func (n *node) close() {
 	}
 }
 
-func (n node) propagateChain(except map[NodeID]bool) {
-	for nodeID, p := range n.peers {
+func (n *node) propagateChain(except map[NodeID]bool) {
+	c := n.getChain()
+
+	for nodeID, p := range n.getPeers() {
 		if _, ok := except[nodeID]; !ok {
-			if err := p.ShareChain(n.chain, n.getID()); err != nil {
+			if err := p.ShareChain(c, n.getID()); err != nil {
 				log.Printf("Removing peer: %s", nodeID.Pubkey)
-				delete(n.peers, nodeID)
+				n.removePeer(nodeID)
 			}
 		}
 	}
 }
 
-func (n node) propagateTx(tx *pb.Tx, except NodeID) {
-	for nodeID, p := range n.peers {
+func (n *node) propagateTx(tx *pb.Tx, except NodeID) {
+	for nodeID, p := range n.getPeers() {
 		if nodeID != except {
 			if err := p.ShareTx(tx, n.getID()); err != nil {
 				// log.Println(err)
@@ -198,17 +271,90 @@ func (n node) propagateTx(tx *pb.Tx, except NodeID) {
 	}
 }
 
-func (n *node) getCreditFor(pubkey string) float64 {
-	creditInChain := n.chain.GetCreditFor(pubkey)
+// getCreditFor returns the credit the address can spend in the next block:
+// what it holds in the chain, less any rewards not yet mature and the debits
+// of its txs pending in the txpool
+func (n *node) getCreditFor(pubkey string) uint64 {
+	if n.params.Ledger == params.LedgerUTXO {
+		unspent, _ := n.getUnspentFor(pubkey)
+
+		var credit uint64
+		for _, u := range unspent {
+			credit += u.GetOutput().GetValue()
+		}
+
+		return credit
+	}
+
+	creditInChain := n.balances.Credit(pubkey)
+
+	debits := n.getImmatureFor(pubkey)
+	for _, tx := range n.txpool.BySender(pubkey) {
+		debits += tx.GetValue() + tx.GetFee()
+	}
+
+	if debits > creditInChain {
+		return 0
+	}
+
+	return creditInChain - debits
+}
+
+// getImmatureFor returns the credit paid to the address by block solve rewards
+// which can't yet be spent in the next block
+func (n *node) getImmatureFor(addr string) uint64 {
+	if n.params.Ledger == params.LedgerUTXO {
+		_, immature := n.getUnspentFor(addr)
 
-	debitsInTxpool := float64(0)
-	for _, tx := range n.txpool {
-		if tx.GetSender() == pubkey {
-			debitsInTxpool += tx.GetValue()
+		var credit uint64
+		for _, u := range immature {
+			credit += u.GetOutput().GetValue()
 		}
+
+		return credit
+	}
+
+	// Rewards paid after since are too recent to be spent in the next block
+	next := uint64(n.chain.Length())
+	since := uint64(0)
+	if next >= n.params.CoinbaseMaturity {
+		since = next - n.params.CoinbaseMaturity + 1
+	}
+
+	return n.chain.GetImmatureFor(addr, since)
+}
+
+// getNextNonce returns the nonce expected of the next tx sent by the address,
+// following on from its txs in the chain and those pending in the txpool
+func (n *node) getNextNonce(addr string) uint64 {
+	pending := make(map[uint64]bool)
+	for _, tx := range n.txpool.BySender(addr) {
+		pending[tx.GetNonce()] = true
+	}
+
+	next := n.balances.Nonce(addr)
+	for pending[next] {
+		next++
+	}
+
+	return next
+}
+
+// getRewardAddr returns the address to pay the reward for mining the block at
+// height to. With an HD wallet account, every block is paid to the address
+// derived at the block's height; otherwise the node's single address is used.
+func (n *node) getRewardAddr(height int) string {
+	if n.rewardAccount == nil {
+		return n.pubkey
+	}
+
+	addr, err := n.rewardAccount.Address(uint32(height))
+	if err != nil {
+		log.Printf("could not derive reward address, falling back to %s: %s", n.pubkey, err)
+		return n.pubkey
 	}
 
-	return creditInChain - debitsInTxpool
+	return addr
 }
 
 func (n *node) getStorageFnameProto() string {
@@ -220,7 +366,7 @@ func (n *node) getStorageFnameJSON() string {
 }
 
 type SubmitReport struct {
-	chain chain.Chain
+	chain *chain.Chain
 }
 
 func (n *node) mergeSubmits(chans ...<-chan SubmitReport) <-chan SubmitReport {
@@ -287,6 +433,43 @@ func (n *node) getSeedAddrs() []string {
 	return addrs
 }
 
+// getChain returns the chain of the node, as adopted so far
+func (n *node) getChain() *chain.Chain {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.chain
+}
+
+// getPeers returns a copy of the peers, to be ranged over without holding
+// peersMu while they are sent to
+func (n *node) getPeers() map[NodeID]Peer {
+	n.peersMu.RLock()
+	defer n.peersMu.RUnlock()
+
+	peers := make(map[NodeID]Peer, len(n.peers))
+	for nodeID, p := range n.peers {
+		peers[nodeID] = p
+	}
+
+	return peers
+}
+
+func (n *node) getPeer(nodeID NodeID) (Peer, bool) {
+	n.peersMu.RLock()
+	defer n.peersMu.RUnlock()
+
+	p, ok := n.peers[nodeID]
+	return p, ok
+}
+
+func (n *node) removePeer(nodeID NodeID) {
+	n.peersMu.Lock()
+	defer n.peersMu.Unlock()
+
+	delete(n.peers, nodeID)
+}
+
 func (n *node) getID() NodeID {
 	return NodeID{
 		Pubkey:     n.pubkey,
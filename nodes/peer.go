@@ -12,7 +12,11 @@ import (
 // Peer manages a client connection to a Node running at a different address
 type Peer interface {
 	GetState(nodeID NodeID) (*chain.Chain, float64, error)
+	GetHeaders(from uint64, nodeID NodeID) (*pb.GetHeadersResponse, error)
+	GetBlocks(from uint64, count int, nodeID NodeID) ([]*pb.Block, error)
+	GetBlock(hash []byte, nodeID NodeID) (*pb.Block, error)
 	ShareChain(c *chain.Chain, nodeID NodeID) error
+	ShareBlock(block *pb.Block, nodeID NodeID) error
 	ShareTx(tx *pb.Tx, nodeID NodeID) error
 	Close() error
 }
@@ -45,6 +49,32 @@ func (p *peer) GetState(nodeID NodeID) (*chain.Chain, float64, error) {
 	}, resp.GetDifficulty(), err
 }
 
+func (p *peer) GetHeaders(from uint64, nodeID NodeID) (*pb.GetHeadersResponse, error) {
+	return p.client.GetHeaders(p.ctx, &pb.GetHeadersRequest{
+		NodeID: nodeID.ToProto(),
+		From:   from,
+	})
+}
+
+func (p *peer) GetBlocks(from uint64, count int, nodeID NodeID) ([]*pb.Block, error) {
+	resp, err := p.client.GetBlocks(p.ctx, &pb.GetBlocksRequest{
+		NodeID: nodeID.ToProto(),
+		From:   from,
+		Count:  uint32(count),
+	})
+
+	return resp.GetBlocks(), err
+}
+
+func (p *peer) GetBlock(hash []byte, nodeID NodeID) (*pb.Block, error) {
+	resp, err := p.client.GetBlock(p.ctx, &pb.GetBlockRequest{
+		NodeID: nodeID.ToProto(),
+		Hash:   hash,
+	})
+
+	return resp.GetBlock(), err
+}
+
 func (p *peer) ShareChain(c *chain.Chain, nodeID NodeID) error {
 	resp, err := p.client.ShareChain(p.ctx, &pb.ShareChainRequest{
 		Chain:  c.ToProto(),
@@ -63,6 +93,15 @@ func (p *peer) ShareChain(c *chain.Chain, nodeID NodeID) error {
 	return nil
 }
 
+func (p *peer) ShareBlock(block *pb.Block, nodeID NodeID) error {
+	_, err := p.client.ShareBlock(p.ctx, &pb.ShareBlockRequest{
+		Block:  block,
+		NodeID: nodeID.ToProto(),
+	})
+
+	return err
+}
+
 func (p *peer) ShareTx(tx *pb.Tx, nodeID NodeID) error {
 	resp, err := p.client.ShareTx(p.ctx, &pb.ShareTxRequest{
 		Tx:     tx,
@@ -0,0 +1,178 @@
+package nodes
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/asgaines/blockchain/chain"
+	"github.com/asgaines/blockchain/orphans"
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+)
+
+// acceptBlock handles a block announced by the peer of the nodeID. A block
+// whose parent is unknown is held in the orphan pool, and its parent requested
+// from the peer. Otherwise it is connected on top of its parent, followed by
+// the orphans waiting on it. It reports whether the node switched to a chain
+// ending with the block or one of those orphans, with why not otherwise.
+func (n *node) acceptBlock(block *pb.Block, from NodeID) (bool, string) {
+	n.mu.Lock()
+	switched, reason := n.adoptBlock(block, from)
+	n.mu.Unlock()
+
+	for _, b := range switched {
+		n.propagateBlock(b, map[NodeID]bool{from: true})
+	}
+
+	return len(switched) > 0, reason
+}
+
+// adoptBlock is acceptBlock short of relaying, with n.mu held. It returns the
+// blocks of those the node switched to, to be relayed once n.mu is released.
+func (n *node) adoptBlock(block *pb.Block, from NodeID) ([]*pb.Block, string) {
+	hash := n.hasher.Hash((*chain.Block)(block))
+
+	if n.tree.Contains(hash) {
+		return nil, "block already known"
+	}
+
+	if n.orphans.Has(hash) {
+		return nil, "block already held until its parent arrives"
+	}
+
+	if !n.tree.Contains(block.GetPrevhash()) {
+		// Too far behind the peer to catch up a block at a time
+		if n.orphans.Full() {
+			n.syncFromPeer(from)
+			return nil, "parent unknown, fetching the chain of the peer"
+		}
+
+		n.orphans.Add(&orphans.Orphan{
+			Block: block,
+			Hash:  hash,
+			From:  from.Pubkey,
+			Added: time.Now(),
+		})
+		n.requestParent(block.GetPrevhash(), from)
+
+		return nil, "block held until its parent arrives"
+	}
+
+	switched := n.connectBlock(block, hash, from)
+	if len(switched) == 0 {
+		return nil, "block not valid, or kept on a side branch"
+	}
+
+	return switched, ""
+}
+
+// connectBlock connects the block, whose parent is in the tree, then the
+// orphans waiting on it and on each of them in turn. It returns those the node
+// switched to, in order.
+func (n *node) connectBlock(block *pb.Block, hash []byte, from NodeID) []*pb.Block {
+	var switched []*pb.Block
+	queue := []*orphans.Orphan{{Block: block, Hash: hash, From: from.Pubkey}}
+
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+
+		parent, ok := n.tree.ChainTo(next.Block.GetPrevhash())
+		if !ok {
+			continue
+		}
+
+		if n.setChain(parent.WithBlock((*chain.Block)(next.Block)), false) {
+			switched = append(switched, next.Block)
+		}
+
+		children := n.orphans.TakeChildren(next.Hash)
+
+		// The descendants of an invalid block are no more valid
+		if !n.tree.Contains(next.Hash) {
+			if len(children) > 0 {
+				log.Printf("dropped %d orphans of invalid block %x", len(children), next.Hash)
+			}
+			continue
+		}
+
+		queue = append(queue, children...)
+	}
+
+	return switched
+}
+
+// requestParent fetches the block with the hash from the peer of the nodeID,
+// which announced a child of it
+func (n *node) requestParent(hash []byte, from NodeID) {
+	p, ok := n.getPeer(from)
+	if !ok {
+		return
+	}
+
+	go func() {
+		block, err := p.GetBlock(hash, n.getID())
+		if err != nil {
+			log.Printf("could not fetch parent block %x from peer: %s", hash, err)
+			return
+		}
+
+		n.acceptBlock(block, from)
+	}()
+}
+
+// syncFromPeer fetches the whole chain of the peer of the nodeID, switching to
+// it should it have more work
+func (n *node) syncFromPeer(from NodeID) {
+	p, ok := n.getPeer(from)
+	if !ok {
+		return
+	}
+
+	go func() {
+		c, _, err := p.GetState(n.getID())
+		if err != nil {
+			log.Printf("could not fetch chain of peer: %s", err)
+			return
+		}
+
+		c.Hasher = n.hasher
+
+		n.mu.Lock()
+		switched := n.setChain(c, false)
+		n.mu.Unlock()
+
+		if switched {
+			n.propagateChain(map[NodeID]bool{from: true})
+		}
+	}()
+}
+
+func (n *node) propagateBlock(block *pb.Block, except map[NodeID]bool) {
+	for nodeID, p := range n.getPeers() {
+		if _, ok := except[nodeID]; !ok {
+			if err := p.ShareBlock(block, n.getID()); err != nil {
+				log.Printf("Removing peer: %s", nodeID.Pubkey)
+				n.removePeer(nodeID)
+			}
+		}
+	}
+}
+
+// periodicExpireOrphans drops orphans which have waited for their parent
+// beyond the TTL of the orphan pool
+func (n *node) periodicExpireOrphans(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if expired := n.orphans.Expire(time.Now()); len(expired) > 0 {
+				log.Printf("Expired %d orphan blocks", len(expired))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package nodes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/asgaines/blockchain/chain"
+	"github.com/asgaines/blockchain/mempool"
+	"github.com/asgaines/blockchain/nettime"
+	"github.com/asgaines/blockchain/orphans"
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/asgaines/blockchain/txindex"
+)
+
+// relayNode returns a node on the first blocks of the chain, of the length
+func relayNode(hasher chain.Hasher, c *chain.Chain, length int) *node {
+	base := &chain.Chain{
+		Pbc:    &pb.Chain{Blocks: append([]*pb.Block{}, c.Pbc.Blocks[:length]...)},
+		Hasher: hasher,
+	}
+
+	return &node{
+		hasher:       hasher,
+		chain:        base,
+		tree:         chain.NewTree(base),
+		balances:     balancesOf(base),
+		orphans:      orphans.New(orphans.DefaultConfig),
+		params:       instantParams(),
+		clock:        nettime.New(),
+		txpool:       mempool.New(mempool.DefaultConfig),
+		txindex:      txindex.New(txindex.DefaultMaxSeen),
+		recalcPeriod: 1000,
+	}
+}
+
+func TestAcceptBlockOutOfOrder(t *testing.T) {
+	hasher := chain.NewHasher()
+	long := testChain(hasher, 6)
+	n := relayNode(hasher, long, 3)
+	from := NodeID{Pubkey: "Gob"}
+
+	for _, height := range []int{5, 4} {
+		if accepted, _ := n.acceptBlock(long.Pbc.Blocks[height], from); accepted {
+			t.Errorf("expected block %d, of unknown parent, not to be accepted", height)
+		}
+	}
+
+	if stats := n.orphans.Stats(); stats.Held != 2 {
+		t.Errorf("expected 2 orphans held, got %+v", stats)
+	}
+
+	if accepted, info := n.acceptBlock(long.Pbc.Blocks[3], from); !accepted {
+		t.Fatalf("expected block 3 to be accepted, got %s", info)
+	}
+
+	if n.chain.Length() != long.Length() {
+		t.Errorf("expected the orphans to connect on their parent, got %d blocks", n.chain.Length())
+	}
+
+	if stats := n.orphans.Stats(); stats.Held != 0 || stats.Connected != 2 {
+		t.Errorf("expected both orphans connected, got %+v", stats)
+	}
+
+	if accepted, _ := n.acceptBlock(long.Pbc.Blocks[5], from); accepted {
+		t.Errorf("expected a block already known not to be accepted again")
+	}
+}
+
+func TestAcceptBlockRequestsParent(t *testing.T) {
+	hasher := chain.NewHasher()
+	long := testChain(hasher, 6)
+	n := relayNode(hasher, long, 3)
+
+	announcer := &fakePeer{chain: long}
+	other := &fakePeer{chain: long}
+	n.peers = map[NodeID]Peer{
+		{Pubkey: "Michael"}: announcer,
+		{Pubkey: "Lindsay"}: other,
+	}
+
+	n.acceptBlock(long.Pbc.Blocks[5], NodeID{Pubkey: "Michael"})
+
+	// The missing parents are fetched from the announcer in the background,
+	// and the blocks switched to relayed once the chain is adopted
+	relayed := func() int {
+		other.mu.Lock()
+		defer other.mu.Unlock()
+
+		return len(other.shared)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for (n.getChain().Length() != long.Length() || relayed() < 3) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if got := n.getChain().Length(); got != long.Length() {
+		t.Fatalf("expected missing parents to be fetched, got %d blocks", got)
+	}
+
+	if got := relayed(); got != 3 {
+		t.Errorf("expected the 3 blocks switched to relayed to the other peer, got %d", got)
+	}
+}
@@ -0,0 +1,68 @@
+package nodes
+
+import (
+	"log"
+	"time"
+
+	"github.com/asgaines/blockchain/chain"
+)
+
+// ReorgEventsBuffer is the number of reorg events held for a slow reader of
+// Reorgs. Events beyond it are dropped, though still logged.
+const ReorgEventsBuffer = 16
+
+// ReorgEvent records the switch of the node from its chain to a branch forking
+// below the tip of it
+type ReorgEvent struct {
+	// Fork is the height of the first block not shared by the two branches
+	Fork uint64
+	// Depth is the number of blocks disconnected
+	Depth int
+	// Disconnected holds the hashes of the blocks disconnected, tip first
+	Disconnected [][]byte
+	// Connected holds the hashes of the blocks connected in their place,
+	// lowest first
+	Connected [][]byte
+	Time      time.Time
+}
+
+func (n *node) Reorgs() <-chan ReorgEvent {
+	return n.reorgs
+}
+
+// reorgEvent returns the event of the switch from prev to c, should it
+// disconnect any block of prev
+func reorgEvent(prev *chain.Chain, c *chain.Chain) (ReorgEvent, bool) {
+	fork := forkPoint(prev, c)
+	if fork >= prev.Length() {
+		return ReorgEvent{}, false
+	}
+
+	event := ReorgEvent{
+		Fork:  uint64(fork),
+		Depth: prev.Length() - fork,
+		Time:  time.Now(),
+	}
+
+	for height := prev.Length() - 1; height >= fork; height-- {
+		event.Disconnected = append(event.Disconnected, prev.Hash(uint64(height)))
+	}
+
+	for height := fork; height < c.Length(); height++ {
+		event.Connected = append(event.Connected, c.Hash(uint64(height)))
+	}
+
+	return event, true
+}
+
+// emitReorg logs the event and hands it to the reader of Reorgs, dropping it
+// should the buffer be full
+func (n *node) emitReorg(event ReorgEvent) {
+	log.Printf("reorganized %d blocks from height %d, to %064x", event.Depth, event.Fork, event.Connected[len(event.Connected)-1])
+
+	select {
+	case n.reorgs <- event:
+	default:
+		log.Println("reorg event dropped, as none are being read")
+	}
+}
@@ -0,0 +1,86 @@
+package nodes
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/asgaines/blockchain/chain"
+	"github.com/asgaines/blockchain/mempool"
+	"github.com/asgaines/blockchain/nettime"
+	"github.com/asgaines/blockchain/params"
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/asgaines/blockchain/txindex"
+)
+
+func TestSetChainReorg(t *testing.T) {
+	hasher := chain.NewHasher()
+	target := bytes.Repeat([]byte{0xff}, 32)
+
+	extend := func(c *chain.Chain, nonce uint64) *chain.Chain {
+		return c.WithBlock(chain.NewBlock(hasher, c.Hash(uint64(c.Length()-1)), []*pb.Tx{}, nonce, target, ""))
+	}
+
+	base := testChain(hasher, 3)
+	main := extend(extend(base, 1), 2)
+	side := extend(base, 3)
+
+	n := node{
+		hasher:       hasher,
+		chain:        main,
+		tree:         chain.NewTree(main),
+		balances:     balancesOf(main),
+		params:       params.Mainnet,
+		clock:        nettime.New(),
+		txpool:       mempool.New(mempool.DefaultConfig),
+		txindex:      txindex.New(txindex.DefaultMaxSeen),
+		recalcPeriod: 1000,
+		reorgs:       make(chan ReorgEvent, 1),
+	}
+
+	// A side branch of less work is kept, but not switched to
+	if n.setChain(side, true) {
+		t.Fatalf("expected side branch of less work not to be switched to")
+	}
+
+	if !n.tree.Contains(side.Hash(3)) {
+		t.Errorf("expected side branch to be kept in the tree")
+	}
+
+	if n.setChain(side, true) {
+		t.Errorf("expected side branch already in the tree not to be switched to")
+	}
+
+	// Once extended past the chain of the node, it is switched to
+	side = extend(extend(side, 4), 5)
+	if !n.setChain(side, true) {
+		t.Fatalf("expected side branch of more work to be switched to")
+	}
+
+	if n.chain != side {
+		t.Errorf("expected the side branch to be the chain of the node")
+	}
+
+	if n.balances.Height() != uint64(side.Length()) || !bytes.Equal(n.balances.Tip(), side.Hash(5)) {
+		t.Errorf("expected balances to follow the side branch, got %d blocks", n.balances.Height())
+	}
+
+	select {
+	case event := <-n.reorgs:
+		if event.Fork != 3 || event.Depth != 2 {
+			t.Errorf("expected a reorg of 2 blocks from height 3, got %d from %d", event.Depth, event.Fork)
+		}
+
+		expected := [][]byte{main.Hash(4), main.Hash(3)}
+		for i, hash := range expected {
+			if i >= len(event.Disconnected) || !bytes.Equal(event.Disconnected[i], hash) {
+				t.Errorf("expected block %x disconnected, got %x", hash, event.Disconnected)
+			}
+		}
+
+		if len(event.Connected) != 3 || !bytes.Equal(event.Connected[2], side.Hash(5)) {
+			t.Errorf("expected the 3 blocks of the side branch connected, got %x", event.Connected)
+		}
+	default:
+		t.Errorf("expected a reorg event")
+	}
+}
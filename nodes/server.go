@@ -2,15 +2,18 @@ package nodes
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
 	"net"
 	"strconv"
+	"time"
 
+	"github.com/asgaines/blockchain/address"
+	"github.com/asgaines/blockchain/canonical"
 	"github.com/asgaines/blockchain/chain"
+	"github.com/asgaines/blockchain/mempool"
+	"github.com/asgaines/blockchain/params"
 	pb "github.com/asgaines/blockchain/protogo/blockchain"
 	"github.com/asgaines/blockchain/transactions"
 	"github.com/golang/protobuf/ptypes"
@@ -24,25 +27,35 @@ func (n *node) Discover(ctx context.Context, r *pb.DiscoverRequest) (*pb.Discove
 		Ok:         true, // len(n.peers) < n.maxPeers,
 		NodeID:     n.getID().ToProto(),
 		KnownAddrs: n.getKnownAddrsExcept([]string{r.NodeID.GetReturnAddr()}),
+		Time:       ptypes.TimestampNow(),
 	}, nil
 }
 
 func (n *node) GetState(ctx context.Context, r *pb.GetStateRequest) (*pb.GetStateResponse, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
 	var c *pb.Chain
+	var chainwork []byte
 	if n.chain != nil {
 		c = n.chain.ToProto()
+		chainwork = n.chain.ChainWork().Bytes()
 	}
 
 	return &pb.GetStateResponse{
 		Chain:      c,
 		Difficulty: n.difficulty,
+		Chainwork:  chainwork,
 	}, nil
 }
 
 func (n *node) ShareChain(ctx context.Context, r *pb.ShareChainRequest) (*pb.ShareChainResponse, error) {
+	n.mu.Lock()
 	accepted := n.setChain(&chain.Chain{
-		Pbc: r.Chain,
+		Pbc:    r.Chain,
+		Hasher: n.hasher,
 	}, false)
+	n.mu.Unlock()
 
 	if accepted {
 		n.propagateChain(map[NodeID]bool{
@@ -58,81 +71,325 @@ func (n *node) ShareTx(ctx context.Context, r *pb.ShareTxRequest) (*pb.ShareTxRe
 		return nil, errors.New("missing tx from request")
 	}
 
-	for _, tx := range n.txpool {
-		// Primitive way of determining if tx already in pool
-		if tx.GetTimestamp().GetSeconds() == r.GetTx().GetTimestamp().GetSeconds() &&
-			tx.GetTimestamp().GetNanos() == r.GetTx().GetTimestamp().GetNanos() {
-			return nil, errors.New("tx already in pool")
-		}
+	if r.Tx.GetTimestamp() == nil {
+		return nil, errors.New("`timestamp` must be set before signing")
+	}
+
+	if transactions.IsLegacy(r.Tx) {
+		return nil, errors.New("`legacyValue` and `legacyFee` are only accepted in migrated chains; use `value` and `fee` in base units")
+	}
+
+	if r.Tx.GetVersion() < canonical.TxVersion {
+		return nil, fmt.Errorf("%w; sign the tx with `tx sign`", transactions.ErrUnversionedTx)
 	}
 
 	if r.Tx.GetSender() == "" {
-		if r.Tx.GetSenderKey() == "" {
-			return nil, errors.New("`key` must not be empty")
+		return nil, errors.New("`sender` must not be empty")
+	}
+
+	if n.params.Ledger != params.LedgerUTXO {
+		if r.Tx.GetValue() == 0 {
+			return nil, errors.New("`value` must be greater than 0")
 		}
 
-		sb := sha256.Sum256([]byte(r.Tx.GetSenderKey()))
-		r.Tx.Sender = hex.EncodeToString(sb[:])
+		if r.Tx.GetRecipient() == "" {
+			return nil, errors.New("`recipient` must not be empty")
+		}
 
-		r.Tx.SenderKey = ""
+		if len(r.Tx.GetInputs()) > 0 || len(r.Tx.GetOutputs()) > 0 {
+			return nil, fmt.Errorf("`inputs` and `outputs` are not used on %s, which has an account ledger", n.params.Network.Name)
+		}
 	}
 
-	if r.Tx.GetTimestamp() == nil {
-		r.Tx.Timestamp = ptypes.TimestampNow()
+	if err := transactions.Verify(r.Tx, n.params.Network); err != nil {
+		return nil, fmt.Errorf("invalid tx: %w", err)
 	}
 
-	if r.Tx.GetHash() == nil {
-		transactions.SetHash(r.Tx)
+	resp, err := n.admitTx(r.Tx)
+	if err != nil || !resp.GetAccepted() {
+		return resp, err
 	}
 
-	if r.Tx.GetValue() <= 0 {
-		return nil, errors.New("`value` must be greater than 0")
+	var except NodeID
+	if nodeID := r.GetNodeID(); nodeID != nil {
+		except = NodeIDFrom(nodeID)
 	}
+	n.propagateTx(r.Tx, except)
 
-	if r.Tx.GetSender() == "" {
-		return nil, errors.New("`sender` must not be empty")
+	return resp, nil
+}
+
+// admitTx checks the tx against the chain of the node and adds it to the
+// txpool, holding n.mu so that it is not checked against one chain and pooled
+// against another
+func (n *node) admitTx(tx *pb.Tx) (*pb.ShareTxResponse, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.txpool.Has(tx.GetHash()) {
+		return nil, mempool.ErrExists
 	}
 
-	if r.Tx.GetRecipient() == "" {
-		return nil, errors.New("`recipient` must not be empty")
+	if n.params.Ledger == params.LedgerUTXO {
+		if err := n.utxos.CheckTx(tx, uint64(n.chain.Length())); err != nil {
+			return nil, fmt.Errorf("invalid tx: %w", err)
+		}
+	} else {
+		if err := n.checkNonce(tx); err != nil {
+			return nil, err
+		}
+
+		total, ok := addAmounts(tx.GetValue(), tx.GetFee())
+		if !ok {
+			return nil, errors.New("`value` plus `fee` is too large")
+		}
+
+		credit := n.getCreditFor(tx.GetSender())
+		if total > credit {
+			return &pb.ShareTxResponse{
+				Accepted: false,
+				Info:     "Insufficient credit for `value` plus `fee`",
+			}, nil
+		}
 	}
 
-	credit := n.getCreditFor(r.Tx.GetSender())
-	if r.Tx.GetValue() > credit {
+	if err := n.addTx(tx); err != nil {
 		return &pb.ShareTxResponse{
 			Accepted: false,
-			Info:     fmt.Sprintf("Insufficient credit. Pubkey owns %v", credit),
+			Info:     err.Error(),
 		}, nil
 	}
 
-	n.addTx(r.Tx)
-
-	log.Printf("New tx: %v from pubkey %s to pubkey %s (message: %s)", r.Tx.GetValue(), r.Tx.GetSender(), r.Tx.GetRecipient(), r.Tx.GetMessage())
+	if n.params.Ledger == params.LedgerUTXO {
+		log.Printf("New tx: %d inputs to %d outputs (fee %v) from %s (message: %s)", len(tx.GetInputs()), len(tx.GetOutputs()), tx.GetFee(), tx.GetSender(), tx.GetMessage())
+	} else {
+		log.Printf("New tx: %v (fee %v) from %s to %s (message: %s)", tx.GetValue(), tx.GetFee(), tx.GetSender(), tx.GetRecipient(), tx.GetMessage())
+	}
 
-	var except NodeID
-	if nodeID := r.GetNodeID(); nodeID != nil {
-		except = NodeIDFrom(nodeID)
+	// Amounts are left to the client to format, in coins
+	info := "Tx will be committed in the next block"
+	if !transactions.IsMature(tx, uint64(n.chain.Length()), time.Now()) {
+		info = fmt.Sprintf("Tx held back until %s", describeLock(tx.GetLock()))
 	}
-	n.propagateTx(r.Tx, except)
 
 	return &pb.ShareTxResponse{
 		Accepted: true,
-		Info:     fmt.Sprintf("Sender will have %v left after tx committed in next block", n.getCreditFor(r.Tx.GetSender())),
+		Info:     info,
 	}, nil
 }
 
+// describeLock writes the height or time a tx is locked until
+func describeLock(lock uint64) string {
+	if lock < transactions.LockThreshold {
+		return fmt.Sprintf("block %d", lock)
+	}
+
+	return time.Unix(int64(lock), 0).UTC().Format(time.RFC3339)
+}
+
 func (n *node) GetCredit(ctx context.Context, r *pb.GetCreditRequest) (*pb.GetCreditResponse, error) {
-	if r.GetKey() == "" {
-		return nil, errors.New("missing `key` from request")
+	if r.GetAddress() == "" {
+		return nil, errors.New("missing `address` from request")
+	}
+
+	if _, err := address.Validate(r.GetAddress(), n.params.Network); err != nil {
+		return nil, err
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	resp := &pb.GetCreditResponse{
+		Value:    n.getCreditFor(r.GetAddress()),
+		Immature: n.getImmatureFor(r.GetAddress()),
+	}
+
+	// Txs spending outputs are kept apart by their inputs, not nonces
+	if n.params.Ledger != params.LedgerUTXO {
+		resp.Nonce = n.getNextNonce(r.GetAddress())
+	}
+
+	return resp, nil
+}
+
+func (n *node) GetTx(ctx context.Context, r *pb.GetTxRequest) (*pb.GetTxResponse, error) {
+	if len(r.GetHash()) == 0 {
+		return nil, errors.New("missing `hash` from request")
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	resp, ok := n.lookupTx(r.GetHash())
+	if !ok {
+		return nil, fmt.Errorf("tx %x is neither in the chain nor known to have been pending", r.GetHash())
+	}
+
+	return resp, nil
+}
+
+func (n *node) GetTxProof(ctx context.Context, r *pb.GetTxProofRequest) (*pb.GetTxProofResponse, error) {
+	if len(r.GetHash()) == 0 {
+		return nil, errors.New("missing `hash` from request")
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.proveTx(r.GetHash())
+}
+
+func (n *node) GetHeaders(ctx context.Context, r *pb.GetHeadersRequest) (*pb.GetHeadersResponse, error) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	c := n.chain
+	if c == nil {
+		return &pb.GetHeadersResponse{}, nil
+	}
+
+	headers := make([]*pb.BlockHeader, 0)
+
+	for height := r.GetFrom(); height < uint64(c.Length()) && len(headers) < MaxHeadersPerRequest; height++ {
+		headers = append(headers, c.BlockByIdx(int(height)).Header(height))
+	}
+
+	return &pb.GetHeadersResponse{
+		Headers:    headers,
+		Height:     uint64(c.Length() - 1),
+		Difficulty: n.difficulty,
+	}, nil
+}
+
+func (n *node) GetBlocks(ctx context.Context, r *pb.GetBlocksRequest) (*pb.GetBlocksResponse, error) {
+	c := n.getChain()
+	if c == nil {
+		return &pb.GetBlocksResponse{}, nil
+	}
+
+	count := uint64(r.GetCount())
+	if count > MaxBlocksPerRequest {
+		count = MaxBlocksPerRequest
+	}
+
+	blocks := make([]*pb.Block, 0, count)
+	for height := r.GetFrom(); height < uint64(c.Length()) && height < r.GetFrom()+count; height++ {
+		blocks = append(blocks, c.Pbc.Blocks[height])
+	}
+
+	return &pb.GetBlocksResponse{
+		Blocks: blocks,
+	}, nil
+}
+
+func (n *node) ShareBlock(ctx context.Context, r *pb.ShareBlockRequest) (*pb.ShareBlockResponse, error) {
+	if r.GetBlock() == nil {
+		return nil, errors.New("missing block from request")
+	}
+
+	accepted, info := n.acceptBlock(r.GetBlock(), NodeIDFrom(r.GetNodeID()))
+
+	return &pb.ShareBlockResponse{
+		Accepted: accepted,
+		Info:     info,
+	}, nil
+}
+
+func (n *node) GetBlock(ctx context.Context, r *pb.GetBlockRequest) (*pb.GetBlockResponse, error) {
+	if len(r.GetHash()) == 0 {
+		return nil, errors.New("missing `hash` from request")
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	if n.tree == nil {
+		return nil, fmt.Errorf("block %x is not known", r.GetHash())
+	}
+
+	block, ok := n.tree.Block(r.GetHash())
+	if !ok {
+		return nil, fmt.Errorf("block %x is not known", r.GetHash())
 	}
-	kb := sha256.Sum256([]byte(r.GetKey()))
-	pubkey := hex.EncodeToString(kb[:])
 
-	return &pb.GetCreditResponse{
-		Value: n.getCreditFor(pubkey),
+	return &pb.GetBlockResponse{
+		Block: block.ToProto(),
 	}, nil
 }
 
+func (n *node) GetStats(ctx context.Context, r *pb.GetStatsRequest) (*pb.GetStatsResponse, error) {
+	orphans := n.orphans.Stats()
+
+	resp := &pb.GetStatsResponse{
+		Orphans:          uint64(orphans.Held),
+		OrphansConnected: orphans.Connected,
+		OrphansEvicted:   orphans.Evicted,
+		OrphansExpired:   orphans.Expired,
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	if n.chain != nil {
+		resp.Height = uint64(n.chain.Length() - 1)
+		resp.TreeBlocks = uint64(n.tree.Len())
+	}
+
+	return resp, nil
+}
+
+func (n *node) GetSupply(ctx context.Context, r *pb.GetSupplyRequest) (*pb.GetSupplyResponse, error) {
+	c := n.getChain()
+
+	return &pb.GetSupplyResponse{
+		Height:          uint64(c.Length() - 1),
+		Circulating:     circulatingSupply(c),
+		MaxSupply:       n.params.MaxSupply,
+		NextSubsidy:     n.params.Subsidy(uint64(c.Length())),
+		HalvingInterval: n.params.HalvingInterval,
+	}, nil
+}
+
+func (n *node) GetUnspent(ctx context.Context, r *pb.GetUnspentRequest) (*pb.GetUnspentResponse, error) {
+	if n.params.Ledger != params.LedgerUTXO {
+		return nil, fmt.Errorf("%s has an account ledger, without unspent outputs", n.params.Network.Name)
+	}
+
+	if r.GetAddress() == "" {
+		return nil, errors.New("missing `address` from request")
+	}
+
+	if _, err := address.Validate(r.GetAddress(), n.params.Network); err != nil {
+		return nil, err
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	unspent, _ := n.getUnspentFor(r.GetAddress())
+
+	return &pb.GetUnspentResponse{
+		Unspent: unspent,
+	}, nil
+}
+
+// checkNonce ensures the tx nonce has not been used by its sender in the chain,
+// and is not too far ahead of it. Nonces used by pending txs are refused by the
+// txpool itself.
+func (n *node) checkNonce(tx *pb.Tx) error {
+	next := n.balances.Nonce(tx.GetSender())
+
+	if tx.GetNonce() < next {
+		return fmt.Errorf("nonce %d already used, next expected is %d", tx.GetNonce(), next)
+	}
+
+	if tx.GetNonce() >= next+MaxNonceGap {
+		return fmt.Errorf("nonce %d too far ahead of next expected %d", tx.GetNonce(), next)
+	}
+
+	return nil
+}
+
 // getPeerAddr is currently a remnant of an attempt to discover requesting peer's
 // ip address. Current methods for discovering ip are not reliable within Docker,
 // as the ip is reported to the Docker gateway proxy.
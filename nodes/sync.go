@@ -0,0 +1,346 @@
+package nodes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+
+	"github.com/asgaines/blockchain/canonical"
+	"github.com/asgaines/blockchain/chain"
+	"github.com/asgaines/blockchain/merkle"
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	// MaxHeadersPerRequest is the most headers returned for one request
+	MaxHeadersPerRequest = 2000
+	// MaxBlocksPerRequest is the most blocks returned for one request, and the
+	// number of blocks fetched from a peer at a time by headers-first sync
+	MaxBlocksPerRequest = 100
+)
+
+// SyncMode is how a node fetches the chain of its peers on start
+type SyncMode int
+
+const (
+	// SyncFull fetches the whole chain of every peer, then keeps the valid one
+	// with the most work
+	SyncFull SyncMode = iota
+	// SyncHeaders fetches the header chain of every peer and checks it, then
+	// fetches the blocks of the valid one with the most work in parallel from
+	// all peers
+	SyncHeaders
+)
+
+// ToSyncMode parses the name of a sync mode: full or headers
+func ToSyncMode(s string) (SyncMode, error) {
+	switch s {
+	case "full":
+		return SyncFull, nil
+	case "headers":
+		return SyncHeaders, nil
+	}
+
+	return 0, errors.New("invalid sync mode")
+}
+
+// checkLink ensures the block links to the previous block, by the hash of
+// it, and that its own hash meets its target
+func checkLink(block *chain.Block, blockHash []byte, prevhash []byte) error {
+	// A block of an unknown version has no hash, so could meet any target
+	if block.Version > canonical.HeaderVersion {
+		return fmt.Errorf("block version %d: %w", block.Version, canonical.ErrUnknownVersion)
+	}
+
+	if !bytes.Equal(prevhash, block.Prevhash) {
+		return errors.New("prevhash does not match the hash of the previous block")
+	}
+
+	blockHashBI := new(big.Int).SetBytes(blockHash)
+	targetBI := new(big.Int).SetBytes(block.Target)
+
+	if blockHashBI.Cmp(targetBI) == 1 {
+		return errors.New("hash is above the target")
+	}
+
+	return nil
+}
+
+// ErrLegacyHeader is returned for a block of a version 0 header outside the
+// blocks of the stored chain
+var ErrLegacyHeader = errors.New("version 0 headers are only accepted in stored chains")
+
+// checkVersion ensures the block at the height is of a current header version,
+// unless it is among those of the stored chain at or below legacyHeight. A
+// version 0 header is hashed without its merkle root, so its proof of work
+// would not commit to its txs.
+func checkVersion(block *chain.Block, height uint64, legacyHeight uint64) error {
+	if height > legacyHeight && block.Version < canonical.HeaderVersion {
+		return fmt.Errorf("block version %d: %w", block.Version, ErrLegacyHeader)
+	}
+
+	return nil
+}
+
+// ErrGenesisTxs is returned for a genesis block carrying txs, or committing to
+// any by its merkle root
+var ErrGenesisTxs = errors.New("genesis block has txs")
+
+// checkGenesis ensures the genesis block carries no txs. It is only a root for
+// the chain to link from, and is not replayed against the ledger, so any credit
+// it paid would escape every check.
+func checkGenesis(block *chain.Block) error {
+	if len(block.Txs) > 0 {
+		return ErrGenesisTxs
+	}
+
+	if block.Version >= canonical.HeaderVersion && !bytes.Equal(block.MerkleRoot, merkle.Root(nil)) {
+		return ErrGenesisTxs
+	}
+
+	return nil
+}
+
+// validateHeaders checks the header chain links together from its genesis
+// and that each header declares and meets the target required of it. The
+// first rule broken is returned as a *ValidationError.
+func (n *node) validateHeaders(headers []*pb.BlockHeader) error {
+	if len(headers) == 0 {
+		return errors.New("header chain has no genesis block")
+	}
+
+	// The headers stand in for their blocks in working out the difficulty
+	// required of each
+	blocks := make([]*pb.Block, len(headers))
+	for i, header := range headers {
+		blocks[i] = (*pb.Block)(chain.FromHeader(header))
+	}
+	hc := &chain.Chain{Pbc: &pb.Chain{Blocks: blocks}}
+
+	var prevhash []byte
+	difficulty := n.initialDifficulty()
+
+	for i, header := range headers {
+		height := uint64(i)
+		blockHash := n.hasher.Hash(chain.FromHeader(header))
+
+		if header.GetHeight() != height {
+			return &ValidationError{Height: height, BlockHash: blockHash, Tx: -1, Err: fmt.Errorf("header records height %d", header.GetHeight())}
+		}
+
+		// Any genesis block without txs is accepted, as with Validate
+		if i == 0 {
+			if err := checkGenesis(chain.FromHeader(header)); err != nil {
+				return &ValidationError{Height: height, BlockHash: blockHash, Tx: -1, Err: err}
+			}
+		} else {
+			if err := checkLink(chain.FromHeader(header), blockHash, prevhash); err != nil {
+				return &ValidationError{Height: height, BlockHash: blockHash, Tx: -1, Err: err}
+			}
+
+			if err := checkVersion(chain.FromHeader(header), height, n.legacyHeight); err != nil {
+				return &ValidationError{Height: height, BlockHash: blockHash, Tx: -1, Err: err}
+			}
+
+			difficulty = n.nextDifficulty(hc, height-1, difficulty)
+			if err := checkTarget(chain.FromHeader(header), difficulty); err != nil {
+				return &ValidationError{Height: height, BlockHash: blockHash, Tx: -1, Err: err}
+			}
+		}
+
+		prevhash = blockHash
+	}
+
+	return nil
+}
+
+// headersWork returns the chainwork of the header chain, and the hash of its
+// last header
+func (n *node) headersWork(headers []*pb.BlockHeader) (*big.Int, []byte) {
+	work := new(big.Int)
+	for _, header := range headers {
+		work.Add(work, chain.BlockWork(header.GetTarget()))
+	}
+
+	return work, n.hasher.Hash(chain.FromHeader(headers[len(headers)-1]))
+}
+
+// fetchHeaders fetches the whole header chain of the peer, a request at a time
+func (n *node) fetchHeaders(p Peer) ([]*pb.BlockHeader, error) {
+	headers := make([]*pb.BlockHeader, 0)
+
+	for {
+		resp, err := p.GetHeaders(uint64(len(headers)), n.getID())
+		if err != nil {
+			return nil, err
+		}
+
+		headers = append(headers, resp.GetHeaders()...)
+
+		if len(resp.GetHeaders()) == 0 || uint64(len(headers)) > resp.GetHeight() {
+			return headers, nil
+		}
+	}
+}
+
+// syncHeadersFirst fetches the header chain of every peer and keeps the valid
+// one with the most work. The blocks of it are then fetched in batches, spread
+// across all peers, each block checked against its header. Only the chain of
+// headers is checked here; the txs are left to Validate.
+func (n *node) syncHeadersFirst(ctx context.Context) (*chain.Chain, error) {
+	mainChain := chain.InitChain(n.hasher, n.filesPrefix)
+	n.legacyHeight = mainChain.LegacyHeight
+
+	peers := make([]Peer, 0)
+	for _, p := range n.getPeers() {
+		peers = append(peers, p)
+	}
+
+	var best []*pb.BlockHeader
+	var source Peer
+	bestWork, bestHash := mainChain.ChainWork(), mainChain.Hash(uint64(mainChain.Length()-1))
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+
+	wg.Add(len(peers))
+	for _, p := range peers {
+		go func(p Peer) {
+			defer wg.Done()
+
+			headers, err := n.fetchHeaders(p)
+			if err != nil {
+				log.Println(err)
+				return
+			}
+
+			if err := n.validateHeaders(headers); err != nil {
+				log.Printf("rejected headers of peer: %s", err)
+				return
+			}
+
+			work, hash := n.headersWork(headers)
+
+			mutex.Lock()
+			if chain.MoreWork(work, hash, bestWork, bestHash) {
+				best, source = headers, p
+				bestWork, bestHash = work, hash
+			}
+			mutex.Unlock()
+		}(p)
+	}
+
+	wg.Wait()
+
+	if best == nil {
+		return mainChain, nil
+	}
+
+	log.Printf("Fetching %d blocks from %d peers...", len(best), len(peers))
+
+	blocks, err := n.fetchBlocks(ctx, best, peers, source)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chain.Chain{
+		Pbc: &pb.Chain{
+			Blocks: blocks,
+		},
+		Hasher: n.hasher,
+	}, nil
+}
+
+// fetchBlocks fetches the blocks of the headers, MaxBlocksPerRequest at a
+// time. The batches are fetched in parallel, each from a different peer
+// first, and from the next peer should one fail to serve blocks matching
+// the headers. Blocks from before merkle trees cannot be checked against
+// their header, so are only taken from source, the peer of the headers.
+func (n *node) fetchBlocks(ctx context.Context, headers []*pb.BlockHeader, peers []Peer, source Peer) ([]*pb.Block, error) {
+	blocks := make([]*pb.Block, len(headers))
+	errs := make(chan error, len(headers)/MaxBlocksPerRequest+1)
+
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, len(peers))
+
+	for batch, from := 0, 0; from < len(headers); batch, from = batch+1, from+MaxBlocksPerRequest {
+		to := from + MaxBlocksPerRequest
+		if to > len(headers) {
+			to = len(headers)
+		}
+
+		// Rotate the peers, so each batch is first asked of a different one
+		candidates := append(append([]Peer{}, peers[batch%len(peers):]...), peers[:batch%len(peers)]...)
+		for _, h := range headers[from:to] {
+			if h.GetVersion() < canonical.HeaderVersion {
+				candidates = []Peer{source}
+				break
+			}
+		}
+
+		workers <- struct{}{}
+		wg.Add(1)
+		go func(from, to int, candidates []Peer) {
+			defer wg.Done()
+			defer func() { <-workers }()
+
+			for _, p := range candidates {
+				if ctx.Err() != nil {
+					errs <- ctx.Err()
+					return
+				}
+
+				fetched, err := p.GetBlocks(uint64(from), to-from, n.getID())
+				if err == nil {
+					err = n.matchHeaders(fetched, headers[from:to])
+				}
+
+				if err != nil {
+					log.Printf("could not fetch blocks %d to %d from peer: %s", from, to-1, err)
+					continue
+				}
+
+				copy(blocks[from:to], fetched)
+				return
+			}
+
+			errs <- fmt.Errorf("no peer served blocks %d to %d", from, to-1)
+		}(from, to, candidates)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	if err, ok := <-errs; ok {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+// matchHeaders ensures the blocks are those of the headers, in order, and that
+// each commits to its txs
+func (n *node) matchHeaders(blocks []*pb.Block, headers []*pb.BlockHeader) error {
+	if len(blocks) != len(headers) {
+		return fmt.Errorf("got %d blocks, expected %d", len(blocks), len(headers))
+	}
+
+	for i, block := range blocks {
+		header := headers[i]
+
+		if !proto.Equal((*chain.Block)(block).Header(header.GetHeight()), header) {
+			return fmt.Errorf("block %d does not match its header", header.GetHeight())
+		}
+
+		if block.GetVersion() >= canonical.HeaderVersion && !bytes.Equal(block.GetMerkleRoot(), merkle.Root(block.GetTxs())) {
+			return fmt.Errorf("txs of block %d do not match its merkle root", header.GetHeight())
+		}
+	}
+
+	return nil
+}
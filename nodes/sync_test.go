@@ -0,0 +1,289 @@
+package nodes
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/asgaines/blockchain/canonical"
+	"github.com/asgaines/blockchain/chain"
+	"github.com/asgaines/blockchain/mining"
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/asgaines/blockchain/transactions"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+)
+
+// fakePeer serves its chain, optionally tampering with the txs of the blocks
+// it serves, and records the blocks shared with it
+type fakePeer struct {
+	chain  *chain.Chain
+	tamper bool
+
+	mu      sync.Mutex
+	fetched int
+	shared  []*pb.Block
+}
+
+func (p *fakePeer) GetState(nodeID NodeID) (*chain.Chain, float64, error) {
+	return p.chain, 1, nil
+}
+
+func (p *fakePeer) GetHeaders(from uint64, nodeID NodeID) (*pb.GetHeadersResponse, error) {
+	headers := make([]*pb.BlockHeader, 0)
+	for height := from; height < uint64(p.chain.Length()) && len(headers) < MaxHeadersPerRequest; height++ {
+		headers = append(headers, p.chain.BlockByIdx(int(height)).Header(height))
+	}
+
+	return &pb.GetHeadersResponse{
+		Headers:    headers,
+		Height:     uint64(p.chain.Length() - 1),
+		Difficulty: 1,
+	}, nil
+}
+
+func (p *fakePeer) GetBlocks(from uint64, count int, nodeID NodeID) ([]*pb.Block, error) {
+	p.mu.Lock()
+	p.fetched++
+	p.mu.Unlock()
+
+	blocks := make([]*pb.Block, 0)
+	for height := from; height < uint64(p.chain.Length()) && height < from+uint64(count); height++ {
+		block := proto.Clone(p.chain.Pbc.Blocks[height]).(*pb.Block)
+		if p.tamper {
+			block.Txs = append(block.Txs, &pb.Tx{Recipient: "Gob", Value: 1})
+		}
+		blocks = append(blocks, block)
+	}
+
+	return blocks, nil
+}
+
+func (p *fakePeer) GetBlock(hash []byte, nodeID NodeID) (*pb.Block, error) {
+	block, ok := p.chain.BlockByHash(hash)
+	if !ok {
+		return nil, errors.New("block not known")
+	}
+
+	return block.ToProto(), nil
+}
+
+func (p *fakePeer) ShareChain(c *chain.Chain, nodeID NodeID) error {
+	return nil
+}
+
+func (p *fakePeer) ShareBlock(block *pb.Block, nodeID NodeID) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.shared = append(p.shared, block)
+	return nil
+}
+
+func (p *fakePeer) ShareTx(tx *pb.Tx, nodeID NodeID) error {
+	return nil
+}
+
+func (p *fakePeer) Close() error {
+	return nil
+}
+
+// testChain builds a chain of the number of blocks, each linking to the one
+// before and meeting the highest target
+func testChain(hasher chain.Hasher, length int) *chain.Chain {
+	return minedChain(&node{hasher: hasher}, length, time.Second)
+}
+
+// minedChain builds a chain of the number of blocks, each linking to the one
+// before and mined to the target the node requires of it. The blocks are
+// timestamped blockDur apart, the last of them blockDur ago.
+func minedChain(n *node, length int, blockDur time.Duration) *chain.Chain {
+	start := time.Now().Add(-time.Duration(length) * blockDur)
+
+	genesis := chain.NewBlock(n.hasher, []byte{}, []*pb.Tx{}, 0, []byte{}, "")
+	genesis.Timestamp, _ = ptypes.TimestampProto(start)
+
+	c := &chain.Chain{
+		Pbc:    &pb.Chain{Blocks: []*pb.Block{genesis.ToProto()}},
+		Hasher: n.hasher,
+	}
+
+	for height := 1; height < length; height++ {
+		reward := &pb.Tx{Recipient: testMiner, Height: uint64(height), Version: canonical.TxVersion}
+		transactions.SetHash(reward)
+
+		target := mining.TargetFor(n.requiredDifficulty(c, uint64(height)))
+
+		block := chain.NewBlock(n.hasher, n.hasher.Hash(c.LastLink()), []*pb.Tx{reward}, 0, target, "")
+		block.Timestamp, _ = ptypes.TimestampProto(start.Add(time.Duration(height) * blockDur))
+
+		for new(big.Int).SetBytes(n.hasher.Hash(block)).Cmp(new(big.Int).SetBytes(target)) > 0 {
+			block.Nonce++
+		}
+
+		c = c.WithBlock(block)
+	}
+
+	return c
+}
+
+func TestValidateHeaders(t *testing.T) {
+	hasher := chain.NewHasher()
+	c := testChain(hasher, 4)
+
+	headersOf := func(tamper func(headers []*pb.BlockHeader)) []*pb.BlockHeader {
+		headers := make([]*pb.BlockHeader, 0)
+		for height := 0; height < c.Length(); height++ {
+			headers = append(headers, c.BlockByIdx(height).Header(uint64(height)))
+		}
+
+		tamper(headers)
+		return headers
+	}
+
+	cases := []struct {
+		name    string
+		headers []*pb.BlockHeader
+		height  uint64
+	}{
+		{
+			name:    "A header chain linking from its genesis is valid",
+			headers: headersOf(func(headers []*pb.BlockHeader) {}),
+		},
+		{
+			name: "A header not linking to the one before is not valid",
+			headers: headersOf(func(headers []*pb.BlockHeader) {
+				headers[2].Prevhash = []byte("Gob")
+			}),
+			height: 2,
+		},
+		{
+			name: "A header changed after it was mined breaks the link of the next",
+			headers: headersOf(func(headers []*pb.BlockHeader) {
+				headers[2].MerkleRoot = []byte("Gob")
+			}),
+			height: 3,
+		},
+		{
+			name: "A header above its target is not valid",
+			headers: headersOf(func(headers []*pb.BlockHeader) {
+				headers[3].Target = []byte{1}
+			}),
+			height: 3,
+		},
+		{
+			name: "A header recording the wrong height is not valid",
+			headers: headersOf(func(headers []*pb.BlockHeader) {
+				headers[1].Height = 2
+			}),
+			height: 1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			n := node{
+				hasher: hasher,
+			}
+
+			err := n.validateHeaders(c.headers)
+
+			if c.height == 0 {
+				if err != nil {
+					t.Errorf("expected header chain to be valid, got %v", err)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) || verr.Height != c.height {
+				t.Errorf("expected header %d not to be valid, got %v", c.height, err)
+			}
+		})
+	}
+}
+
+func TestSyncHeadersFirst(t *testing.T) {
+	hasher := chain.NewHasher()
+
+	// A batch of blocks for each peer, so every peer is asked first for one
+	long := testChain(hasher, 4*MaxBlocksPerRequest)
+	short := testChain(hasher, 10)
+
+	honest := []*fakePeer{{chain: long}, {chain: long}}
+	tampering := &fakePeer{chain: long, tamper: true}
+
+	n := node{
+		hasher: hasher,
+		peers: map[NodeID]Peer{
+			{Pubkey: "Lindsay"}: honest[0],
+			{Pubkey: "Tobias"}:  honest[1],
+			{Pubkey: "Maeby"}:   tampering,
+			{Pubkey: "Buster"}:  &fakePeer{chain: short},
+		},
+		filesPrefix: "test",
+	}
+
+	got, err := n.syncHeadersFirst(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !proto.Equal(got.ToProto(), long.ToProto()) {
+		t.Errorf("expected the longest chain, got %d blocks", got.Length())
+	}
+
+	if honest[0].fetched == 0 || honest[1].fetched == 0 {
+		t.Errorf("expected blocks to be fetched from every honest peer, got %d and %d requests", honest[0].fetched, honest[1].fetched)
+	}
+}
+
+func TestSyncHeadersFirstNoHonestPeer(t *testing.T) {
+	hasher := chain.NewHasher()
+	long := testChain(hasher, 10)
+
+	n := node{
+		hasher: hasher,
+		peers: map[NodeID]Peer{
+			{Pubkey: "Maeby"}: &fakePeer{chain: long, tamper: true},
+		},
+		filesPrefix: "test",
+	}
+
+	if _, err := n.syncHeadersFirst(context.Background()); err == nil {
+		t.Errorf("expected sync to fail when no peer serves blocks matching the headers")
+	}
+}
+
+func TestSyncHeadersFirstMostWork(t *testing.T) {
+	hasher := chain.NewHasher()
+
+	n := node{
+		hasher:            hasher,
+		targetDurPerBlock: time.Minute,
+		recalcPeriod:      2,
+		filesPrefix:       "test",
+	}
+
+	// Mined a second apart, the difficulty of the short chain rises fourfold
+	// every 2 blocks, while that of the long one, an hour apart, stays lowest
+	long := minedChain(&n, 20, time.Hour)
+	short := minedChain(&n, 7, time.Second)
+
+	n.peers = map[NodeID]Peer{
+		{Pubkey: "Gob"}:     &fakePeer{chain: long},
+		{Pubkey: "Michael"}: &fakePeer{chain: short},
+	}
+
+	got, err := n.syncHeadersFirst(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !proto.Equal(got.ToProto(), short.ToProto()) {
+		t.Errorf("expected the chain of the most work, got %d blocks", got.Length())
+	}
+}
@@ -0,0 +1,77 @@
+package nodes
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/asgaines/blockchain/chain"
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/golang/protobuf/ptypes"
+)
+
+// MedianTimeBlocks is the number of blocks preceding a block whose median
+// timestamp the block must be timestamped after
+const MedianTimeBlocks = 11
+
+// ErrTimestampTooEarly is when a block is timestamped at or before the median
+// time past of the blocks preceding it
+var ErrTimestampTooEarly = errors.New("timestamp not after the median time past")
+
+// ErrTimestampTooLate is when a block is timestamped further ahead of the
+// network-adjusted time than the network allows
+var ErrTimestampTooLate = errors.New("timestamp too far in the future")
+
+// blockTime is when the block is timestamped. A block without a valid
+// timestamp, which only a genesis block can be, counts as timestamped at the
+// unix epoch.
+func blockTime(block *pb.Block) time.Time {
+	ts, err := ptypes.Timestamp(block.GetTimestamp())
+	if err != nil {
+		return time.Unix(0, 0)
+	}
+
+	return ts
+}
+
+// medianTimePast is the median timestamp of the MedianTimeBlocks blocks before
+// the height, or of all of them so early in the chain
+func medianTimePast(c *chain.Chain, height uint64) time.Time {
+	from := uint64(0)
+	if height > MedianTimeBlocks {
+		from = height - MedianTimeBlocks
+	}
+
+	times := make([]time.Time, 0, height-from)
+	for _, block := range c.Pbc.Blocks[from:height] {
+		times = append(times, blockTime(block))
+	}
+
+	sort.Slice(times, func(i, j int) bool {
+		return times[i].Before(times[j])
+	})
+
+	return times[len(times)/2]
+}
+
+// checkTimestamp ensures the block at the height is timestamped after the
+// median time past of the blocks before it, so that no miner can walk the
+// chain back in time, and no further than maxDrift ahead of now, so that no
+// miner can rush it forward
+func checkTimestamp(c *chain.Chain, height uint64, now time.Time, maxDrift time.Duration) error {
+	ts, err := ptypes.Timestamp(c.Pbc.Blocks[height].GetTimestamp())
+	if err != nil {
+		return fmt.Errorf("invalid timestamp: %w", err)
+	}
+
+	if mtp := medianTimePast(c, height); !ts.After(mtp) {
+		return fmt.Errorf("%w: %s is not after %s", ErrTimestampTooEarly, ts.Format(time.RFC3339), mtp.Format(time.RFC3339))
+	}
+
+	if limit := now.Add(maxDrift); ts.After(limit) {
+		return fmt.Errorf("%w: %s is after %s", ErrTimestampTooLate, ts.Format(time.RFC3339), limit.Format(time.RFC3339))
+	}
+
+	return nil
+}
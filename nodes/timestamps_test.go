@@ -0,0 +1,185 @@
+package nodes
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/asgaines/blockchain/chain"
+	"github.com/asgaines/blockchain/chain/mocks"
+	"github.com/asgaines/blockchain/nettime"
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+func TestValidateTimestamps(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHasher := mocks.NewMockHasher(ctrl)
+	mockHasher.EXPECT().Hash(gomock.Any()).Return([]byte{1}).AnyTimes()
+
+	// All blocks hash to and link to {1}, and meet the test target, so only
+	// the timestamps decide validity. The first time is of the genesis block
+	chainAt := func(times ...time.Time) *chain.Chain {
+		blocks := []*pb.Block{{Timestamp: &timestamp.Timestamp{Seconds: times[0].Unix()}}}
+		for i, ts := range times[1:] {
+			blocks = append(blocks, &pb.Block{
+				Prevhash:  []byte{1},
+				Target:    testTarget,
+				Timestamp: &timestamp.Timestamp{Seconds: ts.Unix()},
+				Txs:       []*pb.Tx{testReward(uint64(i + 1))},
+			})
+		}
+
+		return &chain.Chain{
+			Pbc: &pb.Chain{
+				Blocks: blocks,
+			},
+		}
+	}
+
+	// honest returns the times of the number of blocks mined a minute apart
+	genesis := time.Date(1990, 6, 27, 0, 0, 0, 0, time.UTC)
+	honest := func(count int) []time.Time {
+		times := make([]time.Time, count)
+		for i := range times {
+			times[i] = genesis.Add(time.Duration(i) * time.Minute)
+		}
+
+		return times
+	}
+
+	now := time.Now().Truncate(time.Second)
+
+	cases := []struct {
+		name        string
+		chain       *chain.Chain
+		peerOffsets []time.Duration
+		err         error
+		height      uint64
+	}{
+		{
+			name:  "Blocks timestamped a minute apart are valid",
+			chain: chainAt(honest(15)...),
+		},
+		{
+			name:  "A block timestamped before its parent, but after the median time past, is valid",
+			chain: chainAt(append(honest(15), genesis.Add(12*time.Minute))...),
+		},
+		{
+			name:   "A block timestamped at the median time past is not valid",
+			chain:  chainAt(append(honest(15), genesis.Add(9*time.Minute))...),
+			err:    ErrTimestampTooEarly,
+			height: 15,
+		},
+		{
+			name:   "A block rewinding the clock, as if slow to solve, to ease the difficulty is not valid",
+			chain:  chainAt(append(honest(15), genesis)...),
+			err:    ErrTimestampTooEarly,
+			height: 15,
+		},
+		{
+			name: "Blocks each timestamped the least allowed cannot walk the median time past back",
+			chain: chainAt(append(honest(11),
+				genesis.Add(5*time.Minute+1*time.Second),
+				genesis.Add(5*time.Minute+2*time.Second),
+				genesis.Add(5*time.Minute+3*time.Second),
+				genesis.Add(5*time.Minute+4*time.Second),
+				genesis.Add(5*time.Minute),
+			)...),
+			err:    ErrTimestampTooEarly,
+			height: 15,
+		},
+		{
+			name:   "A block of the same time as the genesis block is not valid",
+			chain:  chainAt(genesis, genesis),
+			err:    ErrTimestampTooEarly,
+			height: 1,
+		},
+		{
+			name:  "A block timestamped within the drift allowed ahead of now is valid",
+			chain: chainAt(now.Add(-time.Hour), now.Add(time.Hour)),
+		},
+		{
+			name:   "A block timestamped beyond the drift allowed ahead of now is not valid",
+			chain:  chainAt(now.Add(-time.Hour), now.Add(3*time.Hour)),
+			err:    ErrTimestampTooLate,
+			height: 1,
+		},
+		{
+			name:   "A block rushing the clock, as if quick to solve, to ease the difficulty is not valid",
+			chain:  chainAt(now.Add(-time.Hour), now, now.Add(24*time.Hour)),
+			err:    ErrTimestampTooLate,
+			height: 2,
+		},
+		{
+			name:        "Peers whose clocks run ahead extend the drift allowed",
+			chain:       chainAt(now.Add(-time.Hour), now.Add(150*time.Minute)),
+			peerOffsets: []time.Duration{time.Hour, time.Hour, time.Hour, time.Hour, time.Hour},
+		},
+		{
+			name:        "Peers claiming clocks far ahead cannot open the way to a block further in the future",
+			chain:       chainAt(now.Add(-time.Hour), now.Add(5*time.Hour)),
+			peerOffsets: []time.Duration{4 * time.Hour, 4 * time.Hour, 4 * time.Hour, 4 * time.Hour, 4 * time.Hour},
+			err:         ErrTimestampTooLate,
+			height:      1,
+		},
+		{
+			name: "A block without a timestamp is not valid",
+			chain: &chain.Chain{
+				Pbc: &pb.Chain{
+					Blocks: []*pb.Block{
+						{},
+						{
+							Prevhash: []byte{1},
+							Target:   testTarget,
+							Txs:      []*pb.Tx{testReward(1)},
+						},
+					},
+				},
+			},
+			height: 1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			clock := nettime.New()
+			for i, offset := range c.peerOffsets {
+				clock.AddSample(fmt.Sprint(i), offset)
+			}
+
+			n := node{
+				hasher: mockHasher,
+				params: instantParams(),
+				clock:  clock,
+			}
+
+			err := n.Validate(currentForm(c.chain))
+
+			wantValid := c.err == nil && c.height == 0
+			if wantValid {
+				if err != nil {
+					t.Errorf("expected chain to be valid, got %s", err)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected a *ValidationError, got %v", err)
+			}
+
+			if verr.Height != c.height {
+				t.Errorf("expected block %d to fail, got block %d: %s", c.height, verr.Height, err)
+			}
+
+			if c.err != nil && !errors.Is(err, c.err) {
+				t.Errorf("expected %v, got %v", c.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package nodes
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/asgaines/blockchain/canonical"
+	"github.com/asgaines/blockchain/chain"
+	"github.com/asgaines/blockchain/merkle"
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+)
+
+// syncTxIndex brings the tx index in line with a newly adopted chain: the txs
+// of the blocks of prev orphaned by the switch are forgotten, then those of
+// the new blocks indexed
+func (n *node) syncTxIndex(prev *chain.Chain, c *chain.Chain) {
+	fork := 0
+	if prev != nil {
+		fork = forkPoint(prev, c)
+
+		for height := prev.Length() - 1; height >= fork; height-- {
+			n.txindex.RemoveBlock(prev.Pbc.Blocks[height], uint64(height))
+		}
+	}
+
+	for height := fork; height < c.Length(); height++ {
+		n.txindex.AddBlock(c.Pbc.Blocks[height], uint64(height))
+	}
+}
+
+// lookupTx reports what became of the tx with the hash: included in the chain,
+// waiting in the txpool, or dropped from it
+func (n *node) lookupTx(hash []byte) (*pb.GetTxResponse, bool) {
+	c := n.chain
+
+	if height, ok := n.txindex.Height(hash); ok && height < uint64(c.Length()) {
+		block := c.Pbc.Blocks[height]
+
+		for _, tx := range block.GetTxs() {
+			if bytes.Equal(tx.GetHash(), hash) {
+				return &pb.GetTxResponse{
+					Tx:            tx,
+					Status:        pb.GetTxResponse_CONFIRMED,
+					BlockHash:     c.Hash(height),
+					Height:        height,
+					Confirmations: uint64(c.Length()) - height,
+				}, true
+			}
+		}
+	}
+
+	if tx, ok := n.txpool.Get(hash); ok {
+		return &pb.GetTxResponse{
+			Tx:     tx,
+			Status: pb.GetTxResponse_PENDING,
+		}, true
+	}
+
+	if tx, ok := n.txindex.Recall(hash); ok {
+		return &pb.GetTxResponse{
+			Tx:     tx,
+			Status: pb.GetTxResponse_DROPPED,
+		}, true
+	}
+
+	return nil, false
+}
+
+// proveTx returns the proof that the tx with the hash is included in the
+// chain: the header of its block and the merkle path up to its root
+func (n *node) proveTx(hash []byte) (*pb.GetTxProofResponse, error) {
+	c := n.chain
+
+	height, ok := n.txindex.Height(hash)
+	if !ok || height >= uint64(c.Length()) {
+		return nil, fmt.Errorf("tx %x is not in the chain", hash)
+	}
+
+	block := c.Pbc.Blocks[height]
+	if block.GetVersion() < canonical.HeaderVersion {
+		return nil, fmt.Errorf("block %d predates merkle trees, so cannot prove its txs", height)
+	}
+
+	for i, tx := range block.GetTxs() {
+		if !bytes.Equal(tx.GetHash(), hash) {
+			continue
+		}
+
+		path, err := merkle.Proof(block.GetTxs(), i)
+		if err != nil {
+			return nil, err
+		}
+
+		header := *block
+		header.Txs = nil
+
+		return &pb.GetTxProofResponse{
+			Tx:        tx,
+			Header:    &header,
+			BlockHash: c.Hash(height),
+			Height:    height,
+			Index:     uint32(i),
+			Path:      path,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("tx %x is not in the chain", hash)
+}
@@ -0,0 +1,192 @@
+package nodes
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/asgaines/blockchain/canonical"
+	"github.com/asgaines/blockchain/chain"
+	"github.com/asgaines/blockchain/chain/mocks"
+	"github.com/asgaines/blockchain/mempool"
+	"github.com/asgaines/blockchain/merkle"
+	"github.com/asgaines/blockchain/nettime"
+	"github.com/asgaines/blockchain/params"
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/asgaines/blockchain/transactions"
+	"github.com/asgaines/blockchain/txindex"
+	"github.com/golang/mock/gomock"
+)
+
+func TestLookupTx(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHasher := mocks.NewMockHasher(ctrl)
+	mockHasher.EXPECT().Hash(gomock.Any()).Return([]byte{1}).AnyTimes()
+
+	orphaned := &pb.Tx{Sender: "Kitty", Nonce: 0, Value: 1, Version: canonical.TxVersion}
+	confirmed := &pb.Tx{Sender: "Barry", Nonce: 0, Value: 2, Version: canonical.TxVersion}
+	pending := &pb.Tx{Sender: "Barry", Nonce: 1, Value: 3, Version: canonical.TxVersion}
+	for _, tx := range []*pb.Tx{orphaned, confirmed, pending} {
+		transactions.SetHash(tx)
+	}
+
+	genesis := &pb.Block{Nonce: 1}
+
+	prev := &chain.Chain{
+		Pbc: &pb.Chain{
+			Blocks: []*pb.Block{
+				genesis,
+				{Nonce: 2, Txs: []*pb.Tx{orphaned}},
+			},
+		},
+	}
+
+	next := &chain.Chain{
+		Pbc: &pb.Chain{
+			Blocks: []*pb.Block{
+				genesis,
+				{Nonce: 3, Txs: []*pb.Tx{{Recipient: "Barry", Value: 100}, confirmed}},
+				{Nonce: 4},
+			},
+		},
+	}
+
+	lookupParams := *params.Mainnet
+	lookupParams.CoinbaseMaturity = 1
+
+	// The orphaned tx is refused on its return to the txpool, so is dropped
+	n := node{
+		hasher:   mockHasher,
+		params:   &lookupParams,
+		clock:    nettime.New(),
+		chain:    prev,
+		txpool:   mempool.New(mempool.Config{MaxSize: 1}),
+		txindex:  txindex.New(txindex.DefaultMaxSeen),
+		balances: balancesOf(prev),
+	}
+
+	n.syncTxIndex(nil, prev)
+	if err := n.addTx(pending); err != nil {
+		t.Fatal(err)
+	}
+
+	n.chain = next
+	n.syncBalances(prev, next)
+	n.syncTxIndex(prev, next)
+	n.reconcileTxpool(prev, next)
+
+	cases := []struct {
+		name          string
+		hash          []byte
+		found         bool
+		status        pb.GetTxResponse_Status
+		height        uint64
+		confirmations uint64
+	}{
+		{
+			name:          "A tx in the chain is confirmed, counting the blocks from its own",
+			hash:          confirmed.GetHash(),
+			found:         true,
+			status:        pb.GetTxResponse_CONFIRMED,
+			height:        1,
+			confirmations: 2,
+		},
+		{
+			name:   "A tx in the txpool is pending",
+			hash:   pending.GetHash(),
+			found:  true,
+			status: pb.GetTxResponse_PENDING,
+		},
+		{
+			name:   "A tx of an orphaned block which did not make it back into the txpool is dropped",
+			hash:   orphaned.GetHash(),
+			found:  true,
+			status: pb.GetTxResponse_DROPPED,
+		},
+		{
+			name:  "A tx never seen is not found",
+			hash:  []byte("Bob Loblaw"),
+			found: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, ok := n.lookupTx(c.hash)
+
+			if ok != c.found {
+				t.Fatalf("expected found %v, got %v", c.found, ok)
+			}
+
+			if !ok {
+				return
+			}
+
+			if got.GetStatus() != c.status || got.GetHeight() != c.height || got.GetConfirmations() != c.confirmations {
+				t.Errorf("expected %v at height %d with %d confirmations, got %v", c.status, c.height, c.confirmations, got)
+			}
+
+			if string(got.GetTx().GetHash()) != string(c.hash) {
+				t.Errorf("expected tx %x, got %v", c.hash, got.GetTx())
+			}
+		})
+	}
+}
+
+func TestProveTx(t *testing.T) {
+	hasher := chain.NewHasher()
+
+	txs := make([]*pb.Tx, 0)
+	for i := uint64(0); i < 5; i++ {
+		tx := &pb.Tx{Sender: "Lindsay", Nonce: i, Value: 1}
+		transactions.SetHash(tx)
+		txs = append(txs, tx)
+	}
+	unversioned := &pb.Tx{Sender: "Tobias", Value: 1}
+	transactions.SetHash(unversioned)
+
+	c := &chain.Chain{
+		Pbc: &pb.Chain{
+			Blocks: []*pb.Block{
+				{},
+				{Txs: []*pb.Tx{unversioned}},
+				chain.NewBlock(hasher, []byte{1}, txs, 0, []byte{1}, "").ToProto(),
+			},
+		},
+	}
+
+	n := node{
+		hasher:  hasher,
+		chain:   c,
+		txindex: txindex.New(txindex.DefaultMaxSeen),
+	}
+	n.syncTxIndex(nil, c)
+
+	for i, tx := range txs {
+		proof, err := n.proveTx(tx.GetHash())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if proof.GetHeight() != 2 || proof.GetIndex() != uint32(i) || len(proof.GetHeader().GetTxs()) != 0 {
+			t.Errorf("expected tx %d of block 2 proven by its header, got %v", i, proof)
+		}
+
+		if !merkle.Verify(tx.GetHash(), proof.GetPath(), proof.GetHeader().GetMerkleRoot()) {
+			t.Errorf("expected proof of tx %d to verify", i)
+		}
+
+		if !bytes.Equal(hasher.Hash((*chain.Block)(proof.GetHeader())), proof.GetBlockHash()) {
+			t.Errorf("expected header to hash to the block hash")
+		}
+	}
+
+	if _, err := n.proveTx(unversioned.GetHash()); err == nil {
+		t.Errorf("expected tx of a block before merkle trees not to be provable")
+	}
+
+	if _, err := n.proveTx([]byte("Gob")); err == nil {
+		t.Errorf("expected tx not in the chain not to be provable")
+	}
+}
@@ -0,0 +1,188 @@
+package orphans
+
+import (
+	"sync"
+	"time"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+)
+
+// Config sets the limits of a Pool
+type Config struct {
+	// MaxSize is the maximum number of blocks held in the pool. Once reached,
+	// the oldest block is evicted to make room
+	MaxSize int
+	// TTL is how long a block can wait in the pool for its parent before
+	// being expired
+	TTL time.Duration
+}
+
+// DefaultConfig holds the limits used when none are given
+var DefaultConfig = Config{
+	MaxSize: 100,
+	TTL:     20 * time.Minute,
+}
+
+// Orphan is a block whose parent is not yet known
+type Orphan struct {
+	Block *pb.Block
+	Hash  []byte
+	// From is the pubkey of the node which announced the block
+	From  string
+	Added time.Time
+}
+
+// Stats counts the blocks held in a Pool, and what became of those no longer
+// held
+type Stats struct {
+	// Held is the number of blocks in the pool
+	Held int
+	// Connected is the number of blocks taken from the pool once their parent
+	// arrived
+	Connected uint64
+	// Evicted is the number of blocks dropped to make room for another
+	Evicted uint64
+	// Expired is the number of blocks dropped having waited beyond the TTL
+	Expired uint64
+}
+
+// Pool holds the blocks announced before their parent, until the parent
+// arrives. It is safe for concurrent use.
+type Pool interface {
+	// Add holds the orphan until its parent arrives, evicting the oldest
+	// block if the pool is full. It reports whether the block was not
+	// already held.
+	Add(orphan *Orphan) bool
+	// Has reports whether the block with the hash is held
+	Has(hash []byte) bool
+	// Full reports whether adding a block would evict another
+	Full() bool
+	// TakeChildren removes and returns the blocks whose parent has the hash,
+	// in the order they were added
+	TakeChildren(hash []byte) []*Orphan
+	// Expire drops and returns the blocks which have outlived the TTL by now
+	Expire(now time.Time) []*Orphan
+	// Stats returns the counts of the pool
+	Stats() Stats
+}
+
+// New instantiates an empty Pool with the limits of the config
+func New(cfg Config) Pool {
+	p := pool{
+		cfg:      cfg,
+		byHash:   make(map[string]*Orphan),
+		byParent: make(map[string][]*Orphan),
+	}
+
+	return &p
+}
+
+type pool struct {
+	cfg      Config
+	byHash   map[string]*Orphan
+	byParent map[string][]*Orphan
+	// order holds the blocks oldest first
+	order []*Orphan
+	stats Stats
+	mu    sync.Mutex
+}
+
+func (p *pool) Add(orphan *Orphan) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.byHash[string(orphan.Hash)]; ok {
+		return false
+	}
+
+	for len(p.order) >= p.cfg.MaxSize && len(p.order) > 0 {
+		p.remove(p.order[0])
+		p.stats.Evicted++
+	}
+
+	parent := string(orphan.Block.GetPrevhash())
+	p.byHash[string(orphan.Hash)] = orphan
+	p.byParent[parent] = append(p.byParent[parent], orphan)
+	p.order = append(p.order, orphan)
+
+	return true
+}
+
+// remove drops the orphan from every index of the pool
+func (p *pool) remove(orphan *Orphan) {
+	delete(p.byHash, string(orphan.Hash))
+
+	parent := string(orphan.Block.GetPrevhash())
+	siblings := p.byParent[parent]
+	for i, o := range siblings {
+		if o == orphan {
+			siblings = append(siblings[:i:i], siblings[i+1:]...)
+			break
+		}
+	}
+
+	if len(siblings) == 0 {
+		delete(p.byParent, parent)
+	} else {
+		p.byParent[parent] = siblings
+	}
+
+	for i, o := range p.order {
+		if o == orphan {
+			p.order = append(p.order[:i:i], p.order[i+1:]...)
+			break
+		}
+	}
+}
+
+func (p *pool) Has(hash []byte) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, ok := p.byHash[string(hash)]
+	return ok
+}
+
+func (p *pool) Full() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.order) >= p.cfg.MaxSize
+}
+
+func (p *pool) TakeChildren(hash []byte) []*Orphan {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	children := append([]*Orphan{}, p.byParent[string(hash)]...)
+	for _, child := range children {
+		p.remove(child)
+		p.stats.Connected++
+	}
+
+	return children
+}
+
+func (p *pool) Expire(now time.Time) []*Orphan {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	expired := make([]*Orphan, 0)
+	for len(p.order) > 0 && now.Sub(p.order[0].Added) > p.cfg.TTL {
+		expired = append(expired, p.order[0])
+		p.remove(p.order[0])
+		p.stats.Expired++
+	}
+
+	return expired
+}
+
+func (p *pool) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := p.stats
+	stats.Held = len(p.order)
+
+	return stats
+}
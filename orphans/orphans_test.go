@@ -0,0 +1,89 @@
+package orphans
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+)
+
+func orphanOf(hash string, parent string, added time.Time) *Orphan {
+	return &Orphan{
+		Block: &pb.Block{Prevhash: []byte(parent)},
+		Hash:  []byte(hash),
+		Added: added,
+	}
+}
+
+func TestTakeChildren(t *testing.T) {
+	now := time.Now()
+	p := New(DefaultConfig)
+
+	p.Add(orphanOf("Gob", "George", now))
+	p.Add(orphanOf("Michael", "George", now))
+	p.Add(orphanOf("George Michael", "Michael", now))
+
+	if p.Add(orphanOf("Gob", "George", now)) {
+		t.Errorf("expected a block already held not to be added again")
+	}
+
+	children := p.TakeChildren([]byte("George"))
+	if len(children) != 2 || string(children[0].Hash) != "Gob" || string(children[1].Hash) != "Michael" {
+		t.Errorf("expected Gob and Michael, in the order added, got %v", children)
+	}
+
+	if p.Has([]byte("Gob")) || !p.Has([]byte("George Michael")) {
+		t.Errorf("expected only the children taken to be removed")
+	}
+
+	if stats := p.Stats(); stats.Held != 1 || stats.Connected != 2 {
+		t.Errorf("expected 1 held and 2 connected, got %+v", stats)
+	}
+}
+
+func TestAddEvictsOldest(t *testing.T) {
+	now := time.Now()
+	p := New(Config{MaxSize: 2, TTL: time.Hour})
+
+	p.Add(orphanOf("Lucille", "Bluth", now))
+	p.Add(orphanOf("Buster", "Lucille", now))
+
+	if !p.Full() {
+		t.Errorf("expected the pool to be full")
+	}
+
+	p.Add(orphanOf("Tobias", "Funke", now))
+
+	if p.Has([]byte("Lucille")) || !p.Has([]byte("Buster")) || !p.Has([]byte("Tobias")) {
+		t.Errorf("expected the oldest block to be evicted")
+	}
+
+	if children := p.TakeChildren([]byte("Bluth")); len(children) != 0 {
+		t.Errorf("expected the evicted block not to be taken, got %v", children)
+	}
+
+	if stats := p.Stats(); stats.Held != 2 || stats.Evicted != 1 {
+		t.Errorf("expected 2 held and 1 evicted, got %+v", stats)
+	}
+}
+
+func TestExpire(t *testing.T) {
+	now := time.Now()
+	p := New(Config{MaxSize: 10, TTL: time.Hour})
+
+	p.Add(orphanOf("Lindsay", "Bluth", now.Add(-2*time.Hour)))
+	p.Add(orphanOf("Maeby", "Lindsay", now))
+
+	expired := p.Expire(now)
+	if len(expired) != 1 || string(expired[0].Hash) != "Lindsay" {
+		t.Errorf("expected Lindsay to expire, got %v", expired)
+	}
+
+	if !p.Has([]byte("Maeby")) {
+		t.Errorf("expected Maeby, within the TTL, to be held")
+	}
+
+	if stats := p.Stats(); stats.Held != 1 || stats.Expired != 1 {
+		t.Errorf("expected 1 held and 1 expired, got %+v", stats)
+	}
+}
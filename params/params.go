@@ -0,0 +1,176 @@
+package params
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/asgaines/blockchain/address"
+	"github.com/asgaines/blockchain/transactions"
+)
+
+// LedgerMode is how a network records who owns credit
+type LedgerMode int
+
+const (
+	// LedgerAccount records credit as balances of addresses: each tx moves
+	// value from its sender to its recipient
+	LedgerAccount LedgerMode = iota
+	// LedgerUTXO records credit as unspent tx outputs: each tx spends outputs
+	// of earlier txs and creates new ones
+	LedgerUTXO
+)
+
+func (m LedgerMode) String() string {
+	switch m {
+	case LedgerAccount:
+		return "account"
+	case LedgerUTXO:
+		return "utxo"
+	default:
+		return fmt.Sprintf("LedgerMode(%d)", int(m))
+	}
+}
+
+// InitialExpectedHashrate is the seed of how many hashes are possible per
+// second, from which the genesis difficulty of each network is set. It is
+// overridden by real data from the first recalculation.
+//
+// Setting it too high could lead to the genesis block solve taking a long time
+// before the difficulty is adjusted.
+const InitialExpectedHashrate = float64(50) // ultra: 700_000)
+
+// Params are the consensus rules of a network. Every node of a network must
+// share them, else they will disagree on which chains are valid.
+type Params struct {
+	// Network is the network whose addresses are accepted in txs
+	Network *address.Network
+	// Ledger is how the network records who owns credit
+	Ledger LedgerMode
+	// InitialSubsidy is the new credit, in base units, the miner of each block
+	// is paid before the first halving
+	InitialSubsidy uint64
+	// HalvingInterval is the number of blocks after which the subsidy halves
+	HalvingInterval uint64
+	// MaxSupply is the most credit, in base units, subsidies can ever create.
+	// Once reached, miners are paid fees alone
+	MaxSupply uint64
+	// CoinbaseMaturity is the number of blocks after the one paying a block
+	// solve reward before the reward can be spent. Should the block be
+	// orphaned in the meantime, the reward never existed
+	CoinbaseMaturity uint64
+	// MaxFutureDrift is how far ahead of the network-adjusted time a block may
+	// be timestamped
+	MaxFutureDrift time.Duration
+	// TargetDurPerBlock is the desired amount of time between blocks, which the
+	// difficulty is recalculated to aim for
+	TargetDurPerBlock time.Duration
+	// RecalcPeriod is the number of blocks solved between recalculations of the
+	// difficulty
+	RecalcPeriod int
+	// GenesisDifficulty is the difficulty required of the blocks after the
+	// genesis block, until the first recalculation
+	GenesisDifficulty float64
+}
+
+// IsMature reports whether a block solve reward paid at the height can be
+// spent by a tx in the block at spendHeight
+func (p *Params) IsMature(height uint64, spendHeight uint64) bool {
+	return spendHeight >= height && spendHeight-height >= p.CoinbaseMaturity
+}
+
+// maxHalvings is the number of halvings after which any subsidy is spent
+const maxHalvings = 64
+
+// Issued is the total subsidy paid by the blocks up to and including the
+// height, were each to claim its full subsidy
+func (p *Params) Issued(height uint64) uint64 {
+	if p.HalvingInterval == 0 {
+		return 0
+	}
+
+	var issued uint64
+	// Every block of an era between halvings pays the same subsidy
+	for era := uint64(0); era < maxHalvings && era*p.HalvingInterval < height; era++ {
+		blocks := p.HalvingInterval
+		if remaining := height - era*p.HalvingInterval; remaining < blocks {
+			blocks = remaining
+		}
+
+		subsidy := p.InitialSubsidy >> era
+		if subsidy != 0 && blocks > (p.MaxSupply-issued)/subsidy {
+			return p.MaxSupply
+		}
+
+		issued += blocks * subsidy
+	}
+
+	return issued
+}
+
+// Subsidy is the new credit, in base units, the miner of the block at the
+// height can claim on top of the fees of its txs. It halves every
+// HalvingInterval blocks, and is cut short so that the total never exceeds
+// MaxSupply.
+func (p *Params) Subsidy(height uint64) uint64 {
+	if height == 0 {
+		return 0
+	}
+
+	return p.Issued(height) - p.Issued(height-1)
+}
+
+var (
+	// Mainnet is the network of real credit
+	Mainnet = &Params{
+		Network:           address.Mainnet,
+		Ledger:            LedgerAccount,
+		InitialSubsidy:    100 * transactions.Coin,
+		HalvingInterval:   105000,
+		MaxSupply:         21000000 * transactions.Coin,
+		CoinbaseMaturity:  100,
+		MaxFutureDrift:    2 * time.Hour,
+		TargetDurPerBlock: 10 * time.Second,
+		RecalcPeriod:      10,
+		GenesisDifficulty: InitialExpectedHashrate * 10,
+	}
+	// Testnet is for experimenting without consequence. Its subsidy halves
+	// often, so that the whole schedule can be tried out
+	Testnet = &Params{
+		Network:           address.Testnet,
+		Ledger:            LedgerAccount,
+		InitialSubsidy:    100 * transactions.Coin,
+		HalvingInterval:   1000,
+		MaxSupply:         200000 * transactions.Coin,
+		CoinbaseMaturity:  10,
+		MaxFutureDrift:    2 * time.Hour,
+		TargetDurPerBlock: 10 * time.Second,
+		RecalcPeriod:      10,
+		GenesisDifficulty: InitialExpectedHashrate * 10,
+	}
+	// Utxonet is for experimenting with the UTXO ledger
+	Utxonet = &Params{
+		Network:           address.Utxonet,
+		Ledger:            LedgerUTXO,
+		InitialSubsidy:    100 * transactions.Coin,
+		HalvingInterval:   1000,
+		MaxSupply:         200000 * transactions.Coin,
+		CoinbaseMaturity:  10,
+		MaxFutureDrift:    2 * time.Hour,
+		TargetDurPerBlock: 10 * time.Second,
+		RecalcPeriod:      10,
+		GenesisDifficulty: InitialExpectedHashrate * 10,
+	}
+)
+
+var all = []*Params{Mainnet, Testnet, Utxonet}
+
+// ByName looks up the params of a network by its name, as given on the command line
+func ByName(name string) (*Params, error) {
+	for _, p := range all {
+		if p.Network.Name == name {
+			return p, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown network %q", name)
+}
@@ -0,0 +1,112 @@
+package params
+
+import (
+	"testing"
+)
+
+func TestSubsidy(t *testing.T) {
+	p := &Params{
+		InitialSubsidy:  100,
+		HalvingInterval: 10,
+		MaxSupply:       1500,
+	}
+
+	cases := []struct {
+		name     string
+		height   uint64
+		expected uint64
+	}{
+		{
+			name:     "The genesis block pays no subsidy",
+			height:   0,
+			expected: 0,
+		},
+		{
+			name:     "The first block pays the initial subsidy",
+			height:   1,
+			expected: 100,
+		},
+		{
+			name:     "The last block before the halving pays the initial subsidy",
+			height:   10,
+			expected: 100,
+		},
+		{
+			name:     "The first block after the halving pays half",
+			height:   11,
+			expected: 50,
+		},
+		{
+			name:     "The block reaching the supply cap pays only up to it",
+			height:   20,
+			expected: 50,
+		},
+		{
+			name:     "Blocks past the supply cap pay no subsidy",
+			height:   21,
+			expected: 0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := p.Subsidy(c.height); got != c.expected {
+				t.Errorf("expected %d, got %d", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestIssued(t *testing.T) {
+	cases := []struct {
+		name     string
+		params   *Params
+		height   uint64
+		expected uint64
+	}{
+		{
+			name: "The subsidies of every block so far are summed across halvings",
+			params: &Params{
+				InitialSubsidy:  100,
+				HalvingInterval: 10,
+				MaxSupply:       10000,
+			},
+			height:   25,
+			expected: 10*100 + 10*50 + 5*25,
+		},
+		{
+			name: "Issuance stops at the supply cap partway through a block",
+			params: &Params{
+				InitialSubsidy:  100,
+				HalvingInterval: 10,
+				MaxSupply:       1025,
+			},
+			height:   11,
+			expected: 1025,
+		},
+		{
+			name: "Issuance stops once the subsidy halves to nothing",
+			params: &Params{
+				InitialSubsidy:  4,
+				HalvingInterval: 1,
+				MaxSupply:       10000,
+			},
+			height:   1000,
+			expected: 4 + 2 + 1,
+		},
+		{
+			name:     "The mainnet schedule ends just short of the supply cap",
+			params:   Mainnet,
+			height:   ^uint64(0),
+			expected: 2099999998845000,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.params.Issued(c.height); got != c.expected {
+				t.Errorf("expected %d, got %d", c.expected, got)
+			}
+		})
+	}
+}
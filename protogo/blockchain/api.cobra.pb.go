@@ -454,3 +454,390 @@ func init() {
 	NodeClientCommand.AddCommand(_NodeGetCreditClientCommand)
 	_DefaultNodeClientCommandConfig.AddFlags(_NodeGetCreditClientCommand.Flags())
 }
+
+var _NodeGetUnspentClientCommand = &cobra.Command{
+	Use:  "getunspent",
+	Long: "GetUnspent client\n\nYou can use environment variables with the same name of the command flags.\nAll caps and s/-/_, e.g. SERVER_ADDR.",
+	Example: `
+Save a sample request to a file (or refer to your protobuf descriptor to create one):
+	getunspent -p > req.json
+
+Submit request using file:
+	getunspent -f req.json
+
+Authenticate using the Authorization header (requires transport security):
+	export AUTH_TOKEN=your_access_token
+	export SERVER_ADDR=api.example.com:443
+	echo '{json}' | getunspent --tls`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var v GetUnspentRequest
+		err := _NodeRoundTrip(v, func(cli NodeClient, in iocodec.Decoder, out iocodec.Encoder) error {
+
+			err := in.Decode(&v)
+			if err != nil {
+				return err
+			}
+
+			resp, err := cli.GetUnspent(context.Background(), &v)
+
+			if err != nil {
+				return err
+			}
+
+			return out.Encode(resp)
+
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	NodeClientCommand.AddCommand(_NodeGetUnspentClientCommand)
+	_DefaultNodeClientCommandConfig.AddFlags(_NodeGetUnspentClientCommand.Flags())
+}
+
+var _NodeGetTxClientCommand = &cobra.Command{
+	Use:  "gettx",
+	Long: "GetTx client\n\nYou can use environment variables with the same name of the command flags.\nAll caps and s/-/_, e.g. SERVER_ADDR.",
+	Example: `
+Save a sample request to a file (or refer to your protobuf descriptor to create one):
+	gettx -p > req.json
+
+Submit request using file:
+	gettx -f req.json
+
+Authenticate using the Authorization header (requires transport security):
+	export AUTH_TOKEN=your_access_token
+	export SERVER_ADDR=api.example.com:443
+	echo '{json}' | gettx --tls`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var v GetTxRequest
+		err := _NodeRoundTrip(v, func(cli NodeClient, in iocodec.Decoder, out iocodec.Encoder) error {
+
+			err := in.Decode(&v)
+			if err != nil {
+				return err
+			}
+
+			resp, err := cli.GetTx(context.Background(), &v)
+
+			if err != nil {
+				return err
+			}
+
+			return out.Encode(resp)
+
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	NodeClientCommand.AddCommand(_NodeGetTxClientCommand)
+	_DefaultNodeClientCommandConfig.AddFlags(_NodeGetTxClientCommand.Flags())
+}
+
+var _NodeGetSupplyClientCommand = &cobra.Command{
+	Use:  "getsupply",
+	Long: "GetSupply client\n\nYou can use environment variables with the same name of the command flags.\nAll caps and s/-/_, e.g. SERVER_ADDR.",
+	Example: `
+Save a sample request to a file (or refer to your protobuf descriptor to create one):
+	getsupply -p > req.json
+
+Submit request using file:
+	getsupply -f req.json
+
+Authenticate using the Authorization header (requires transport security):
+	export AUTH_TOKEN=your_access_token
+	export SERVER_ADDR=api.example.com:443
+	echo '{json}' | getsupply --tls`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var v GetSupplyRequest
+		err := _NodeRoundTrip(v, func(cli NodeClient, in iocodec.Decoder, out iocodec.Encoder) error {
+
+			err := in.Decode(&v)
+			if err != nil {
+				return err
+			}
+
+			resp, err := cli.GetSupply(context.Background(), &v)
+
+			if err != nil {
+				return err
+			}
+
+			return out.Encode(resp)
+
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	NodeClientCommand.AddCommand(_NodeGetSupplyClientCommand)
+	_DefaultNodeClientCommandConfig.AddFlags(_NodeGetSupplyClientCommand.Flags())
+}
+
+var _NodeGetTxProofClientCommand = &cobra.Command{
+	Use:  "gettxproof",
+	Long: "GetTxProof client\n\nYou can use environment variables with the same name of the command flags.\nAll caps and s/-/_, e.g. SERVER_ADDR.",
+	Example: `
+Save a sample request to a file (or refer to your protobuf descriptor to create one):
+	gettxproof -p > req.json
+
+Submit request using file:
+	gettxproof -f req.json
+
+Authenticate using the Authorization header (requires transport security):
+	export AUTH_TOKEN=your_access_token
+	export SERVER_ADDR=api.example.com:443
+	echo '{json}' | gettxproof --tls`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var v GetTxProofRequest
+		err := _NodeRoundTrip(v, func(cli NodeClient, in iocodec.Decoder, out iocodec.Encoder) error {
+
+			err := in.Decode(&v)
+			if err != nil {
+				return err
+			}
+
+			resp, err := cli.GetTxProof(context.Background(), &v)
+
+			if err != nil {
+				return err
+			}
+
+			return out.Encode(resp)
+
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	NodeClientCommand.AddCommand(_NodeGetTxProofClientCommand)
+	_DefaultNodeClientCommandConfig.AddFlags(_NodeGetTxProofClientCommand.Flags())
+}
+
+var _NodeGetHeadersClientCommand = &cobra.Command{
+	Use:  "getheaders",
+	Long: "GetHeaders client\n\nYou can use environment variables with the same name of the command flags.\nAll caps and s/-/_, e.g. SERVER_ADDR.",
+	Example: `
+Save a sample request to a file (or refer to your protobuf descriptor to create one):
+	getheaders -p > req.json
+
+Submit request using file:
+	getheaders -f req.json
+
+Authenticate using the Authorization header (requires transport security):
+	export AUTH_TOKEN=your_access_token
+	export SERVER_ADDR=api.example.com:443
+	echo '{json}' | getheaders --tls`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var v GetHeadersRequest
+		err := _NodeRoundTrip(v, func(cli NodeClient, in iocodec.Decoder, out iocodec.Encoder) error {
+
+			err := in.Decode(&v)
+			if err != nil {
+				return err
+			}
+
+			resp, err := cli.GetHeaders(context.Background(), &v)
+
+			if err != nil {
+				return err
+			}
+
+			return out.Encode(resp)
+
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	NodeClientCommand.AddCommand(_NodeGetHeadersClientCommand)
+	_DefaultNodeClientCommandConfig.AddFlags(_NodeGetHeadersClientCommand.Flags())
+}
+
+var _NodeGetBlocksClientCommand = &cobra.Command{
+	Use:  "getblocks",
+	Long: "GetBlocks client\n\nYou can use environment variables with the same name of the command flags.\nAll caps and s/-/_, e.g. SERVER_ADDR.",
+	Example: `
+Save a sample request to a file (or refer to your protobuf descriptor to create one):
+	getblocks -p > req.json
+
+Submit request using file:
+	getblocks -f req.json
+
+Authenticate using the Authorization header (requires transport security):
+	export AUTH_TOKEN=your_access_token
+	export SERVER_ADDR=api.example.com:443
+	echo '{json}' | getblocks --tls`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var v GetBlocksRequest
+		err := _NodeRoundTrip(v, func(cli NodeClient, in iocodec.Decoder, out iocodec.Encoder) error {
+
+			err := in.Decode(&v)
+			if err != nil {
+				return err
+			}
+
+			resp, err := cli.GetBlocks(context.Background(), &v)
+
+			if err != nil {
+				return err
+			}
+
+			return out.Encode(resp)
+
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	NodeClientCommand.AddCommand(_NodeGetBlocksClientCommand)
+	_DefaultNodeClientCommandConfig.AddFlags(_NodeGetBlocksClientCommand.Flags())
+}
+
+var _NodeShareBlockClientCommand = &cobra.Command{
+	Use:  "shareblock",
+	Long: "ShareBlock client\n\nYou can use environment variables with the same name of the command flags.\nAll caps and s/-/_, e.g. SERVER_ADDR.",
+	Example: `
+Save a sample request to a file (or refer to your protobuf descriptor to create one):
+	shareblock -p > req.json
+
+Submit request using file:
+	shareblock -f req.json
+
+Authenticate using the Authorization header (requires transport security):
+	export AUTH_TOKEN=your_access_token
+	export SERVER_ADDR=api.example.com:443
+	echo '{json}' | shareblock --tls`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var v ShareBlockRequest
+		err := _NodeRoundTrip(v, func(cli NodeClient, in iocodec.Decoder, out iocodec.Encoder) error {
+
+			err := in.Decode(&v)
+			if err != nil {
+				return err
+			}
+
+			resp, err := cli.ShareBlock(context.Background(), &v)
+
+			if err != nil {
+				return err
+			}
+
+			return out.Encode(resp)
+
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	NodeClientCommand.AddCommand(_NodeShareBlockClientCommand)
+	_DefaultNodeClientCommandConfig.AddFlags(_NodeShareBlockClientCommand.Flags())
+}
+
+var _NodeGetBlockClientCommand = &cobra.Command{
+	Use:  "getblock",
+	Long: "GetBlock client\n\nYou can use environment variables with the same name of the command flags.\nAll caps and s/-/_, e.g. SERVER_ADDR.",
+	Example: `
+Save a sample request to a file (or refer to your protobuf descriptor to create one):
+	getblock -p > req.json
+
+Submit request using file:
+	getblock -f req.json
+
+Authenticate using the Authorization header (requires transport security):
+	export AUTH_TOKEN=your_access_token
+	export SERVER_ADDR=api.example.com:443
+	echo '{json}' | getblock --tls`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var v GetBlockRequest
+		err := _NodeRoundTrip(v, func(cli NodeClient, in iocodec.Decoder, out iocodec.Encoder) error {
+
+			err := in.Decode(&v)
+			if err != nil {
+				return err
+			}
+
+			resp, err := cli.GetBlock(context.Background(), &v)
+
+			if err != nil {
+				return err
+			}
+
+			return out.Encode(resp)
+
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	NodeClientCommand.AddCommand(_NodeGetBlockClientCommand)
+	_DefaultNodeClientCommandConfig.AddFlags(_NodeGetBlockClientCommand.Flags())
+}
+
+var _NodeGetStatsClientCommand = &cobra.Command{
+	Use:  "getstats",
+	Long: "GetStats client\n\nYou can use environment variables with the same name of the command flags.\nAll caps and s/-/_, e.g. SERVER_ADDR.",
+	Example: `
+Save a sample request to a file (or refer to your protobuf descriptor to create one):
+	getstats -p > req.json
+
+Submit request using file:
+	getstats -f req.json
+
+Authenticate using the Authorization header (requires transport security):
+	export AUTH_TOKEN=your_access_token
+	export SERVER_ADDR=api.example.com:443
+	echo '{json}' | getstats --tls`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var v GetStatsRequest
+		err := _NodeRoundTrip(v, func(cli NodeClient, in iocodec.Decoder, out iocodec.Encoder) error {
+
+			err := in.Decode(&v)
+			if err != nil {
+				return err
+			}
+
+			resp, err := cli.GetStats(context.Background(), &v)
+
+			if err != nil {
+				return err
+			}
+
+			return out.Encode(resp)
+
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	NodeClientCommand.AddCommand(_NodeGetStatsClientCommand)
+	_DefaultNodeClientCommandConfig.AddFlags(_NodeGetStatsClientCommand.Flags())
+}
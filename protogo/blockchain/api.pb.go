@@ -26,16 +26,55 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+type GetTxResponse_Status int32
+
+const (
+	GetTxResponse_UNKNOWN GetTxResponse_Status = 0
+	// PENDING txs are waiting in the txpool to be included in a block
+	GetTxResponse_PENDING GetTxResponse_Status = 1
+	// CONFIRMED txs are included in a block of the chain
+	GetTxResponse_CONFIRMED GetTxResponse_Status = 2
+	// DROPPED txs were pending, but have since left the txpool without
+	// being included in the chain: they expired, were evicted, or were
+	// orphaned and could not be returned to the txpool
+	GetTxResponse_DROPPED GetTxResponse_Status = 3
+)
+
+var GetTxResponse_Status_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "PENDING",
+	2: "CONFIRMED",
+	3: "DROPPED",
+}
+
+var GetTxResponse_Status_value = map[string]int32{
+	"UNKNOWN":   0,
+	"PENDING":   1,
+	"CONFIRMED": 2,
+	"DROPPED":   3,
+}
+
+func (x GetTxResponse_Status) String() string {
+	return proto.EnumName(GetTxResponse_Status_name, int32(x))
+}
+
+func (GetTxResponse_Status) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{26, 0}
+}
+
 type Block struct {
-	Timestamp            *timestamp.Timestamp `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Prevhash             []byte               `protobuf:"bytes,2,opt,name=prevhash,proto3" json:"prevhash,omitempty"`
-	Nonce                uint64               `protobuf:"varint,3,opt,name=nonce,proto3" json:"nonce,omitempty"`
-	Target               []byte               `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`
-	MerkleRoot           []byte               `protobuf:"bytes,5,opt,name=merkleRoot,proto3" json:"merkleRoot,omitempty"`
-	Txs                  []*Tx                `protobuf:"bytes,6,rep,name=txs,proto3" json:"txs,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
-	XXX_unrecognized     []byte               `json:"-"`
-	XXX_sizecache        int32                `json:"-"`
+	Timestamp  *timestamp.Timestamp `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Prevhash   []byte               `protobuf:"bytes,2,opt,name=prevhash,proto3" json:"prevhash,omitempty"`
+	Nonce      uint64               `protobuf:"varint,3,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	Target     []byte               `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`
+	MerkleRoot []byte               `protobuf:"bytes,5,opt,name=merkleRoot,proto3" json:"merkleRoot,omitempty"`
+	Txs        []*Tx                `protobuf:"bytes,6,rep,name=txs,proto3" json:"txs,omitempty"`
+	// version is the version of the canonical encoding the block hash is made
+	// over. Blocks of version 0 keep the hash they had before the encoding existed
+	Version              uint32   `protobuf:"varint,7,opt,name=version,proto3" json:"version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Block) Reset()         { *m = Block{} }
@@ -105,6 +144,105 @@ func (m *Block) GetTxs() []*Tx {
 	return nil
 }
 
+func (m *Block) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+// BlockHeader is every field of a block but its txs, which the merkle root
+// commits to. It hashes to the hash of its block, so a chain of headers can be
+// checked without the txs of any block.
+type BlockHeader struct {
+	Version uint32 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	// height is the index of the block in the chain. It is not hashed, as the
+	// prevhash already fixes the position of the block
+	Height               uint64               `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
+	Prevhash             []byte               `protobuf:"bytes,3,opt,name=prevhash,proto3" json:"prevhash,omitempty"`
+	MerkleRoot           []byte               `protobuf:"bytes,4,opt,name=merkleRoot,proto3" json:"merkleRoot,omitempty"`
+	Timestamp            *timestamp.Timestamp `protobuf:"bytes,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Target               []byte               `protobuf:"bytes,6,opt,name=target,proto3" json:"target,omitempty"`
+	Nonce                uint64               `protobuf:"varint,7,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *BlockHeader) Reset()         { *m = BlockHeader{} }
+func (m *BlockHeader) String() string { return proto.CompactTextString(m) }
+func (*BlockHeader) ProtoMessage()    {}
+func (*BlockHeader) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{1}
+}
+
+func (m *BlockHeader) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlockHeader.Unmarshal(m, b)
+}
+func (m *BlockHeader) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlockHeader.Marshal(b, m, deterministic)
+}
+func (m *BlockHeader) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlockHeader.Merge(m, src)
+}
+func (m *BlockHeader) XXX_Size() int {
+	return xxx_messageInfo_BlockHeader.Size(m)
+}
+func (m *BlockHeader) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlockHeader.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlockHeader proto.InternalMessageInfo
+
+func (m *BlockHeader) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *BlockHeader) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *BlockHeader) GetPrevhash() []byte {
+	if m != nil {
+		return m.Prevhash
+	}
+	return nil
+}
+
+func (m *BlockHeader) GetMerkleRoot() []byte {
+	if m != nil {
+		return m.MerkleRoot
+	}
+	return nil
+}
+
+func (m *BlockHeader) GetTimestamp() *timestamp.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
+func (m *BlockHeader) GetTarget() []byte {
+	if m != nil {
+		return m.Target
+	}
+	return nil
+}
+
+func (m *BlockHeader) GetNonce() uint64 {
+	if m != nil {
+		return m.Nonce
+	}
+	return 0
+}
+
 type Chain struct {
 	Blocks               []*Block `protobuf:"bytes,1,rep,name=blocks,proto3" json:"blocks,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -116,7 +254,7 @@ func (m *Chain) Reset()         { *m = Chain{} }
 func (m *Chain) String() string { return proto.CompactTextString(m) }
 func (*Chain) ProtoMessage()    {}
 func (*Chain) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ecf0878b123623e2, []int{1}
+	return fileDescriptor_ecf0878b123623e2, []int{2}
 }
 
 func (m *Chain) XXX_Unmarshal(b []byte) error {
@@ -163,7 +301,7 @@ func (m *NodeID) Reset()         { *m = NodeID{} }
 func (m *NodeID) String() string { return proto.CompactTextString(m) }
 func (*NodeID) ProtoMessage()    {}
 func (*NodeID) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ecf0878b123623e2, []int{2}
+	return fileDescriptor_ecf0878b123623e2, []int{3}
 }
 
 func (m *NodeID) XXX_Unmarshal(b []byte) error {
@@ -208,19 +346,54 @@ func (m *NodeID) GetReturnAddr() string {
 type Tx struct {
 	// timestamp is the moment the tx was generated
 	Timestamp *timestamp.Timestamp `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	// value is the amount of credit being transferred
-	Value float64 `protobuf:"fixed64,2,opt,name=value,proto3" json:"value,omitempty"`
-	// sender is the public key of the payer, the one giving credit
+	// legacyValue is the amount transferred in whole coins, as recorded by
+	// chains stored before amounts were counted in base units. It is only set
+	// on txs migrated from such a chain
+	LegacyValue float64 `protobuf:"fixed64,2,opt,name=legacyValue,proto3" json:"legacyValue,omitempty"`
+	// sender is the address of the payer, the one giving credit
 	Sender string `protobuf:"bytes,3,opt,name=sender,proto3" json:"sender,omitempty"`
-	// recipient is the public key of the payee, the one receiving credit
+	// recipient is the address of the payee, the one receiving credit
 	Recipient string `protobuf:"bytes,4,opt,name=recipient,proto3" json:"recipient,omitempty"`
 	// message is an optional field to describe or provide metadata for the transaction
 	Message string `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
 	// hash is the sha256 hash of the pertinent fields of the transaction
 	Hash []byte `protobuf:"bytes,6,opt,name=hash,proto3" json:"hash,omitempty"`
-	// senderKey is the value hashed to the sender's pubkey
-	// It is a stand-in for full public-private key authentication and is not secure
-	SenderKey            string   `protobuf:"bytes,7,opt,name=senderKey,proto3" json:"senderKey,omitempty"`
+	// signature is the sender's Ed25519 signature over the tx hash
+	Signature []byte `protobuf:"bytes,8,opt,name=signature,proto3" json:"signature,omitempty"`
+	// multisig is the set of keys behind a multisig sender address. It is
+	// only set when spending from a multisig address
+	Multisig *Multisig `protobuf:"bytes,9,opt,name=multisig,proto3" json:"multisig,omitempty"`
+	// signatures are the signatures over the tx hash made by keys of the
+	// multisig. At least the threshold of them must be valid
+	Signatures []*Signature `protobuf:"bytes,10,rep,name=signatures,proto3" json:"signatures,omitempty"`
+	// nonce is the sequence number of the tx among those sent by the sender,
+	// starting from 0. Each nonce can only be used once, preventing replays
+	Nonce uint64 `protobuf:"varint,11,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	// legacyFee is the fee in whole coins, as recorded by chains stored before
+	// amounts were counted in base units
+	LegacyFee float64 `protobuf:"fixed64,12,opt,name=legacyFee,proto3" json:"legacyFee,omitempty"`
+	// value is the amount of credit being transferred, in base units
+	Value uint64 `protobuf:"varint,13,opt,name=value,proto3" json:"value,omitempty"`
+	// fee is the amount of credit paid by the sender, on top of the value, to
+	// the miner of the block including the tx, in base units
+	Fee uint64 `protobuf:"varint,14,opt,name=fee,proto3" json:"fee,omitempty"`
+	// inputs are the outputs of earlier txs spent by the tx, on networks with
+	// a UTXO ledger. Each must be owned by the sender
+	Inputs []*OutPoint `protobuf:"bytes,15,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	// outputs are the credit created by the tx, on networks with a UTXO
+	// ledger. They take the place of the value and recipient
+	Outputs []*TxOut `protobuf:"bytes,16,rep,name=outputs,proto3" json:"outputs,omitempty"`
+	// lock holds the tx back until it matures, as a block height below
+	// 500000000 or else a unix time in seconds. The tx can only be included in
+	// a block at or after that height, or timestamped at or after that time.
+	// Zero leaves the tx unlocked
+	Lock uint64 `protobuf:"varint,17,opt,name=lock,proto3" json:"lock,omitempty"`
+	// height is the index of the block paying a block solve reward. It is
+	// only set on rewards, making the hash of each one unique
+	Height uint64 `protobuf:"varint,18,opt,name=height,proto3" json:"height,omitempty"`
+	// version is the version of the canonical encoding the hash is made over.
+	// Txs of version 0 keep the hash they had before the encoding existed
+	Version              uint32   `protobuf:"varint,19,opt,name=version,proto3" json:"version,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -230,7 +403,7 @@ func (m *Tx) Reset()         { *m = Tx{} }
 func (m *Tx) String() string { return proto.CompactTextString(m) }
 func (*Tx) ProtoMessage()    {}
 func (*Tx) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ecf0878b123623e2, []int{3}
+	return fileDescriptor_ecf0878b123623e2, []int{4}
 }
 
 func (m *Tx) XXX_Unmarshal(b []byte) error {
@@ -258,9 +431,9 @@ func (m *Tx) GetTimestamp() *timestamp.Timestamp {
 	return nil
 }
 
-func (m *Tx) GetValue() float64 {
+func (m *Tx) GetLegacyValue() float64 {
 	if m != nil {
-		return m.Value
+		return m.LegacyValue
 	}
 	return 0
 }
@@ -293,341 +466,1740 @@ func (m *Tx) GetHash() []byte {
 	return nil
 }
 
-func (m *Tx) GetSenderKey() string {
+func (m *Tx) GetSignature() []byte {
 	if m != nil {
-		return m.SenderKey
+		return m.Signature
 	}
-	return ""
+	return nil
 }
 
-type DiscoverRequest struct {
-	NodeID *NodeID `protobuf:"bytes,1,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
-	// peerAddrs is the collection of addresses of known nodes.
-	// They can be used to further discover more peers
-	KnownAddrs           []string `protobuf:"bytes,2,rep,name=knownAddrs,proto3" json:"knownAddrs,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *Tx) GetMultisig() *Multisig {
+	if m != nil {
+		return m.Multisig
+	}
+	return nil
 }
 
-func (m *DiscoverRequest) Reset()         { *m = DiscoverRequest{} }
-func (m *DiscoverRequest) String() string { return proto.CompactTextString(m) }
-func (*DiscoverRequest) ProtoMessage()    {}
-func (*DiscoverRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ecf0878b123623e2, []int{4}
+func (m *Tx) GetSignatures() []*Signature {
+	if m != nil {
+		return m.Signatures
+	}
+	return nil
 }
 
-func (m *DiscoverRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_DiscoverRequest.Unmarshal(m, b)
-}
-func (m *DiscoverRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_DiscoverRequest.Marshal(b, m, deterministic)
-}
-func (m *DiscoverRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_DiscoverRequest.Merge(m, src)
+func (m *Tx) GetNonce() uint64 {
+	if m != nil {
+		return m.Nonce
+	}
+	return 0
 }
-func (m *DiscoverRequest) XXX_Size() int {
-	return xxx_messageInfo_DiscoverRequest.Size(m)
+
+func (m *Tx) GetLegacyFee() float64 {
+	if m != nil {
+		return m.LegacyFee
+	}
+	return 0
 }
-func (m *DiscoverRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_DiscoverRequest.DiscardUnknown(m)
+
+func (m *Tx) GetValue() uint64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
 }
 
-var xxx_messageInfo_DiscoverRequest proto.InternalMessageInfo
+func (m *Tx) GetFee() uint64 {
+	if m != nil {
+		return m.Fee
+	}
+	return 0
+}
 
-func (m *DiscoverRequest) GetNodeID() *NodeID {
+func (m *Tx) GetInputs() []*OutPoint {
 	if m != nil {
-		return m.NodeID
+		return m.Inputs
 	}
 	return nil
 }
 
-func (m *DiscoverRequest) GetKnownAddrs() []string {
+func (m *Tx) GetOutputs() []*TxOut {
 	if m != nil {
-		return m.KnownAddrs
+		return m.Outputs
 	}
 	return nil
 }
 
-type DiscoverResponse struct {
-	NodeID *NodeID `protobuf:"bytes,1,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
-	// ok signifies to the pinger that it is ok to connect with and share
-	// new tx and block solve propagations
-	Ok bool `protobuf:"varint,2,opt,name=ok,proto3" json:"ok,omitempty"`
-	// peerAddrs is the collection of addresses of other known nodes.
-	// They can be used to further discover more peers
-	KnownAddrs           []string `protobuf:"bytes,3,rep,name=knownAddrs,proto3" json:"knownAddrs,omitempty"`
+func (m *Tx) GetLock() uint64 {
+	if m != nil {
+		return m.Lock
+	}
+	return 0
+}
+
+func (m *Tx) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *Tx) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+// OutPoint refers to an output of a tx
+type OutPoint struct {
+	// txHash is the hash of the tx creating the output
+	TxHash []byte `protobuf:"bytes,1,opt,name=txHash,proto3" json:"txHash,omitempty"`
+	// index is the position of the output among those of the tx
+	Index                uint32   `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *DiscoverResponse) Reset()         { *m = DiscoverResponse{} }
-func (m *DiscoverResponse) String() string { return proto.CompactTextString(m) }
-func (*DiscoverResponse) ProtoMessage()    {}
-func (*DiscoverResponse) Descriptor() ([]byte, []int) {
+func (m *OutPoint) Reset()         { *m = OutPoint{} }
+func (m *OutPoint) String() string { return proto.CompactTextString(m) }
+func (*OutPoint) ProtoMessage()    {}
+func (*OutPoint) Descriptor() ([]byte, []int) {
 	return fileDescriptor_ecf0878b123623e2, []int{5}
 }
 
-func (m *DiscoverResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_DiscoverResponse.Unmarshal(m, b)
+func (m *OutPoint) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OutPoint.Unmarshal(m, b)
 }
-func (m *DiscoverResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_DiscoverResponse.Marshal(b, m, deterministic)
+func (m *OutPoint) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OutPoint.Marshal(b, m, deterministic)
 }
-func (m *DiscoverResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_DiscoverResponse.Merge(m, src)
+func (m *OutPoint) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OutPoint.Merge(m, src)
 }
-func (m *DiscoverResponse) XXX_Size() int {
-	return xxx_messageInfo_DiscoverResponse.Size(m)
+func (m *OutPoint) XXX_Size() int {
+	return xxx_messageInfo_OutPoint.Size(m)
 }
-func (m *DiscoverResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_DiscoverResponse.DiscardUnknown(m)
+func (m *OutPoint) XXX_DiscardUnknown() {
+	xxx_messageInfo_OutPoint.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_DiscoverResponse proto.InternalMessageInfo
+var xxx_messageInfo_OutPoint proto.InternalMessageInfo
 
-func (m *DiscoverResponse) GetNodeID() *NodeID {
+func (m *OutPoint) GetTxHash() []byte {
 	if m != nil {
-		return m.NodeID
+		return m.TxHash
 	}
 	return nil
 }
 
-func (m *DiscoverResponse) GetOk() bool {
-	if m != nil {
-		return m.Ok
-	}
-	return false
-}
-
-func (m *DiscoverResponse) GetKnownAddrs() []string {
+func (m *OutPoint) GetIndex() uint32 {
 	if m != nil {
-		return m.KnownAddrs
+		return m.Index
 	}
-	return nil
+	return 0
 }
 
-type GetStateRequest struct {
-	NodeID               *NodeID  `protobuf:"bytes,1,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
+// MerkleStep is one level of the path from a tx up to the merkle root of its
+// block: the hash of the sibling to combine with
+type MerkleStep struct {
+	Hash []byte `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	// left is whether the sibling is on the left
+	Left                 bool     `protobuf:"varint,2,opt,name=left,proto3" json:"left,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *GetStateRequest) Reset()         { *m = GetStateRequest{} }
-func (m *GetStateRequest) String() string { return proto.CompactTextString(m) }
-func (*GetStateRequest) ProtoMessage()    {}
-func (*GetStateRequest) Descriptor() ([]byte, []int) {
+func (m *MerkleStep) Reset()         { *m = MerkleStep{} }
+func (m *MerkleStep) String() string { return proto.CompactTextString(m) }
+func (*MerkleStep) ProtoMessage()    {}
+func (*MerkleStep) Descriptor() ([]byte, []int) {
 	return fileDescriptor_ecf0878b123623e2, []int{6}
 }
 
-func (m *GetStateRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GetStateRequest.Unmarshal(m, b)
+func (m *MerkleStep) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MerkleStep.Unmarshal(m, b)
 }
-func (m *GetStateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GetStateRequest.Marshal(b, m, deterministic)
+func (m *MerkleStep) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MerkleStep.Marshal(b, m, deterministic)
 }
-func (m *GetStateRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetStateRequest.Merge(m, src)
+func (m *MerkleStep) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MerkleStep.Merge(m, src)
 }
-func (m *GetStateRequest) XXX_Size() int {
-	return xxx_messageInfo_GetStateRequest.Size(m)
+func (m *MerkleStep) XXX_Size() int {
+	return xxx_messageInfo_MerkleStep.Size(m)
 }
-func (m *GetStateRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetStateRequest.DiscardUnknown(m)
+func (m *MerkleStep) XXX_DiscardUnknown() {
+	xxx_messageInfo_MerkleStep.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_GetStateRequest proto.InternalMessageInfo
+var xxx_messageInfo_MerkleStep proto.InternalMessageInfo
 
-func (m *GetStateRequest) GetNodeID() *NodeID {
+func (m *MerkleStep) GetHash() []byte {
 	if m != nil {
-		return m.NodeID
+		return m.Hash
 	}
 	return nil
 }
 
-type GetStateResponse struct {
-	Chain                *Chain   `protobuf:"bytes,1,opt,name=chain,proto3" json:"chain,omitempty"`
-	Difficulty           float64  `protobuf:"fixed64,2,opt,name=difficulty,proto3" json:"difficulty,omitempty"`
+func (m *MerkleStep) GetLeft() bool {
+	if m != nil {
+		return m.Left
+	}
+	return false
+}
+
+type TxOut struct {
+	// recipient is the address able to spend the output
+	Recipient string `protobuf:"bytes,1,opt,name=recipient,proto3" json:"recipient,omitempty"`
+	// value is the credit held by the output, in base units
+	Value                uint64   `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *GetStateResponse) Reset()         { *m = GetStateResponse{} }
-func (m *GetStateResponse) String() string { return proto.CompactTextString(m) }
-func (*GetStateResponse) ProtoMessage()    {}
-func (*GetStateResponse) Descriptor() ([]byte, []int) {
+func (m *TxOut) Reset()         { *m = TxOut{} }
+func (m *TxOut) String() string { return proto.CompactTextString(m) }
+func (*TxOut) ProtoMessage()    {}
+func (*TxOut) Descriptor() ([]byte, []int) {
 	return fileDescriptor_ecf0878b123623e2, []int{7}
 }
 
-func (m *GetStateResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GetStateResponse.Unmarshal(m, b)
+func (m *TxOut) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TxOut.Unmarshal(m, b)
 }
-func (m *GetStateResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GetStateResponse.Marshal(b, m, deterministic)
+func (m *TxOut) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TxOut.Marshal(b, m, deterministic)
 }
-func (m *GetStateResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetStateResponse.Merge(m, src)
+func (m *TxOut) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TxOut.Merge(m, src)
 }
-func (m *GetStateResponse) XXX_Size() int {
-	return xxx_messageInfo_GetStateResponse.Size(m)
+func (m *TxOut) XXX_Size() int {
+	return xxx_messageInfo_TxOut.Size(m)
 }
-func (m *GetStateResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetStateResponse.DiscardUnknown(m)
+func (m *TxOut) XXX_DiscardUnknown() {
+	xxx_messageInfo_TxOut.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_GetStateResponse proto.InternalMessageInfo
+var xxx_messageInfo_TxOut proto.InternalMessageInfo
 
-func (m *GetStateResponse) GetChain() *Chain {
+func (m *TxOut) GetRecipient() string {
 	if m != nil {
-		return m.Chain
+		return m.Recipient
 	}
-	return nil
+	return ""
 }
 
-func (m *GetStateResponse) GetDifficulty() float64 {
+func (m *TxOut) GetValue() uint64 {
 	if m != nil {
-		return m.Difficulty
+		return m.Value
 	}
 	return 0
 }
 
-type ShareChainRequest struct {
-	NodeID               *NodeID  `protobuf:"bytes,1,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
-	Chain                *Chain   `protobuf:"bytes,2,opt,name=chain,proto3" json:"chain,omitempty"`
+// Unspent is an output not yet spent by any tx in the chain
+type Unspent struct {
+	OutPoint *OutPoint `protobuf:"bytes,1,opt,name=outPoint,proto3" json:"outPoint,omitempty"`
+	Output   *TxOut    `protobuf:"bytes,2,opt,name=output,proto3" json:"output,omitempty"`
+	// height is the index of the block containing the tx creating the output
+	Height uint64 `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
+	// coinbase is set on outputs of block solve rewards, which can't be spent
+	// until they mature
+	Coinbase             bool     `protobuf:"varint,4,opt,name=coinbase,proto3" json:"coinbase,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *ShareChainRequest) Reset()         { *m = ShareChainRequest{} }
-func (m *ShareChainRequest) String() string { return proto.CompactTextString(m) }
-func (*ShareChainRequest) ProtoMessage()    {}
-func (*ShareChainRequest) Descriptor() ([]byte, []int) {
+func (m *Unspent) Reset()         { *m = Unspent{} }
+func (m *Unspent) String() string { return proto.CompactTextString(m) }
+func (*Unspent) ProtoMessage()    {}
+func (*Unspent) Descriptor() ([]byte, []int) {
 	return fileDescriptor_ecf0878b123623e2, []int{8}
 }
 
-func (m *ShareChainRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ShareChainRequest.Unmarshal(m, b)
+func (m *Unspent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Unspent.Unmarshal(m, b)
 }
-func (m *ShareChainRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ShareChainRequest.Marshal(b, m, deterministic)
+func (m *Unspent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Unspent.Marshal(b, m, deterministic)
 }
-func (m *ShareChainRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ShareChainRequest.Merge(m, src)
+func (m *Unspent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Unspent.Merge(m, src)
 }
-func (m *ShareChainRequest) XXX_Size() int {
-	return xxx_messageInfo_ShareChainRequest.Size(m)
+func (m *Unspent) XXX_Size() int {
+	return xxx_messageInfo_Unspent.Size(m)
 }
-func (m *ShareChainRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_ShareChainRequest.DiscardUnknown(m)
+func (m *Unspent) XXX_DiscardUnknown() {
+	xxx_messageInfo_Unspent.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ShareChainRequest proto.InternalMessageInfo
+var xxx_messageInfo_Unspent proto.InternalMessageInfo
 
-func (m *ShareChainRequest) GetNodeID() *NodeID {
+func (m *Unspent) GetOutPoint() *OutPoint {
+	if m != nil {
+		return m.OutPoint
+	}
+	return nil
+}
+
+func (m *Unspent) GetOutput() *TxOut {
+	if m != nil {
+		return m.Output
+	}
+	return nil
+}
+
+func (m *Unspent) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *Unspent) GetCoinbase() bool {
+	if m != nil {
+		return m.Coinbase
+	}
+	return false
+}
+
+// Multisig defines an M-of-N multisignature account: credit held by it can only
+// be spent with signatures of at least threshold (M) of the pubkeys (N)
+type Multisig struct {
+	Threshold uint32 `protobuf:"varint,1,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	// pubkeys are the raw Ed25519 public keys able to sign
+	Pubkeys              [][]byte `protobuf:"bytes,2,rep,name=pubkeys,proto3" json:"pubkeys,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Multisig) Reset()         { *m = Multisig{} }
+func (m *Multisig) String() string { return proto.CompactTextString(m) }
+func (*Multisig) ProtoMessage()    {}
+func (*Multisig) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{9}
+}
+
+func (m *Multisig) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Multisig.Unmarshal(m, b)
+}
+func (m *Multisig) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Multisig.Marshal(b, m, deterministic)
+}
+func (m *Multisig) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Multisig.Merge(m, src)
+}
+func (m *Multisig) XXX_Size() int {
+	return xxx_messageInfo_Multisig.Size(m)
+}
+func (m *Multisig) XXX_DiscardUnknown() {
+	xxx_messageInfo_Multisig.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Multisig proto.InternalMessageInfo
+
+func (m *Multisig) GetThreshold() uint32 {
+	if m != nil {
+		return m.Threshold
+	}
+	return 0
+}
+
+func (m *Multisig) GetPubkeys() [][]byte {
+	if m != nil {
+		return m.Pubkeys
+	}
+	return nil
+}
+
+type Signature struct {
+	// pubkey is the raw Ed25519 public key which made the signature
+	Pubkey               []byte   `protobuf:"bytes,1,opt,name=pubkey,proto3" json:"pubkey,omitempty"`
+	Signature            []byte   `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Signature) Reset()         { *m = Signature{} }
+func (m *Signature) String() string { return proto.CompactTextString(m) }
+func (*Signature) ProtoMessage()    {}
+func (*Signature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{10}
+}
+
+func (m *Signature) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Signature.Unmarshal(m, b)
+}
+func (m *Signature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Signature.Marshal(b, m, deterministic)
+}
+func (m *Signature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Signature.Merge(m, src)
+}
+func (m *Signature) XXX_Size() int {
+	return xxx_messageInfo_Signature.Size(m)
+}
+func (m *Signature) XXX_DiscardUnknown() {
+	xxx_messageInfo_Signature.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Signature proto.InternalMessageInfo
+
+func (m *Signature) GetPubkey() []byte {
+	if m != nil {
+		return m.Pubkey
+	}
+	return nil
+}
+
+func (m *Signature) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+// Balances is the credit and nonce of every address of a chain, stored
+// alongside it so they need not be counted again on start
+type Balances struct {
+	// height is the number of blocks of the chain counted
+	Height uint64 `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	// tip is the hash of the block counted last
+	Tip                  []byte              `protobuf:"bytes,2,opt,name=tip,proto3" json:"tip,omitempty"`
+	Balances             map[string]*Balance `protobuf:"bytes,3,rep,name=balances,proto3" json:"balances,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *Balances) Reset()         { *m = Balances{} }
+func (m *Balances) String() string { return proto.CompactTextString(m) }
+func (*Balances) ProtoMessage()    {}
+func (*Balances) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{11}
+}
+
+func (m *Balances) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Balances.Unmarshal(m, b)
+}
+func (m *Balances) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Balances.Marshal(b, m, deterministic)
+}
+func (m *Balances) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Balances.Merge(m, src)
+}
+func (m *Balances) XXX_Size() int {
+	return xxx_messageInfo_Balances.Size(m)
+}
+func (m *Balances) XXX_DiscardUnknown() {
+	xxx_messageInfo_Balances.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Balances proto.InternalMessageInfo
+
+func (m *Balances) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *Balances) GetTip() []byte {
+	if m != nil {
+		return m.Tip
+	}
+	return nil
+}
+
+func (m *Balances) GetBalances() map[string]*Balance {
+	if m != nil {
+		return m.Balances
+	}
+	return nil
+}
+
+// Balance is what the txs of a chain moved to and from an address
+type Balance struct {
+	// credits is the value received, in base units
+	Credits uint64 `protobuf:"varint,1,opt,name=credits,proto3" json:"credits,omitempty"`
+	// debits is the value plus fee sent, in base units
+	Debits uint64 `protobuf:"varint,2,opt,name=debits,proto3" json:"debits,omitempty"`
+	// sent is the number of txs sent
+	Sent                 uint64   `protobuf:"varint,3,opt,name=sent,proto3" json:"sent,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Balance) Reset()         { *m = Balance{} }
+func (m *Balance) String() string { return proto.CompactTextString(m) }
+func (*Balance) ProtoMessage()    {}
+func (*Balance) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{12}
+}
+
+func (m *Balance) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Balance.Unmarshal(m, b)
+}
+func (m *Balance) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Balance.Marshal(b, m, deterministic)
+}
+func (m *Balance) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Balance.Merge(m, src)
+}
+func (m *Balance) XXX_Size() int {
+	return xxx_messageInfo_Balance.Size(m)
+}
+func (m *Balance) XXX_DiscardUnknown() {
+	xxx_messageInfo_Balance.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Balance proto.InternalMessageInfo
+
+func (m *Balance) GetCredits() uint64 {
+	if m != nil {
+		return m.Credits
+	}
+	return 0
+}
+
+func (m *Balance) GetDebits() uint64 {
+	if m != nil {
+		return m.Debits
+	}
+	return 0
+}
+
+func (m *Balance) GetSent() uint64 {
+	if m != nil {
+		return m.Sent
+	}
+	return 0
+}
+
+type DiscoverRequest struct {
+	NodeID *NodeID `protobuf:"bytes,1,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
+	// peerAddrs is the collection of addresses of known nodes.
+	// They can be used to further discover more peers
+	KnownAddrs           []string `protobuf:"bytes,2,rep,name=knownAddrs,proto3" json:"knownAddrs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DiscoverRequest) Reset()         { *m = DiscoverRequest{} }
+func (m *DiscoverRequest) String() string { return proto.CompactTextString(m) }
+func (*DiscoverRequest) ProtoMessage()    {}
+func (*DiscoverRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{13}
+}
+
+func (m *DiscoverRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DiscoverRequest.Unmarshal(m, b)
+}
+func (m *DiscoverRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DiscoverRequest.Marshal(b, m, deterministic)
+}
+func (m *DiscoverRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DiscoverRequest.Merge(m, src)
+}
+func (m *DiscoverRequest) XXX_Size() int {
+	return xxx_messageInfo_DiscoverRequest.Size(m)
+}
+func (m *DiscoverRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DiscoverRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DiscoverRequest proto.InternalMessageInfo
+
+func (m *DiscoverRequest) GetNodeID() *NodeID {
+	if m != nil {
+		return m.NodeID
+	}
+	return nil
+}
+
+func (m *DiscoverRequest) GetKnownAddrs() []string {
+	if m != nil {
+		return m.KnownAddrs
+	}
+	return nil
+}
+
+type DiscoverResponse struct {
+	NodeID *NodeID `protobuf:"bytes,1,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
+	// ok signifies to the pinger that it is ok to connect with and share
+	// new tx and block solve propagations
+	Ok bool `protobuf:"varint,2,opt,name=ok,proto3" json:"ok,omitempty"`
+	// peerAddrs is the collection of addresses of other known nodes.
+	// They can be used to further discover more peers
+	KnownAddrs []string `protobuf:"bytes,3,rep,name=knownAddrs,proto3" json:"knownAddrs,omitempty"`
+	// time is the clock of the responding node, from which the network-adjusted
+	// time is derived
+	Time                 *timestamp.Timestamp `protobuf:"bytes,4,opt,name=time,proto3" json:"time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *DiscoverResponse) Reset()         { *m = DiscoverResponse{} }
+func (m *DiscoverResponse) String() string { return proto.CompactTextString(m) }
+func (*DiscoverResponse) ProtoMessage()    {}
+func (*DiscoverResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{14}
+}
+
+func (m *DiscoverResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DiscoverResponse.Unmarshal(m, b)
+}
+func (m *DiscoverResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DiscoverResponse.Marshal(b, m, deterministic)
+}
+func (m *DiscoverResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DiscoverResponse.Merge(m, src)
+}
+func (m *DiscoverResponse) XXX_Size() int {
+	return xxx_messageInfo_DiscoverResponse.Size(m)
+}
+func (m *DiscoverResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DiscoverResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DiscoverResponse proto.InternalMessageInfo
+
+func (m *DiscoverResponse) GetNodeID() *NodeID {
+	if m != nil {
+		return m.NodeID
+	}
+	return nil
+}
+
+func (m *DiscoverResponse) GetOk() bool {
+	if m != nil {
+		return m.Ok
+	}
+	return false
+}
+
+func (m *DiscoverResponse) GetKnownAddrs() []string {
+	if m != nil {
+		return m.KnownAddrs
+	}
+	return nil
+}
+
+func (m *DiscoverResponse) GetTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.Time
+	}
+	return nil
+}
+
+type GetStateRequest struct {
+	NodeID               *NodeID  `protobuf:"bytes,1,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetStateRequest) Reset()         { *m = GetStateRequest{} }
+func (m *GetStateRequest) String() string { return proto.CompactTextString(m) }
+func (*GetStateRequest) ProtoMessage()    {}
+func (*GetStateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{15}
+}
+
+func (m *GetStateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetStateRequest.Unmarshal(m, b)
+}
+func (m *GetStateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetStateRequest.Marshal(b, m, deterministic)
+}
+func (m *GetStateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetStateRequest.Merge(m, src)
+}
+func (m *GetStateRequest) XXX_Size() int {
+	return xxx_messageInfo_GetStateRequest.Size(m)
+}
+func (m *GetStateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetStateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetStateRequest proto.InternalMessageInfo
+
+func (m *GetStateRequest) GetNodeID() *NodeID {
+	if m != nil {
+		return m.NodeID
+	}
+	return nil
+}
+
+type GetStateResponse struct {
+	Chain *Chain `protobuf:"bytes,1,opt,name=chain,proto3" json:"chain,omitempty"`
+	// difficulty is that required of the next block of the chain. It is for
+	// information only: nodes work it out from the chain itself
+	Difficulty float64 `protobuf:"fixed64,2,opt,name=difficulty,proto3" json:"difficulty,omitempty"`
+	// chainwork is the sum of the work of every block of the chain: the
+	// hashes expected to be tried to mine it. Big-endian.
+	Chainwork            []byte   `protobuf:"bytes,3,opt,name=chainwork,proto3" json:"chainwork,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetStateResponse) Reset()         { *m = GetStateResponse{} }
+func (m *GetStateResponse) String() string { return proto.CompactTextString(m) }
+func (*GetStateResponse) ProtoMessage()    {}
+func (*GetStateResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{16}
+}
+
+func (m *GetStateResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetStateResponse.Unmarshal(m, b)
+}
+func (m *GetStateResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetStateResponse.Marshal(b, m, deterministic)
+}
+func (m *GetStateResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetStateResponse.Merge(m, src)
+}
+func (m *GetStateResponse) XXX_Size() int {
+	return xxx_messageInfo_GetStateResponse.Size(m)
+}
+func (m *GetStateResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetStateResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetStateResponse proto.InternalMessageInfo
+
+func (m *GetStateResponse) GetChain() *Chain {
+	if m != nil {
+		return m.Chain
+	}
+	return nil
+}
+
+func (m *GetStateResponse) GetDifficulty() float64 {
+	if m != nil {
+		return m.Difficulty
+	}
+	return 0
+}
+
+func (m *GetStateResponse) GetChainwork() []byte {
+	if m != nil {
+		return m.Chainwork
+	}
+	return nil
+}
+
+type ShareChainRequest struct {
+	NodeID               *NodeID  `protobuf:"bytes,1,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
+	Chain                *Chain   `protobuf:"bytes,2,opt,name=chain,proto3" json:"chain,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ShareChainRequest) Reset()         { *m = ShareChainRequest{} }
+func (m *ShareChainRequest) String() string { return proto.CompactTextString(m) }
+func (*ShareChainRequest) ProtoMessage()    {}
+func (*ShareChainRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{17}
+}
+
+func (m *ShareChainRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ShareChainRequest.Unmarshal(m, b)
+}
+func (m *ShareChainRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ShareChainRequest.Marshal(b, m, deterministic)
+}
+func (m *ShareChainRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShareChainRequest.Merge(m, src)
+}
+func (m *ShareChainRequest) XXX_Size() int {
+	return xxx_messageInfo_ShareChainRequest.Size(m)
+}
+func (m *ShareChainRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShareChainRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ShareChainRequest proto.InternalMessageInfo
+
+func (m *ShareChainRequest) GetNodeID() *NodeID {
+	if m != nil {
+		return m.NodeID
+	}
+	return nil
+}
+
+func (m *ShareChainRequest) GetChain() *Chain {
+	if m != nil {
+		return m.Chain
+	}
+	return nil
+}
+
+type ShareChainResponse struct {
+	Accepted             bool     `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ShareChainResponse) Reset()         { *m = ShareChainResponse{} }
+func (m *ShareChainResponse) String() string { return proto.CompactTextString(m) }
+func (*ShareChainResponse) ProtoMessage()    {}
+func (*ShareChainResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{18}
+}
+
+func (m *ShareChainResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ShareChainResponse.Unmarshal(m, b)
+}
+func (m *ShareChainResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ShareChainResponse.Marshal(b, m, deterministic)
+}
+func (m *ShareChainResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShareChainResponse.Merge(m, src)
+}
+func (m *ShareChainResponse) XXX_Size() int {
+	return xxx_messageInfo_ShareChainResponse.Size(m)
+}
+func (m *ShareChainResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShareChainResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ShareChainResponse proto.InternalMessageInfo
+
+func (m *ShareChainResponse) GetAccepted() bool {
+	if m != nil {
+		return m.Accepted
+	}
+	return false
+}
+
+type ShareTxRequest struct {
+	NodeID               *NodeID  `protobuf:"bytes,1,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
+	Tx                   *Tx      `protobuf:"bytes,2,opt,name=tx,proto3" json:"tx,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ShareTxRequest) Reset()         { *m = ShareTxRequest{} }
+func (m *ShareTxRequest) String() string { return proto.CompactTextString(m) }
+func (*ShareTxRequest) ProtoMessage()    {}
+func (*ShareTxRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{19}
+}
+
+func (m *ShareTxRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ShareTxRequest.Unmarshal(m, b)
+}
+func (m *ShareTxRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ShareTxRequest.Marshal(b, m, deterministic)
+}
+func (m *ShareTxRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShareTxRequest.Merge(m, src)
+}
+func (m *ShareTxRequest) XXX_Size() int {
+	return xxx_messageInfo_ShareTxRequest.Size(m)
+}
+func (m *ShareTxRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShareTxRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ShareTxRequest proto.InternalMessageInfo
+
+func (m *ShareTxRequest) GetNodeID() *NodeID {
+	if m != nil {
+		return m.NodeID
+	}
+	return nil
+}
+
+func (m *ShareTxRequest) GetTx() *Tx {
+	if m != nil {
+		return m.Tx
+	}
+	return nil
+}
+
+type ShareTxResponse struct {
+	Accepted             bool     `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	Info                 string   `protobuf:"bytes,2,opt,name=info,proto3" json:"info,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ShareTxResponse) Reset()         { *m = ShareTxResponse{} }
+func (m *ShareTxResponse) String() string { return proto.CompactTextString(m) }
+func (*ShareTxResponse) ProtoMessage()    {}
+func (*ShareTxResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{20}
+}
+
+func (m *ShareTxResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ShareTxResponse.Unmarshal(m, b)
+}
+func (m *ShareTxResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ShareTxResponse.Marshal(b, m, deterministic)
+}
+func (m *ShareTxResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShareTxResponse.Merge(m, src)
+}
+func (m *ShareTxResponse) XXX_Size() int {
+	return xxx_messageInfo_ShareTxResponse.Size(m)
+}
+func (m *ShareTxResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShareTxResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ShareTxResponse proto.InternalMessageInfo
+
+func (m *ShareTxResponse) GetAccepted() bool {
+	if m != nil {
+		return m.Accepted
+	}
+	return false
+}
+
+func (m *ShareTxResponse) GetInfo() string {
+	if m != nil {
+		return m.Info
+	}
+	return ""
+}
+
+type GetCreditRequest struct {
+	NodeID *NodeID `protobuf:"bytes,1,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
+	// address is the address to report the credit of
+	Address              string   `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetCreditRequest) Reset()         { *m = GetCreditRequest{} }
+func (m *GetCreditRequest) String() string { return proto.CompactTextString(m) }
+func (*GetCreditRequest) ProtoMessage()    {}
+func (*GetCreditRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{21}
+}
+
+func (m *GetCreditRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetCreditRequest.Unmarshal(m, b)
+}
+func (m *GetCreditRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetCreditRequest.Marshal(b, m, deterministic)
+}
+func (m *GetCreditRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetCreditRequest.Merge(m, src)
+}
+func (m *GetCreditRequest) XXX_Size() int {
+	return xxx_messageInfo_GetCreditRequest.Size(m)
+}
+func (m *GetCreditRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetCreditRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetCreditRequest proto.InternalMessageInfo
+
+func (m *GetCreditRequest) GetNodeID() *NodeID {
+	if m != nil {
+		return m.NodeID
+	}
+	return nil
+}
+
+func (m *GetCreditRequest) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+type GetCreditResponse struct {
+	// value is the credit held by the address, in base units
+	Value uint64 `protobuf:"varint,3,opt,name=value,proto3" json:"value,omitempty"`
+	// nonce is the nonce expected of the next tx sent by the address, taking
+	// txs pending in the txpool into account
+	Nonce uint64 `protobuf:"varint,2,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	// immature is the credit paid to the address by recent block solve
+	// rewards, in base units. It can't be spent until the rewards mature, so
+	// is not included in value
+	Immature             uint64   `protobuf:"varint,4,opt,name=immature,proto3" json:"immature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetCreditResponse) Reset()         { *m = GetCreditResponse{} }
+func (m *GetCreditResponse) String() string { return proto.CompactTextString(m) }
+func (*GetCreditResponse) ProtoMessage()    {}
+func (*GetCreditResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{22}
+}
+
+func (m *GetCreditResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetCreditResponse.Unmarshal(m, b)
+}
+func (m *GetCreditResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetCreditResponse.Marshal(b, m, deterministic)
+}
+func (m *GetCreditResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetCreditResponse.Merge(m, src)
+}
+func (m *GetCreditResponse) XXX_Size() int {
+	return xxx_messageInfo_GetCreditResponse.Size(m)
+}
+func (m *GetCreditResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetCreditResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetCreditResponse proto.InternalMessageInfo
+
+func (m *GetCreditResponse) GetValue() uint64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *GetCreditResponse) GetNonce() uint64 {
+	if m != nil {
+		return m.Nonce
+	}
+	return 0
+}
+
+func (m *GetCreditResponse) GetImmature() uint64 {
+	if m != nil {
+		return m.Immature
+	}
+	return 0
+}
+
+type GetUnspentRequest struct {
+	NodeID               *NodeID  `protobuf:"bytes,1,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
+	Address              string   `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetUnspentRequest) Reset()         { *m = GetUnspentRequest{} }
+func (m *GetUnspentRequest) String() string { return proto.CompactTextString(m) }
+func (*GetUnspentRequest) ProtoMessage()    {}
+func (*GetUnspentRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{23}
+}
+
+func (m *GetUnspentRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetUnspentRequest.Unmarshal(m, b)
+}
+func (m *GetUnspentRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetUnspentRequest.Marshal(b, m, deterministic)
+}
+func (m *GetUnspentRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetUnspentRequest.Merge(m, src)
+}
+func (m *GetUnspentRequest) XXX_Size() int {
+	return xxx_messageInfo_GetUnspentRequest.Size(m)
+}
+func (m *GetUnspentRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetUnspentRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetUnspentRequest proto.InternalMessageInfo
+
+func (m *GetUnspentRequest) GetNodeID() *NodeID {
+	if m != nil {
+		return m.NodeID
+	}
+	return nil
+}
+
+func (m *GetUnspentRequest) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+type GetUnspentResponse struct {
+	// unspent are the outputs owned by the address, on networks with a UTXO
+	// ledger. Outputs spent by txs pending in the txpool, and rewards not
+	// yet mature enough to be spent in the next block, are left out
+	Unspent              []*Unspent `protobuf:"bytes,1,rep,name=unspent,proto3" json:"unspent,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *GetUnspentResponse) Reset()         { *m = GetUnspentResponse{} }
+func (m *GetUnspentResponse) String() string { return proto.CompactTextString(m) }
+func (*GetUnspentResponse) ProtoMessage()    {}
+func (*GetUnspentResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{24}
+}
+
+func (m *GetUnspentResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetUnspentResponse.Unmarshal(m, b)
+}
+func (m *GetUnspentResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetUnspentResponse.Marshal(b, m, deterministic)
+}
+func (m *GetUnspentResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetUnspentResponse.Merge(m, src)
+}
+func (m *GetUnspentResponse) XXX_Size() int {
+	return xxx_messageInfo_GetUnspentResponse.Size(m)
+}
+func (m *GetUnspentResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetUnspentResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetUnspentResponse proto.InternalMessageInfo
+
+func (m *GetUnspentResponse) GetUnspent() []*Unspent {
+	if m != nil {
+		return m.Unspent
+	}
+	return nil
+}
+
+type GetTxRequest struct {
+	NodeID *NodeID `protobuf:"bytes,1,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
+	// hash is the hash of the tx to look up
+	Hash                 []byte   `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetTxRequest) Reset()         { *m = GetTxRequest{} }
+func (m *GetTxRequest) String() string { return proto.CompactTextString(m) }
+func (*GetTxRequest) ProtoMessage()    {}
+func (*GetTxRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{25}
+}
+
+func (m *GetTxRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetTxRequest.Unmarshal(m, b)
+}
+func (m *GetTxRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetTxRequest.Marshal(b, m, deterministic)
+}
+func (m *GetTxRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetTxRequest.Merge(m, src)
+}
+func (m *GetTxRequest) XXX_Size() int {
+	return xxx_messageInfo_GetTxRequest.Size(m)
+}
+func (m *GetTxRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetTxRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetTxRequest proto.InternalMessageInfo
+
+func (m *GetTxRequest) GetNodeID() *NodeID {
+	if m != nil {
+		return m.NodeID
+	}
+	return nil
+}
+
+func (m *GetTxRequest) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+type GetTxResponse struct {
+	Tx     *Tx                  `protobuf:"bytes,1,opt,name=tx,proto3" json:"tx,omitempty"`
+	Status GetTxResponse_Status `protobuf:"varint,2,opt,name=status,proto3,enum=blockchain.GetTxResponse_Status" json:"status,omitempty"`
+	// blockHash is the hash of the block including the tx, when confirmed
+	BlockHash []byte `protobuf:"bytes,3,opt,name=blockHash,proto3" json:"blockHash,omitempty"`
+	// height is the index of the block including the tx, when confirmed
+	Height uint64 `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`
+	// confirmations is the number of blocks from the one including the tx to
+	// the end of the chain, itself included. It is 0 unless confirmed
+	Confirmations        uint64   `protobuf:"varint,5,opt,name=confirmations,proto3" json:"confirmations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetTxResponse) Reset()         { *m = GetTxResponse{} }
+func (m *GetTxResponse) String() string { return proto.CompactTextString(m) }
+func (*GetTxResponse) ProtoMessage()    {}
+func (*GetTxResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{26}
+}
+
+func (m *GetTxResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetTxResponse.Unmarshal(m, b)
+}
+func (m *GetTxResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetTxResponse.Marshal(b, m, deterministic)
+}
+func (m *GetTxResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetTxResponse.Merge(m, src)
+}
+func (m *GetTxResponse) XXX_Size() int {
+	return xxx_messageInfo_GetTxResponse.Size(m)
+}
+func (m *GetTxResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetTxResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetTxResponse proto.InternalMessageInfo
+
+func (m *GetTxResponse) GetTx() *Tx {
+	if m != nil {
+		return m.Tx
+	}
+	return nil
+}
+
+func (m *GetTxResponse) GetStatus() GetTxResponse_Status {
+	if m != nil {
+		return m.Status
+	}
+	return GetTxResponse_UNKNOWN
+}
+
+func (m *GetTxResponse) GetBlockHash() []byte {
+	if m != nil {
+		return m.BlockHash
+	}
+	return nil
+}
+
+func (m *GetTxResponse) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *GetTxResponse) GetConfirmations() uint64 {
+	if m != nil {
+		return m.Confirmations
+	}
+	return 0
+}
+
+type GetSupplyRequest struct {
+	NodeID               *NodeID  `protobuf:"bytes,1,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetSupplyRequest) Reset()         { *m = GetSupplyRequest{} }
+func (m *GetSupplyRequest) String() string { return proto.CompactTextString(m) }
+func (*GetSupplyRequest) ProtoMessage()    {}
+func (*GetSupplyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{27}
+}
+
+func (m *GetSupplyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetSupplyRequest.Unmarshal(m, b)
+}
+func (m *GetSupplyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetSupplyRequest.Marshal(b, m, deterministic)
+}
+func (m *GetSupplyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetSupplyRequest.Merge(m, src)
+}
+func (m *GetSupplyRequest) XXX_Size() int {
+	return xxx_messageInfo_GetSupplyRequest.Size(m)
+}
+func (m *GetSupplyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetSupplyRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetSupplyRequest proto.InternalMessageInfo
+
+func (m *GetSupplyRequest) GetNodeID() *NodeID {
+	if m != nil {
+		return m.NodeID
+	}
+	return nil
+}
+
+type GetSupplyResponse struct {
+	// height is the index of the last block of the chain
+	Height uint64 `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	// circulating is the credit in existence, in base units: the subsidies
+	// claimed by the blocks of the chain, less any fees left unclaimed
+	Circulating uint64 `protobuf:"varint,2,opt,name=circulating,proto3" json:"circulating,omitempty"`
+	// maxSupply is the most credit subsidies can ever create, in base units
+	MaxSupply uint64 `protobuf:"varint,3,opt,name=maxSupply,proto3" json:"maxSupply,omitempty"`
+	// nextSubsidy is the subsidy the next block can claim, in base units
+	NextSubsidy uint64 `protobuf:"varint,4,opt,name=nextSubsidy,proto3" json:"nextSubsidy,omitempty"`
+	// halvingInterval is the number of blocks after which the subsidy halves
+	HalvingInterval      uint64   `protobuf:"varint,5,opt,name=halvingInterval,proto3" json:"halvingInterval,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetSupplyResponse) Reset()         { *m = GetSupplyResponse{} }
+func (m *GetSupplyResponse) String() string { return proto.CompactTextString(m) }
+func (*GetSupplyResponse) ProtoMessage()    {}
+func (*GetSupplyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{28}
+}
+
+func (m *GetSupplyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetSupplyResponse.Unmarshal(m, b)
+}
+func (m *GetSupplyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetSupplyResponse.Marshal(b, m, deterministic)
+}
+func (m *GetSupplyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetSupplyResponse.Merge(m, src)
+}
+func (m *GetSupplyResponse) XXX_Size() int {
+	return xxx_messageInfo_GetSupplyResponse.Size(m)
+}
+func (m *GetSupplyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetSupplyResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetSupplyResponse proto.InternalMessageInfo
+
+func (m *GetSupplyResponse) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *GetSupplyResponse) GetCirculating() uint64 {
+	if m != nil {
+		return m.Circulating
+	}
+	return 0
+}
+
+func (m *GetSupplyResponse) GetMaxSupply() uint64 {
+	if m != nil {
+		return m.MaxSupply
+	}
+	return 0
+}
+
+func (m *GetSupplyResponse) GetNextSubsidy() uint64 {
+	if m != nil {
+		return m.NextSubsidy
+	}
+	return 0
+}
+
+func (m *GetSupplyResponse) GetHalvingInterval() uint64 {
+	if m != nil {
+		return m.HalvingInterval
+	}
+	return 0
+}
+
+type GetTxProofRequest struct {
+	NodeID *NodeID `protobuf:"bytes,1,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
+	// hash is the hash of the tx to prove included in the chain
+	Hash                 []byte   `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetTxProofRequest) Reset()         { *m = GetTxProofRequest{} }
+func (m *GetTxProofRequest) String() string { return proto.CompactTextString(m) }
+func (*GetTxProofRequest) ProtoMessage()    {}
+func (*GetTxProofRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{29}
+}
+
+func (m *GetTxProofRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetTxProofRequest.Unmarshal(m, b)
+}
+func (m *GetTxProofRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetTxProofRequest.Marshal(b, m, deterministic)
+}
+func (m *GetTxProofRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetTxProofRequest.Merge(m, src)
+}
+func (m *GetTxProofRequest) XXX_Size() int {
+	return xxx_messageInfo_GetTxProofRequest.Size(m)
+}
+func (m *GetTxProofRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetTxProofRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetTxProofRequest proto.InternalMessageInfo
+
+func (m *GetTxProofRequest) GetNodeID() *NodeID {
 	if m != nil {
 		return m.NodeID
 	}
 	return nil
 }
 
-func (m *ShareChainRequest) GetChain() *Chain {
+func (m *GetTxProofRequest) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+type GetTxProofResponse struct {
+	Tx *Tx `protobuf:"bytes,1,opt,name=tx,proto3" json:"tx,omitempty"`
+	// header is the block including the tx, without its txs
+	Header *Block `protobuf:"bytes,2,opt,name=header,proto3" json:"header,omitempty"`
+	// blockHash is the hash of the header
+	BlockHash []byte `protobuf:"bytes,3,opt,name=blockHash,proto3" json:"blockHash,omitempty"`
+	// height is the index of the block in the chain
+	Height uint64 `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`
+	// index is the position of the tx among those of the block
+	Index uint32 `protobuf:"varint,5,opt,name=index,proto3" json:"index,omitempty"`
+	// path leads from the tx hash up to the merkle root of the header
+	Path                 []*MerkleStep `protobuf:"bytes,6,rep,name=path,proto3" json:"path,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *GetTxProofResponse) Reset()         { *m = GetTxProofResponse{} }
+func (m *GetTxProofResponse) String() string { return proto.CompactTextString(m) }
+func (*GetTxProofResponse) ProtoMessage()    {}
+func (*GetTxProofResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{30}
+}
+
+func (m *GetTxProofResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetTxProofResponse.Unmarshal(m, b)
+}
+func (m *GetTxProofResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetTxProofResponse.Marshal(b, m, deterministic)
+}
+func (m *GetTxProofResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetTxProofResponse.Merge(m, src)
+}
+func (m *GetTxProofResponse) XXX_Size() int {
+	return xxx_messageInfo_GetTxProofResponse.Size(m)
+}
+func (m *GetTxProofResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetTxProofResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetTxProofResponse proto.InternalMessageInfo
+
+func (m *GetTxProofResponse) GetTx() *Tx {
+	if m != nil {
+		return m.Tx
+	}
+	return nil
+}
+
+func (m *GetTxProofResponse) GetHeader() *Block {
 	if m != nil {
-		return m.Chain
+		return m.Header
 	}
 	return nil
 }
 
-type ShareChainResponse struct {
-	Accepted             bool     `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"`
+func (m *GetTxProofResponse) GetBlockHash() []byte {
+	if m != nil {
+		return m.BlockHash
+	}
+	return nil
+}
+
+func (m *GetTxProofResponse) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *GetTxProofResponse) GetIndex() uint32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *GetTxProofResponse) GetPath() []*MerkleStep {
+	if m != nil {
+		return m.Path
+	}
+	return nil
+}
+
+type GetHeadersRequest struct {
+	NodeID *NodeID `protobuf:"bytes,1,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
+	// from is the height of the first header to return
+	From                 uint64   `protobuf:"varint,2,opt,name=from,proto3" json:"from,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *ShareChainResponse) Reset()         { *m = ShareChainResponse{} }
-func (m *ShareChainResponse) String() string { return proto.CompactTextString(m) }
-func (*ShareChainResponse) ProtoMessage()    {}
-func (*ShareChainResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ecf0878b123623e2, []int{9}
+func (m *GetHeadersRequest) Reset()         { *m = GetHeadersRequest{} }
+func (m *GetHeadersRequest) String() string { return proto.CompactTextString(m) }
+func (*GetHeadersRequest) ProtoMessage()    {}
+func (*GetHeadersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{31}
 }
 
-func (m *ShareChainResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ShareChainResponse.Unmarshal(m, b)
+func (m *GetHeadersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetHeadersRequest.Unmarshal(m, b)
 }
-func (m *ShareChainResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ShareChainResponse.Marshal(b, m, deterministic)
+func (m *GetHeadersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetHeadersRequest.Marshal(b, m, deterministic)
 }
-func (m *ShareChainResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ShareChainResponse.Merge(m, src)
+func (m *GetHeadersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetHeadersRequest.Merge(m, src)
 }
-func (m *ShareChainResponse) XXX_Size() int {
-	return xxx_messageInfo_ShareChainResponse.Size(m)
+func (m *GetHeadersRequest) XXX_Size() int {
+	return xxx_messageInfo_GetHeadersRequest.Size(m)
 }
-func (m *ShareChainResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_ShareChainResponse.DiscardUnknown(m)
+func (m *GetHeadersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetHeadersRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ShareChainResponse proto.InternalMessageInfo
+var xxx_messageInfo_GetHeadersRequest proto.InternalMessageInfo
 
-func (m *ShareChainResponse) GetAccepted() bool {
+func (m *GetHeadersRequest) GetNodeID() *NodeID {
 	if m != nil {
-		return m.Accepted
+		return m.NodeID
 	}
-	return false
+	return nil
 }
 
-type ShareTxRequest struct {
-	NodeID               *NodeID  `protobuf:"bytes,1,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
-	Tx                   *Tx      `protobuf:"bytes,2,opt,name=tx,proto3" json:"tx,omitempty"`
+func (m *GetHeadersRequest) GetFrom() uint64 {
+	if m != nil {
+		return m.From
+	}
+	return 0
+}
+
+type GetHeadersResponse struct {
+	// headers are those of the blocks of the chain from the height requested
+	// on, up to a limit. Request again from the height after the last to
+	// fetch more
+	Headers []*BlockHeader `protobuf:"bytes,1,rep,name=headers,proto3" json:"headers,omitempty"`
+	// height is the index of the last block of the chain
+	Height               uint64   `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
+	Difficulty           float64  `protobuf:"fixed64,3,opt,name=difficulty,proto3" json:"difficulty,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *ShareTxRequest) Reset()         { *m = ShareTxRequest{} }
-func (m *ShareTxRequest) String() string { return proto.CompactTextString(m) }
-func (*ShareTxRequest) ProtoMessage()    {}
-func (*ShareTxRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ecf0878b123623e2, []int{10}
+func (m *GetHeadersResponse) Reset()         { *m = GetHeadersResponse{} }
+func (m *GetHeadersResponse) String() string { return proto.CompactTextString(m) }
+func (*GetHeadersResponse) ProtoMessage()    {}
+func (*GetHeadersResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{32}
 }
 
-func (m *ShareTxRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ShareTxRequest.Unmarshal(m, b)
+func (m *GetHeadersResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetHeadersResponse.Unmarshal(m, b)
 }
-func (m *ShareTxRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ShareTxRequest.Marshal(b, m, deterministic)
+func (m *GetHeadersResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetHeadersResponse.Marshal(b, m, deterministic)
 }
-func (m *ShareTxRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ShareTxRequest.Merge(m, src)
+func (m *GetHeadersResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetHeadersResponse.Merge(m, src)
 }
-func (m *ShareTxRequest) XXX_Size() int {
-	return xxx_messageInfo_ShareTxRequest.Size(m)
+func (m *GetHeadersResponse) XXX_Size() int {
+	return xxx_messageInfo_GetHeadersResponse.Size(m)
 }
-func (m *ShareTxRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_ShareTxRequest.DiscardUnknown(m)
+func (m *GetHeadersResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetHeadersResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ShareTxRequest proto.InternalMessageInfo
+var xxx_messageInfo_GetHeadersResponse proto.InternalMessageInfo
 
-func (m *ShareTxRequest) GetNodeID() *NodeID {
+func (m *GetHeadersResponse) GetHeaders() []*BlockHeader {
+	if m != nil {
+		return m.Headers
+	}
+	return nil
+}
+
+func (m *GetHeadersResponse) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *GetHeadersResponse) GetDifficulty() float64 {
+	if m != nil {
+		return m.Difficulty
+	}
+	return 0
+}
+
+type GetBlocksRequest struct {
+	NodeID *NodeID `protobuf:"bytes,1,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
+	// from is the height of the first block to return
+	From uint64 `protobuf:"varint,2,opt,name=from,proto3" json:"from,omitempty"`
+	// count is the number of blocks to return, up to a limit
+	Count                uint32   `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetBlocksRequest) Reset()         { *m = GetBlocksRequest{} }
+func (m *GetBlocksRequest) String() string { return proto.CompactTextString(m) }
+func (*GetBlocksRequest) ProtoMessage()    {}
+func (*GetBlocksRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{33}
+}
+
+func (m *GetBlocksRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetBlocksRequest.Unmarshal(m, b)
+}
+func (m *GetBlocksRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetBlocksRequest.Marshal(b, m, deterministic)
+}
+func (m *GetBlocksRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetBlocksRequest.Merge(m, src)
+}
+func (m *GetBlocksRequest) XXX_Size() int {
+	return xxx_messageInfo_GetBlocksRequest.Size(m)
+}
+func (m *GetBlocksRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetBlocksRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetBlocksRequest proto.InternalMessageInfo
+
+func (m *GetBlocksRequest) GetNodeID() *NodeID {
 	if m != nil {
 		return m.NodeID
 	}
 	return nil
 }
 
-func (m *ShareTxRequest) GetTx() *Tx {
+func (m *GetBlocksRequest) GetFrom() uint64 {
 	if m != nil {
-		return m.Tx
+		return m.From
+	}
+	return 0
+}
+
+func (m *GetBlocksRequest) GetCount() uint32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type GetBlocksResponse struct {
+	// blocks are those of the chain from the height requested on, in order
+	Blocks               []*Block `protobuf:"bytes,1,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetBlocksResponse) Reset()         { *m = GetBlocksResponse{} }
+func (m *GetBlocksResponse) String() string { return proto.CompactTextString(m) }
+func (*GetBlocksResponse) ProtoMessage()    {}
+func (*GetBlocksResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{34}
+}
+
+func (m *GetBlocksResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetBlocksResponse.Unmarshal(m, b)
+}
+func (m *GetBlocksResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetBlocksResponse.Marshal(b, m, deterministic)
+}
+func (m *GetBlocksResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetBlocksResponse.Merge(m, src)
+}
+func (m *GetBlocksResponse) XXX_Size() int {
+	return xxx_messageInfo_GetBlocksResponse.Size(m)
+}
+func (m *GetBlocksResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetBlocksResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetBlocksResponse proto.InternalMessageInfo
+
+func (m *GetBlocksResponse) GetBlocks() []*Block {
+	if m != nil {
+		return m.Blocks
 	}
 	return nil
 }
 
-type ShareTxResponse struct {
+type ShareBlockRequest struct {
+	Block                *Block   `protobuf:"bytes,1,opt,name=block,proto3" json:"block,omitempty"`
+	NodeID               *NodeID  `protobuf:"bytes,2,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ShareBlockRequest) Reset()         { *m = ShareBlockRequest{} }
+func (m *ShareBlockRequest) String() string { return proto.CompactTextString(m) }
+func (*ShareBlockRequest) ProtoMessage()    {}
+func (*ShareBlockRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{35}
+}
+
+func (m *ShareBlockRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ShareBlockRequest.Unmarshal(m, b)
+}
+func (m *ShareBlockRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ShareBlockRequest.Marshal(b, m, deterministic)
+}
+func (m *ShareBlockRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShareBlockRequest.Merge(m, src)
+}
+func (m *ShareBlockRequest) XXX_Size() int {
+	return xxx_messageInfo_ShareBlockRequest.Size(m)
+}
+func (m *ShareBlockRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShareBlockRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ShareBlockRequest proto.InternalMessageInfo
+
+func (m *ShareBlockRequest) GetBlock() *Block {
+	if m != nil {
+		return m.Block
+	}
+	return nil
+}
+
+func (m *ShareBlockRequest) GetNodeID() *NodeID {
+	if m != nil {
+		return m.NodeID
+	}
+	return nil
+}
+
+type ShareBlockResponse struct {
+	// accepted is whether the node switched to the chain ending with the block
 	Accepted             bool     `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"`
 	Info                 string   `protobuf:"bytes,2,opt,name=info,proto3" json:"info,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -635,136 +2207,274 @@ type ShareTxResponse struct {
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *ShareTxResponse) Reset()         { *m = ShareTxResponse{} }
-func (m *ShareTxResponse) String() string { return proto.CompactTextString(m) }
-func (*ShareTxResponse) ProtoMessage()    {}
-func (*ShareTxResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ecf0878b123623e2, []int{11}
+func (m *ShareBlockResponse) Reset()         { *m = ShareBlockResponse{} }
+func (m *ShareBlockResponse) String() string { return proto.CompactTextString(m) }
+func (*ShareBlockResponse) ProtoMessage()    {}
+func (*ShareBlockResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{36}
 }
 
-func (m *ShareTxResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ShareTxResponse.Unmarshal(m, b)
+func (m *ShareBlockResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ShareBlockResponse.Unmarshal(m, b)
 }
-func (m *ShareTxResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ShareTxResponse.Marshal(b, m, deterministic)
+func (m *ShareBlockResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ShareBlockResponse.Marshal(b, m, deterministic)
 }
-func (m *ShareTxResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ShareTxResponse.Merge(m, src)
+func (m *ShareBlockResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShareBlockResponse.Merge(m, src)
 }
-func (m *ShareTxResponse) XXX_Size() int {
-	return xxx_messageInfo_ShareTxResponse.Size(m)
+func (m *ShareBlockResponse) XXX_Size() int {
+	return xxx_messageInfo_ShareBlockResponse.Size(m)
 }
-func (m *ShareTxResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_ShareTxResponse.DiscardUnknown(m)
+func (m *ShareBlockResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShareBlockResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ShareTxResponse proto.InternalMessageInfo
+var xxx_messageInfo_ShareBlockResponse proto.InternalMessageInfo
 
-func (m *ShareTxResponse) GetAccepted() bool {
+func (m *ShareBlockResponse) GetAccepted() bool {
 	if m != nil {
 		return m.Accepted
 	}
 	return false
 }
 
-func (m *ShareTxResponse) GetInfo() string {
+func (m *ShareBlockResponse) GetInfo() string {
 	if m != nil {
 		return m.Info
 	}
 	return ""
 }
 
-type GetCreditRequest struct {
+type GetBlockRequest struct {
+	NodeID *NodeID `protobuf:"bytes,1,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
+	// hash is the hash of the block, of the chain or a side branch of it
+	Hash                 []byte   `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetBlockRequest) Reset()         { *m = GetBlockRequest{} }
+func (m *GetBlockRequest) String() string { return proto.CompactTextString(m) }
+func (*GetBlockRequest) ProtoMessage()    {}
+func (*GetBlockRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{37}
+}
+
+func (m *GetBlockRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetBlockRequest.Unmarshal(m, b)
+}
+func (m *GetBlockRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetBlockRequest.Marshal(b, m, deterministic)
+}
+func (m *GetBlockRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetBlockRequest.Merge(m, src)
+}
+func (m *GetBlockRequest) XXX_Size() int {
+	return xxx_messageInfo_GetBlockRequest.Size(m)
+}
+func (m *GetBlockRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetBlockRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetBlockRequest proto.InternalMessageInfo
+
+func (m *GetBlockRequest) GetNodeID() *NodeID {
+	if m != nil {
+		return m.NodeID
+	}
+	return nil
+}
+
+func (m *GetBlockRequest) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+type GetBlockResponse struct {
+	Block                *Block   `protobuf:"bytes,1,opt,name=block,proto3" json:"block,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetBlockResponse) Reset()         { *m = GetBlockResponse{} }
+func (m *GetBlockResponse) String() string { return proto.CompactTextString(m) }
+func (*GetBlockResponse) ProtoMessage()    {}
+func (*GetBlockResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{38}
+}
+
+func (m *GetBlockResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetBlockResponse.Unmarshal(m, b)
+}
+func (m *GetBlockResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetBlockResponse.Marshal(b, m, deterministic)
+}
+func (m *GetBlockResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetBlockResponse.Merge(m, src)
+}
+func (m *GetBlockResponse) XXX_Size() int {
+	return xxx_messageInfo_GetBlockResponse.Size(m)
+}
+func (m *GetBlockResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetBlockResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetBlockResponse proto.InternalMessageInfo
+
+func (m *GetBlockResponse) GetBlock() *Block {
+	if m != nil {
+		return m.Block
+	}
+	return nil
+}
+
+type GetStatsRequest struct {
 	NodeID               *NodeID  `protobuf:"bytes,1,opt,name=nodeID,proto3" json:"nodeID,omitempty"`
-	Key                  string   `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *GetCreditRequest) Reset()         { *m = GetCreditRequest{} }
-func (m *GetCreditRequest) String() string { return proto.CompactTextString(m) }
-func (*GetCreditRequest) ProtoMessage()    {}
-func (*GetCreditRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ecf0878b123623e2, []int{12}
+func (m *GetStatsRequest) Reset()         { *m = GetStatsRequest{} }
+func (m *GetStatsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetStatsRequest) ProtoMessage()    {}
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{39}
+}
+
+func (m *GetStatsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetStatsRequest.Unmarshal(m, b)
+}
+func (m *GetStatsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetStatsRequest.Marshal(b, m, deterministic)
+}
+func (m *GetStatsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetStatsRequest.Merge(m, src)
+}
+func (m *GetStatsRequest) XXX_Size() int {
+	return xxx_messageInfo_GetStatsRequest.Size(m)
+}
+func (m *GetStatsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetStatsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetStatsRequest proto.InternalMessageInfo
+
+func (m *GetStatsRequest) GetNodeID() *NodeID {
+	if m != nil {
+		return m.NodeID
+	}
+	return nil
+}
+
+type GetStatsResponse struct {
+	// height is the index of the last block of the chain
+	Height uint64 `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	// treeBlocks is the number of blocks of the chain and its side branches
+	TreeBlocks uint64 `protobuf:"varint,2,opt,name=treeBlocks,proto3" json:"treeBlocks,omitempty"`
+	// orphans is the number of blocks held until their parent arrives
+	Orphans uint64 `protobuf:"varint,3,opt,name=orphans,proto3" json:"orphans,omitempty"`
+	// orphansConnected is the number of orphans taken from the pool once
+	// their parent arrived
+	OrphansConnected uint64 `protobuf:"varint,4,opt,name=orphansConnected,proto3" json:"orphansConnected,omitempty"`
+	// orphansEvicted is the number of orphans dropped to make room for others
+	OrphansEvicted uint64 `protobuf:"varint,5,opt,name=orphansEvicted,proto3" json:"orphansEvicted,omitempty"`
+	// orphansExpired is the number of orphans dropped having waited too long
+	// for their parent
+	OrphansExpired       uint64   `protobuf:"varint,6,opt,name=orphansExpired,proto3" json:"orphansExpired,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetStatsResponse) Reset()         { *m = GetStatsResponse{} }
+func (m *GetStatsResponse) String() string { return proto.CompactTextString(m) }
+func (*GetStatsResponse) ProtoMessage()    {}
+func (*GetStatsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ecf0878b123623e2, []int{40}
 }
 
-func (m *GetCreditRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GetCreditRequest.Unmarshal(m, b)
+func (m *GetStatsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetStatsResponse.Unmarshal(m, b)
 }
-func (m *GetCreditRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GetCreditRequest.Marshal(b, m, deterministic)
+func (m *GetStatsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetStatsResponse.Marshal(b, m, deterministic)
 }
-func (m *GetCreditRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetCreditRequest.Merge(m, src)
+func (m *GetStatsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetStatsResponse.Merge(m, src)
 }
-func (m *GetCreditRequest) XXX_Size() int {
-	return xxx_messageInfo_GetCreditRequest.Size(m)
+func (m *GetStatsResponse) XXX_Size() int {
+	return xxx_messageInfo_GetStatsResponse.Size(m)
 }
-func (m *GetCreditRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetCreditRequest.DiscardUnknown(m)
+func (m *GetStatsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetStatsResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_GetCreditRequest proto.InternalMessageInfo
+var xxx_messageInfo_GetStatsResponse proto.InternalMessageInfo
 
-func (m *GetCreditRequest) GetNodeID() *NodeID {
+func (m *GetStatsResponse) GetHeight() uint64 {
 	if m != nil {
-		return m.NodeID
+		return m.Height
 	}
-	return nil
+	return 0
 }
 
-func (m *GetCreditRequest) GetKey() string {
+func (m *GetStatsResponse) GetTreeBlocks() uint64 {
 	if m != nil {
-		return m.Key
+		return m.TreeBlocks
 	}
-	return ""
+	return 0
 }
 
-type GetCreditResponse struct {
-	Value                float64  `protobuf:"fixed64,1,opt,name=value,proto3" json:"value,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *GetStatsResponse) GetOrphans() uint64 {
+	if m != nil {
+		return m.Orphans
+	}
+	return 0
 }
 
-func (m *GetCreditResponse) Reset()         { *m = GetCreditResponse{} }
-func (m *GetCreditResponse) String() string { return proto.CompactTextString(m) }
-func (*GetCreditResponse) ProtoMessage()    {}
-func (*GetCreditResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_ecf0878b123623e2, []int{13}
+func (m *GetStatsResponse) GetOrphansConnected() uint64 {
+	if m != nil {
+		return m.OrphansConnected
+	}
+	return 0
 }
 
-func (m *GetCreditResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GetCreditResponse.Unmarshal(m, b)
-}
-func (m *GetCreditResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GetCreditResponse.Marshal(b, m, deterministic)
-}
-func (m *GetCreditResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetCreditResponse.Merge(m, src)
-}
-func (m *GetCreditResponse) XXX_Size() int {
-	return xxx_messageInfo_GetCreditResponse.Size(m)
-}
-func (m *GetCreditResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetCreditResponse.DiscardUnknown(m)
+func (m *GetStatsResponse) GetOrphansEvicted() uint64 {
+	if m != nil {
+		return m.OrphansEvicted
+	}
+	return 0
 }
 
-var xxx_messageInfo_GetCreditResponse proto.InternalMessageInfo
-
-func (m *GetCreditResponse) GetValue() float64 {
+func (m *GetStatsResponse) GetOrphansExpired() uint64 {
 	if m != nil {
-		return m.Value
+		return m.OrphansExpired
 	}
 	return 0
 }
 
 func init() {
+	proto.RegisterEnum("blockchain.GetTxResponse_Status", GetTxResponse_Status_name, GetTxResponse_Status_value)
 	proto.RegisterType((*Block)(nil), "blockchain.Block")
+	proto.RegisterType((*BlockHeader)(nil), "blockchain.BlockHeader")
 	proto.RegisterType((*Chain)(nil), "blockchain.Chain")
 	proto.RegisterType((*NodeID)(nil), "blockchain.NodeID")
 	proto.RegisterType((*Tx)(nil), "blockchain.Tx")
+	proto.RegisterType((*OutPoint)(nil), "blockchain.OutPoint")
+	proto.RegisterType((*MerkleStep)(nil), "blockchain.MerkleStep")
+	proto.RegisterType((*TxOut)(nil), "blockchain.TxOut")
+	proto.RegisterType((*Unspent)(nil), "blockchain.Unspent")
+	proto.RegisterType((*Multisig)(nil), "blockchain.Multisig")
+	proto.RegisterType((*Signature)(nil), "blockchain.Signature")
+	proto.RegisterType((*Balances)(nil), "blockchain.Balances")
+	proto.RegisterMapType((map[string]*Balance)(nil), "blockchain.Balances.BalancesEntry")
+	proto.RegisterType((*Balance)(nil), "blockchain.Balance")
 	proto.RegisterType((*DiscoverRequest)(nil), "blockchain.DiscoverRequest")
 	proto.RegisterType((*DiscoverResponse)(nil), "blockchain.DiscoverResponse")
 	proto.RegisterType((*GetStateRequest)(nil), "blockchain.GetStateRequest")
@@ -775,58 +2485,149 @@ func init() {
 	proto.RegisterType((*ShareTxResponse)(nil), "blockchain.ShareTxResponse")
 	proto.RegisterType((*GetCreditRequest)(nil), "blockchain.GetCreditRequest")
 	proto.RegisterType((*GetCreditResponse)(nil), "blockchain.GetCreditResponse")
+	proto.RegisterType((*GetUnspentRequest)(nil), "blockchain.GetUnspentRequest")
+	proto.RegisterType((*GetUnspentResponse)(nil), "blockchain.GetUnspentResponse")
+	proto.RegisterType((*GetTxRequest)(nil), "blockchain.GetTxRequest")
+	proto.RegisterType((*GetTxResponse)(nil), "blockchain.GetTxResponse")
+	proto.RegisterType((*GetSupplyRequest)(nil), "blockchain.GetSupplyRequest")
+	proto.RegisterType((*GetSupplyResponse)(nil), "blockchain.GetSupplyResponse")
+	proto.RegisterType((*GetTxProofRequest)(nil), "blockchain.GetTxProofRequest")
+	proto.RegisterType((*GetTxProofResponse)(nil), "blockchain.GetTxProofResponse")
+	proto.RegisterType((*GetHeadersRequest)(nil), "blockchain.GetHeadersRequest")
+	proto.RegisterType((*GetHeadersResponse)(nil), "blockchain.GetHeadersResponse")
+	proto.RegisterType((*GetBlocksRequest)(nil), "blockchain.GetBlocksRequest")
+	proto.RegisterType((*GetBlocksResponse)(nil), "blockchain.GetBlocksResponse")
+	proto.RegisterType((*ShareBlockRequest)(nil), "blockchain.ShareBlockRequest")
+	proto.RegisterType((*ShareBlockResponse)(nil), "blockchain.ShareBlockResponse")
+	proto.RegisterType((*GetBlockRequest)(nil), "blockchain.GetBlockRequest")
+	proto.RegisterType((*GetBlockResponse)(nil), "blockchain.GetBlockResponse")
+	proto.RegisterType((*GetStatsRequest)(nil), "blockchain.GetStatsRequest")
+	proto.RegisterType((*GetStatsResponse)(nil), "blockchain.GetStatsResponse")
 }
 
 func init() { proto.RegisterFile("proto/api.proto", fileDescriptor_ecf0878b123623e2) }
 
 var fileDescriptor_ecf0878b123623e2 = []byte{
-	// 721 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x55, 0xcb, 0x6e, 0xdb, 0x3a,
-	0x10, 0x85, 0xe4, 0x47, 0xac, 0xc9, 0x85, 0x9d, 0x10, 0xc1, 0x85, 0xa0, 0x3c, 0x6a, 0x68, 0x53,
-	0xa7, 0x0b, 0xbb, 0x75, 0x36, 0xdd, 0x74, 0x91, 0x17, 0xda, 0x22, 0x40, 0x11, 0x30, 0x5e, 0xf5,
-	0xb1, 0x90, 0x25, 0xda, 0x26, 0x6c, 0x93, 0xaa, 0x48, 0xa5, 0xce, 0x47, 0x76, 0xdb, 0xdf, 0xe8,
-	0x2f, 0x14, 0xa4, 0x68, 0x49, 0xb6, 0x93, 0x16, 0x75, 0x77, 0x9a, 0x99, 0x33, 0x67, 0x0e, 0x0f,
-	0x87, 0x36, 0xb4, 0xe2, 0x84, 0x4b, 0xde, 0x0b, 0x62, 0xda, 0xd5, 0x5f, 0x08, 0x86, 0x33, 0x1e,
-	0x4e, 0xc3, 0x49, 0x40, 0x99, 0x77, 0x34, 0xe6, 0x7c, 0x3c, 0x23, 0xaa, 0xda, 0x0b, 0x18, 0xe3,
-	0x32, 0x90, 0x94, 0x33, 0x91, 0x21, 0xbd, 0x67, 0xa6, 0xaa, 0xa3, 0x61, 0x3a, 0xea, 0x49, 0x3a,
-	0x27, 0x42, 0x06, 0xf3, 0x38, 0x03, 0xf8, 0xdf, 0x2d, 0xa8, 0x5d, 0x28, 0x36, 0xf4, 0x1a, 0x9c,
-	0xbc, 0xe8, 0x5a, 0x6d, 0xab, 0xb3, 0xdb, 0xf7, 0xba, 0x59, 0x7b, 0x77, 0xd9, 0xde, 0x1d, 0x2c,
-	0x11, 0xb8, 0x00, 0x23, 0x0f, 0x1a, 0x71, 0x42, 0xee, 0x27, 0x81, 0x98, 0xb8, 0x76, 0xdb, 0xea,
-	0xfc, 0x87, 0xf3, 0x18, 0x1d, 0x40, 0x8d, 0x71, 0x16, 0x12, 0xb7, 0xd2, 0xb6, 0x3a, 0x55, 0x9c,
-	0x05, 0xe8, 0x7f, 0xa8, 0xcb, 0x20, 0x19, 0x13, 0xe9, 0x56, 0x35, 0xde, 0x44, 0xe8, 0x04, 0x60,
-	0x4e, 0x92, 0xe9, 0x8c, 0x60, 0xce, 0xa5, 0x5b, 0xd3, 0xb5, 0x52, 0x06, 0xb5, 0xa1, 0x22, 0x17,
-	0xc2, 0xad, 0xb7, 0x2b, 0x9d, 0xdd, 0x7e, 0xb3, 0x5b, 0xd8, 0xd0, 0x1d, 0x2c, 0xb0, 0x2a, 0xf9,
-	0x7d, 0xa8, 0x5d, 0xaa, 0x04, 0x3a, 0x85, 0xba, 0x2e, 0x0b, 0xd7, 0xd2, 0xe8, 0xfd, 0x32, 0x5a,
-	0x9f, 0x18, 0x1b, 0x80, 0x7f, 0x0b, 0xf5, 0x0f, 0x3c, 0x22, 0xef, 0xaf, 0x94, 0xae, 0x38, 0x1d,
-	0x4e, 0xc9, 0x83, 0x36, 0xc0, 0xc1, 0x26, 0x42, 0x4d, 0xb0, 0x69, 0xa4, 0xcf, 0x56, 0xc3, 0x36,
-	0x8d, 0x94, 0xce, 0x84, 0xc8, 0x34, 0x61, 0xe7, 0x51, 0x94, 0xe8, 0xa3, 0x39, 0xb8, 0x94, 0xf1,
-	0x7f, 0x58, 0x60, 0x0f, 0x16, 0xff, 0x60, 0xe9, 0x01, 0xd4, 0xee, 0x83, 0x59, 0x4a, 0xf4, 0x4c,
-	0x0b, 0x67, 0x81, 0x92, 0x27, 0x08, 0x8b, 0xc8, 0x72, 0xa4, 0x89, 0xd0, 0x11, 0x38, 0x09, 0x09,
-	0x69, 0x4c, 0x09, 0xcb, 0x1c, 0x75, 0x70, 0x91, 0x40, 0x2e, 0xec, 0xcc, 0x89, 0x10, 0xc1, 0x98,
-	0x68, 0x47, 0x1d, 0xbc, 0x0c, 0x11, 0x82, 0xaa, 0xbe, 0xb4, 0xba, 0x36, 0x5a, 0x7f, 0x2b, 0xae,
-	0x8c, 0xf5, 0x86, 0x3c, 0xb8, 0x3b, 0x19, 0x57, 0x9e, 0xf0, 0xbf, 0x40, 0xeb, 0x8a, 0x8a, 0x90,
-	0xdf, 0x93, 0x04, 0x93, 0xaf, 0x29, 0x11, 0x12, 0xbd, 0x80, 0x3a, 0xd3, 0xee, 0x99, 0x13, 0xa2,
-	0xb2, 0xd1, 0x99, 0xaf, 0xd8, 0x20, 0x94, 0x6f, 0x53, 0xc6, 0xbf, 0x69, 0x93, 0x84, 0x6b, 0xb7,
-	0x2b, 0xca, 0xb7, 0x22, 0xe3, 0x33, 0xd8, 0x2b, 0xe8, 0x45, 0xcc, 0x99, 0x20, 0x7f, 0xc5, 0xdf,
-	0x04, 0x9b, 0x4f, 0xb5, 0x67, 0x0d, 0x6c, 0xf3, 0xe9, 0xda, 0xbc, 0xca, 0xc6, 0xbc, 0x37, 0xd0,
-	0x7a, 0x4b, 0xe4, 0x9d, 0x0c, 0x24, 0xd9, 0xe2, 0x38, 0xfe, 0x27, 0xd8, 0x2b, 0xda, 0x8d, 0xdc,
-	0xe7, 0x50, 0xd3, 0x58, 0xd3, 0xbe, 0xb2, 0x76, 0x7a, 0x33, 0x71, 0x56, 0x57, 0xda, 0x22, 0x3a,
-	0x1a, 0xd1, 0x30, 0x9d, 0xc9, 0x07, 0x73, 0xcf, 0xa5, 0x8c, 0x3f, 0x81, 0xfd, 0xbb, 0x49, 0x90,
-	0x90, 0xac, 0x69, 0x0b, 0xb3, 0x73, 0x25, 0xf6, 0xef, 0x95, 0xf8, 0x2f, 0x01, 0x95, 0x27, 0x99,
-	0x83, 0x78, 0xd0, 0x08, 0xc2, 0x90, 0xc4, 0x92, 0x44, 0x7a, 0x58, 0x03, 0xe7, 0xb1, 0xff, 0x19,
-	0x9a, 0xba, 0x63, 0xb0, 0xd8, 0x6e, 0x0b, 0x6c, 0xb9, 0x30, 0xaa, 0xd6, 0x1f, 0xb1, 0x2d, 0x17,
-	0xfe, 0x39, 0xb4, 0x72, 0xf6, 0x3f, 0x8b, 0x51, 0x5b, 0x4c, 0xd9, 0x88, 0x6b, 0x42, 0x07, 0xeb,
-	0x6f, 0xff, 0x56, 0xdf, 0xcc, 0x65, 0x42, 0x22, 0x2a, 0xb7, 0x91, 0xb8, 0x07, 0x15, 0xf5, 0x2b,
-	0x90, 0x51, 0xaa, 0x4f, 0xff, 0x14, 0xf6, 0x4b, 0x8c, 0x46, 0x56, 0xfe, 0x4c, 0xad, 0xd2, 0x33,
-	0xed, 0xff, 0xb4, 0xa1, 0xaa, 0xf8, 0xd0, 0x35, 0x34, 0x96, 0xeb, 0x8c, 0x0e, 0xcb, 0xd3, 0xd6,
-	0xde, 0x90, 0x77, 0xf4, 0x78, 0xd1, 0x4c, 0xb9, 0x86, 0xc6, 0x72, 0xcd, 0x56, 0x69, 0xd6, 0x76,
-	0x77, 0x95, 0x66, 0x63, 0x33, 0x6f, 0x00, 0x8a, 0x6b, 0x46, 0xc7, 0x65, 0xec, 0xc6, 0xa2, 0x79,
-	0x27, 0x4f, 0x95, 0x0d, 0xd9, 0x05, 0xec, 0x98, 0x3b, 0x42, 0xde, 0x06, 0x34, 0x5f, 0x0b, 0xef,
-	0xf0, 0xd1, 0x9a, 0xe1, 0x78, 0x07, 0x4e, 0x6e, 0x29, 0x5a, 0xd7, 0xbe, 0x72, 0x77, 0xde, 0xf1,
-	0x13, 0xd5, 0x8c, 0xe9, 0xe2, 0xec, 0xe3, 0xab, 0x31, 0x95, 0x93, 0x74, 0xd8, 0x0d, 0xf9, 0xbc,
-	0x17, 0x88, 0x71, 0x40, 0x19, 0x11, 0xbd, 0xa2, 0x27, 0xfb, 0x03, 0x1c, 0xf3, 0x52, 0x6a, 0x58,
-	0xd7, 0xb9, 0xb3, 0x5f, 0x01, 0x00, 0x00, 0xff, 0xff, 0x1b, 0xf4, 0x70, 0xdb, 0x5f, 0x07, 0x00,
-	0x00,
+	// 1893 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x58, 0xdb, 0x72, 0xe3, 0x48,
+	0x19, 0x46, 0xf2, 0x49, 0xfe, 0x73, 0x72, 0x7a, 0x97, 0x45, 0x68, 0x32, 0xc1, 0xd5, 0x45, 0xb1,
+	0x99, 0x01, 0x9c, 0xd9, 0x2c, 0x54, 0x4d, 0xb1, 0x30, 0x55, 0x93, 0xc3, 0xce, 0xcc, 0x4e, 0x4d,
+	0x12, 0x3a, 0x59, 0x4e, 0x05, 0x17, 0xb2, 0xdc, 0xb6, 0x55, 0xb1, 0x25, 0xa3, 0x6e, 0x65, 0x9d,
+	0x2b, 0x2e, 0x28, 0x5e, 0x82, 0x0b, 0x5e, 0x83, 0x37, 0xe0, 0x15, 0x28, 0xee, 0xa8, 0xe2, 0x82,
+	0xd7, 0xd8, 0xea, 0x83, 0xa4, 0x96, 0x6c, 0x6f, 0x76, 0x3c, 0x73, 0xa7, 0xff, 0xd0, 0x7f, 0xf7,
+	0xff, 0xfd, 0x47, 0x1b, 0x76, 0x66, 0x49, 0xcc, 0xe3, 0x43, 0x7f, 0x16, 0xf6, 0xe4, 0x17, 0x82,
+	0xfe, 0x24, 0x0e, 0x6e, 0x82, 0xb1, 0x1f, 0x46, 0xde, 0xde, 0x28, 0x8e, 0x47, 0x13, 0x2a, 0xa4,
+	0x87, 0x7e, 0x14, 0xc5, 0xdc, 0xe7, 0x61, 0x1c, 0x31, 0xa5, 0xe9, 0xfd, 0x40, 0x4b, 0x25, 0xd5,
+	0x4f, 0x87, 0x87, 0x3c, 0x9c, 0x52, 0xc6, 0xfd, 0xe9, 0x4c, 0x29, 0xe0, 0xff, 0x5b, 0xd0, 0x38,
+	0x16, 0xd6, 0xd0, 0x53, 0x68, 0xe7, 0x42, 0xd7, 0xea, 0x5a, 0x07, 0x1b, 0x47, 0x5e, 0x4f, 0x1d,
+	0xef, 0x65, 0xc7, 0x7b, 0xd7, 0x99, 0x06, 0x29, 0x94, 0x91, 0x07, 0xce, 0x2c, 0xa1, 0xb7, 0x63,
+	0x9f, 0x8d, 0x5d, 0xbb, 0x6b, 0x1d, 0x6c, 0x92, 0x9c, 0x46, 0x1f, 0x42, 0x23, 0x8a, 0xa3, 0x80,
+	0xba, 0xb5, 0xae, 0x75, 0x50, 0x27, 0x8a, 0x40, 0x1f, 0x41, 0x93, 0xfb, 0xc9, 0x88, 0x72, 0xb7,
+	0x2e, 0xf5, 0x35, 0x85, 0xf6, 0x01, 0xa6, 0x34, 0xb9, 0x99, 0x50, 0x12, 0xc7, 0xdc, 0x6d, 0x48,
+	0x99, 0xc1, 0x41, 0x5d, 0xa8, 0xf1, 0x39, 0x73, 0x9b, 0xdd, 0xda, 0xc1, 0xc6, 0xd1, 0x76, 0xaf,
+	0x80, 0xa1, 0x77, 0x3d, 0x27, 0x42, 0x84, 0x5c, 0x68, 0xdd, 0xd2, 0x84, 0x85, 0x71, 0xe4, 0xb6,
+	0xba, 0xd6, 0xc1, 0x16, 0xc9, 0x48, 0xfc, 0x3f, 0x0b, 0x36, 0xa4, 0xa7, 0x2f, 0xa9, 0x3f, 0xa0,
+	0x89, 0xa9, 0x69, 0x95, 0x34, 0xc5, 0xeb, 0xc6, 0x34, 0x1c, 0x8d, 0xb9, 0xf4, 0xa6, 0x4e, 0x34,
+	0x55, 0xf2, 0xb3, 0x56, 0xf1, 0xb3, 0xfc, 0xf2, 0xfa, 0xc2, 0xcb, 0x4b, 0xe8, 0x36, 0xde, 0x06,
+	0xdd, 0x02, 0xab, 0x66, 0x09, 0xab, 0x1c, 0xd9, 0x96, 0x81, 0x2c, 0x3e, 0x82, 0xc6, 0x89, 0x00,
+	0x04, 0x3d, 0x82, 0xa6, 0x84, 0x87, 0xb9, 0x96, 0x44, 0x6b, 0xd7, 0x44, 0x4b, 0xe2, 0x40, 0xb4,
+	0x02, 0xbe, 0x84, 0xe6, 0x79, 0x3c, 0xa0, 0xaf, 0x4e, 0xc5, 0x5d, 0xb3, 0xb4, 0x7f, 0x43, 0xef,
+	0x24, 0x24, 0x6d, 0xa2, 0x29, 0xb4, 0x0d, 0x76, 0x38, 0x90, 0x68, 0x34, 0x88, 0x1d, 0x0e, 0x84,
+	0xb7, 0x09, 0xe5, 0x69, 0x12, 0x3d, 0x1f, 0x0c, 0x12, 0x89, 0x45, 0x9b, 0x18, 0x1c, 0xfc, 0x9f,
+	0x3a, 0xd8, 0xd7, 0xf3, 0x77, 0x48, 0xa9, 0x2e, 0x6c, 0x4c, 0xe8, 0xc8, 0x0f, 0xee, 0x7e, 0xe3,
+	0x4f, 0x52, 0x2a, 0x6f, 0xb6, 0x88, 0xc9, 0x12, 0x4f, 0x65, 0x34, 0x1a, 0xd0, 0xec, 0x7a, 0x4d,
+	0xa1, 0x3d, 0x68, 0x27, 0x34, 0x08, 0x67, 0x21, 0x8d, 0x54, 0x1c, 0xda, 0xa4, 0x60, 0x88, 0xa0,
+	0x4f, 0x29, 0x63, 0xfe, 0x88, 0xca, 0x20, 0xb4, 0x49, 0x46, 0x22, 0x04, 0x75, 0x19, 0x58, 0x05,
+	0xb2, 0xfc, 0x16, 0xb6, 0x58, 0x38, 0x8a, 0x7c, 0x9e, 0x26, 0xd4, 0x75, 0xa4, 0xa0, 0x60, 0xa0,
+	0x27, 0xe0, 0x4c, 0xd3, 0x09, 0x0f, 0x59, 0x38, 0x72, 0xdb, 0xd2, 0xb9, 0x0f, 0x4d, 0x8c, 0xdf,
+	0x68, 0x19, 0xc9, 0xb5, 0xd0, 0xcf, 0x01, 0xf2, 0xe3, 0xcc, 0x05, 0x19, 0x97, 0xef, 0x9a, 0x67,
+	0xae, 0x32, 0x29, 0x31, 0x14, 0x8b, 0x48, 0x6f, 0x98, 0x35, 0xb4, 0x07, 0x6d, 0x85, 0xc7, 0xe7,
+	0x94, 0xba, 0x9b, 0x12, 0xa0, 0x82, 0x21, 0xce, 0xdc, 0x4a, 0xe8, 0xb6, 0xd4, 0x19, 0x49, 0xa0,
+	0x0e, 0xd4, 0x86, 0x94, 0xba, 0xdb, 0x92, 0x27, 0x3e, 0xd1, 0x4f, 0xa0, 0x19, 0x46, 0xb3, 0x94,
+	0x33, 0x77, 0xa7, 0x5b, 0xab, 0xba, 0x70, 0x91, 0xf2, 0xcb, 0x38, 0x8c, 0x38, 0xd1, 0x3a, 0xe8,
+	0xc7, 0xd0, 0x8a, 0x53, 0x2e, 0xd5, 0x3b, 0x8b, 0x59, 0x75, 0x3d, 0xbf, 0x48, 0x39, 0xc9, 0x34,
+	0x04, 0xa2, 0x42, 0xe6, 0xee, 0xca, 0xdb, 0xe4, 0xb7, 0x51, 0x5a, 0xa8, 0x54, 0x5a, 0x46, 0x31,
+	0x7e, 0x50, 0x2a, 0xc6, 0x2f, 0xea, 0x4e, 0xab, 0xe3, 0x90, 0xb6, 0x8a, 0xee, 0x6b, 0x7a, 0x87,
+	0x9f, 0x82, 0x93, 0xbd, 0x4b, 0xd6, 0xc6, 0xfc, 0xa5, 0x08, 0x9b, 0xa5, 0x6b, 0x43, 0x52, 0xc2,
+	0xfb, 0x30, 0x1a, 0xd0, 0xb9, 0x4c, 0x9c, 0x2d, 0xa2, 0x08, 0xfc, 0x33, 0x80, 0x37, 0xb2, 0x22,
+	0xaf, 0x38, 0x9d, 0xe5, 0x01, 0xb7, 0x8c, 0x80, 0x8b, 0x27, 0xd3, 0xa1, 0xaa, 0x7b, 0x87, 0xc8,
+	0x6f, 0xfc, 0x19, 0x34, 0xa4, 0x63, 0xe5, 0xcc, 0xb2, 0xaa, 0x99, 0x95, 0x03, 0x6e, 0x1b, 0x80,
+	0xe3, 0xbf, 0x5b, 0xd0, 0xfa, 0x32, 0x62, 0x33, 0xa1, 0xf1, 0x04, 0x9c, 0x58, 0x3f, 0x5c, 0x17,
+	0xc3, 0x72, 0xb0, 0x73, 0x2d, 0x51, 0xc3, 0x0a, 0x4c, 0x69, 0x74, 0x29, 0xda, 0x5a, 0xc1, 0x00,
+	0xb6, 0x56, 0xed, 0x59, 0x41, 0x1c, 0x46, 0x7d, 0x9f, 0x51, 0x59, 0x0d, 0x0e, 0xc9, 0x69, 0x7c,
+	0x0c, 0x4e, 0x96, 0xa4, 0xc2, 0x39, 0x3e, 0x4e, 0x28, 0x1b, 0xc7, 0x93, 0x81, 0xee, 0x87, 0x05,
+	0x43, 0x84, 0x47, 0x75, 0x02, 0xe6, 0xda, 0xdd, 0xda, 0xc1, 0x26, 0xc9, 0x48, 0xfc, 0x1c, 0xda,
+	0x79, 0xd2, 0x56, 0xda, 0xc7, 0x66, 0xde, 0x3e, 0x4a, 0x75, 0x64, 0x57, 0xea, 0x08, 0xff, 0xcb,
+	0x02, 0xe7, 0xd8, 0x9f, 0xf8, 0x51, 0x40, 0x99, 0xe1, 0x87, 0x55, 0xf2, 0xa3, 0x03, 0x35, 0x1e,
+	0xce, 0xf4, 0x61, 0xf1, 0x89, 0x9e, 0x81, 0xd3, 0xd7, 0xa7, 0xdc, 0x9a, 0x4c, 0x46, 0x5c, 0x6a,
+	0x71, 0x5a, 0x96, 0x7f, 0x9c, 0x45, 0x3c, 0xb9, 0x23, 0xf9, 0x19, 0xef, 0x12, 0xb6, 0x4a, 0x22,
+	0x71, 0x45, 0xd1, 0xf9, 0xc4, 0x27, 0x7a, 0x64, 0xc6, 0x74, 0xe3, 0xe8, 0x83, 0x25, 0xf6, 0x75,
+	0xa0, 0x7f, 0x61, 0x3f, 0xb5, 0xf0, 0x05, 0xb4, 0x34, 0x57, 0x00, 0x16, 0x24, 0x74, 0x10, 0x72,
+	0xa6, 0xfd, 0xc8, 0x48, 0xe1, 0xe0, 0x80, 0xf6, 0x85, 0x40, 0x0f, 0x17, 0x45, 0x89, 0xd4, 0x63,
+	0x34, 0xca, 0xc2, 0x27, 0xbf, 0xf1, 0x9f, 0x60, 0xe7, 0x34, 0x64, 0x41, 0x7c, 0x4b, 0x13, 0x42,
+	0xff, 0x9c, 0x52, 0xc6, 0xd1, 0x63, 0x68, 0x46, 0xb2, 0x57, 0xeb, 0x14, 0x42, 0xe6, 0x9b, 0x54,
+	0x17, 0x27, 0x5a, 0x43, 0x74, 0xe9, 0x9b, 0x28, 0xfe, 0x4a, 0xb6, 0x64, 0x15, 0xb8, 0x36, 0x31,
+	0x38, 0xf8, 0x1f, 0x16, 0x74, 0x0a, 0xfb, 0x6c, 0x16, 0x47, 0x8c, 0xbe, 0xd5, 0x05, 0xdb, 0x60,
+	0xc7, 0x37, 0xba, 0x58, 0xec, 0xf8, 0xa6, 0x72, 0x61, 0xad, 0x7a, 0x21, 0xea, 0x41, 0x5d, 0xb4,
+	0x78, 0xb7, 0x7e, 0xef, 0x28, 0x90, 0x7a, 0xf8, 0x57, 0xb0, 0xf3, 0x82, 0xf2, 0x2b, 0xee, 0x73,
+	0xba, 0x86, 0xff, 0xf8, 0x0e, 0x3a, 0xc5, 0x71, 0xed, 0xde, 0xc7, 0xd0, 0x90, 0xba, 0xae, 0xb5,
+	0x58, 0x51, 0x72, 0x70, 0x12, 0x25, 0x17, 0xbe, 0x0c, 0xc2, 0xe1, 0x30, 0x0c, 0xd2, 0x09, 0xbf,
+	0xd3, 0x03, 0xc8, 0xe0, 0x88, 0x9c, 0x96, 0x8a, 0x5f, 0xc5, 0xc9, 0x8d, 0xde, 0x06, 0x0a, 0x06,
+	0x1e, 0xc3, 0xee, 0xd5, 0xd8, 0x4f, 0xa8, 0x32, 0xb9, 0x46, 0xec, 0xf2, 0x77, 0xda, 0xdf, 0xfc,
+	0x4e, 0xfc, 0x04, 0x90, 0x79, 0x93, 0x76, 0xd3, 0x03, 0xc7, 0x0f, 0x02, 0x3a, 0xe3, 0x54, 0x55,
+	0xb3, 0x43, 0x72, 0x1a, 0xff, 0x11, 0xb6, 0xe5, 0x89, 0xeb, 0xf9, 0x7a, 0x49, 0x65, 0xf3, 0xb9,
+	0x7e, 0x55, 0x75, 0x03, 0xb3, 0xf9, 0x1c, 0x3f, 0x87, 0x9d, 0xdc, 0xfa, 0xfd, 0x8f, 0x11, 0x69,
+	0x1f, 0x46, 0xc3, 0x58, 0x1a, 0x6c, 0x13, 0xf9, 0x8d, 0x7f, 0x27, 0xe3, 0x76, 0x22, 0x0b, 0x66,
+	0x9d, 0x27, 0xba, 0xd0, 0xf2, 0x07, 0x83, 0x84, 0x32, 0xa6, 0xcd, 0x66, 0x24, 0xf6, 0x61, 0xd7,
+	0xb0, 0xac, 0x9f, 0x97, 0x77, 0xee, 0x9a, 0x39, 0x2a, 0xf3, 0xa1, 0x6b, 0x9b, 0x43, 0xd7, 0x03,
+	0x27, 0x9c, 0x4e, 0x55, 0x23, 0xab, 0x4b, 0x41, 0x4e, 0x7f, 0x51, 0x77, 0xac, 0x8e, 0x8d, 0x7f,
+	0x2f, 0xaf, 0xd0, 0x3d, 0xff, 0xfd, 0xbe, 0xfe, 0x04, 0x90, 0x69, 0x5a, 0x3f, 0xff, 0xa7, 0xd0,
+	0x4a, 0x15, 0x4b, 0x6f, 0x7a, 0xa5, 0x36, 0x95, 0x69, 0x67, 0x3a, 0xf8, 0x1c, 0x36, 0x5f, 0x50,
+	0xbe, 0x5e, 0xec, 0xb3, 0x91, 0x69, 0x17, 0x23, 0x13, 0xff, 0xcd, 0x86, 0x2d, 0x6d, 0x50, 0x3f,
+	0x48, 0x65, 0x88, 0xb5, 0x2a, 0x43, 0xd0, 0x53, 0x68, 0x32, 0xee, 0xf3, 0x54, 0xf9, 0xb7, 0x7d,
+	0xd4, 0x35, 0x75, 0x4a, 0xa6, 0x7a, 0x57, 0x52, 0x8f, 0x68, 0x7d, 0x51, 0x73, 0x52, 0xf5, 0x65,
+	0xb1, 0x81, 0x17, 0x0c, 0x63, 0x74, 0xd4, 0x4b, 0xa3, 0xe3, 0x87, 0xb0, 0x15, 0xc4, 0xd1, 0x30,
+	0x4c, 0xa6, 0xea, 0xa7, 0x91, 0xdc, 0xfc, 0xea, 0xa4, 0xcc, 0xc4, 0xcf, 0xa0, 0xa9, 0x6e, 0x43,
+	0x1b, 0xd0, 0xfa, 0xf2, 0xfc, 0xf5, 0xf9, 0xc5, 0x6f, 0xcf, 0x3b, 0xdf, 0x11, 0xc4, 0xe5, 0xd9,
+	0xf9, 0xe9, 0xab, 0xf3, 0x17, 0x1d, 0x0b, 0x6d, 0x41, 0xfb, 0xe4, 0xe2, 0xfc, 0xf3, 0x57, 0xe4,
+	0xcd, 0xd9, 0x69, 0xc7, 0x16, 0xb2, 0x53, 0x72, 0x71, 0x79, 0x79, 0x76, 0xda, 0xa9, 0xe1, 0x67,
+	0xaa, 0xd9, 0xa4, 0xb3, 0xd9, 0xe4, 0x6e, 0x9d, 0x66, 0xf5, 0x4f, 0x0b, 0x76, 0x0d, 0x03, 0x1a,
+	0xcb, 0x55, 0xe3, 0xb0, 0x0b, 0x1b, 0x41, 0x98, 0x04, 0xe9, 0xc4, 0xe7, 0x61, 0x34, 0xd2, 0x39,
+	0x6a, 0xb2, 0x04, 0x56, 0x53, 0x7f, 0xae, 0xcc, 0xe9, 0xcc, 0x2e, 0x18, 0xe2, 0x7c, 0x44, 0xe7,
+	0xfc, 0x2a, 0xed, 0xb3, 0x70, 0x70, 0xa7, 0x01, 0x33, 0x59, 0xe8, 0x00, 0x76, 0xc6, 0xfe, 0xe4,
+	0x36, 0x8c, 0x46, 0xaf, 0x22, 0x4e, 0x93, 0x5b, 0x7f, 0xa2, 0x71, 0xab, 0xb2, 0xf1, 0x95, 0x7c,
+	0xf8, 0xf5, 0xfc, 0x32, 0x89, 0xe3, 0xe1, 0xfb, 0x4a, 0xab, 0x7f, 0x5b, 0x80, 0x4c, 0xab, 0xdf,
+	0x32, 0xb7, 0x1e, 0x09, 0xbc, 0xc4, 0xcf, 0xbb, 0x65, 0x7d, 0x53, 0xff, 0xea, 0x51, 0x0a, 0x6b,
+	0x26, 0x53, 0xbe, 0x59, 0x36, 0x8c, 0xcd, 0x12, 0x3d, 0x86, 0xfa, 0xcc, 0xe7, 0x63, 0xfd, 0xc3,
+	0xf4, 0xa3, 0xd2, 0xcf, 0x80, 0x7c, 0xe3, 0x24, 0x52, 0x47, 0xc3, 0xa5, 0x7e, 0x84, 0xb2, 0x35,
+	0xe1, 0x1a, 0x26, 0xf1, 0x54, 0x07, 0x5d, 0x7e, 0xe3, 0xbf, 0x00, 0x32, 0x8d, 0x6a, 0xb4, 0x3e,
+	0x81, 0x96, 0x72, 0x36, 0xfb, 0x11, 0xf8, 0xbd, 0x05, 0x38, 0xd4, 0x11, 0x92, 0xe9, 0xad, 0xfc,
+	0xed, 0x5b, 0x1e, 0x87, 0xb5, 0xea, 0x38, 0xc4, 0x63, 0x99, 0xfe, 0xd2, 0xe4, 0xfb, 0x72, 0x4a,
+	0x60, 0x1d, 0xc4, 0xa9, 0xde, 0x89, 0xb6, 0x88, 0x22, 0xf0, 0x33, 0xd8, 0x35, 0x6e, 0xd2, 0x9e,
+	0xbe, 0xc5, 0xaf, 0xdd, 0x6c, 0x34, 0x2b, 0xae, 0x7e, 0xea, 0xc7, 0xd0, 0x90, 0xe2, 0x65, 0x6b,
+	0x81, 0x52, 0x54, 0x72, 0xc3, 0x27, 0xfb, 0xde, 0x92, 0x3e, 0xd5, 0xa3, 0x59, 0xdf, 0xb4, 0xe6,
+	0x34, 0xfc, 0xb5, 0x5c, 0x82, 0x4a, 0xaf, 0x7d, 0xd7, 0xe2, 0xfa, 0xac, 0x08, 0x96, 0xb9, 0x18,
+	0x7d, 0x2b, 0x04, 0x8c, 0xa5, 0x6c, 0x9d, 0x40, 0xe3, 0xff, 0x5a, 0xf9, 0x56, 0xc6, 0xee, 0x6d,
+	0x73, 0xfb, 0x00, 0x3c, 0xa1, 0x0a, 0xc0, 0x6c, 0x61, 0x36, 0x38, 0x62, 0x56, 0xc6, 0xc9, 0x6c,
+	0xec, 0x47, 0x4c, 0xb7, 0xb8, 0x8c, 0x44, 0x8f, 0xa1, 0xa3, 0x3f, 0x4f, 0xe2, 0x28, 0xa2, 0x81,
+	0x40, 0x5b, 0x55, 0xf2, 0x02, 0x1f, 0xfd, 0x08, 0xb6, 0x35, 0xef, 0xec, 0x36, 0x94, 0x9a, 0xaa,
+	0xd3, 0x55, 0xb8, 0xa6, 0xde, 0x7c, 0x16, 0x26, 0x74, 0xe0, 0x36, 0xcb, 0x7a, 0x8a, 0x7b, 0xf4,
+	0x57, 0x07, 0xea, 0xc2, 0x6b, 0x74, 0x06, 0x4e, 0xb6, 0x5f, 0xa3, 0x07, 0x26, 0x26, 0x95, 0xad,
+	0xde, 0xdb, 0x5b, 0x2e, 0xd4, 0xe8, 0x9c, 0x81, 0x93, 0xed, 0xb1, 0x65, 0x33, 0x95, 0xe5, 0xd8,
+	0xdb, 0x5b, 0x2e, 0xd4, 0x66, 0x5e, 0x03, 0x14, 0x9b, 0x22, 0x7a, 0x68, 0xea, 0x2e, 0xec, 0xaa,
+	0xde, 0xfe, 0x2a, 0xb1, 0x36, 0x76, 0x0c, 0x2d, 0xbd, 0xe6, 0x21, 0x6f, 0x41, 0x35, 0xdf, 0x2e,
+	0xbc, 0x07, 0x4b, 0x65, 0xda, 0xc6, 0x4b, 0x68, 0xe7, 0xdb, 0x18, 0xaa, 0xbe, 0xbd, 0xb4, 0xfe,
+	0x79, 0x0f, 0x57, 0x48, 0x0b, 0xd7, 0x8a, 0xcd, 0x08, 0x55, 0x95, 0xcb, 0xcb, 0x98, 0xb7, 0xbf,
+	0x4a, 0xac, 0x8d, 0xfd, 0x12, 0x1a, 0x72, 0xf2, 0x20, 0x77, 0xc9, 0x62, 0xa2, 0x4c, 0x7c, 0x7f,
+	0xe5, 0xca, 0xa2, 0x9d, 0xd2, 0x63, 0x76, 0x21, 0x20, 0xe6, 0x7a, 0xe0, 0x3d, 0x5c, 0x21, 0x2d,
+	0x39, 0xa5, 0x27, 0xe0, 0x82, 0x53, 0xe5, 0x79, 0xeb, 0xed, 0xaf, 0x12, 0x97, 0x8c, 0xe9, 0x01,
+	0xb1, 0x60, 0xac, 0x3c, 0x8d, 0xbc, 0xfd, 0x55, 0xe2, 0x92, 0x8f, 0xba, 0x06, 0xab, 0x3e, 0x96,
+	0x66, 0x80, 0xf7, 0x70, 0x85, 0xb4, 0x92, 0x93, 0x92, 0xbd, 0x24, 0x27, 0xcd, 0xb6, 0xe7, 0xed,
+	0xaf, 0x12, 0x97, 0xea, 0x44, 0x99, 0x7a, 0xb0, 0xec, 0xde, 0x55, 0x75, 0xb2, 0xcc, 0x8c, 0x6c,
+	0x50, 0x4b, 0xcb, 0x8d, 0x7d, 0x53, 0xb9, 0xe5, 0xae, 0x1d, 0x7f, 0xfa, 0x87, 0x4f, 0x46, 0x21,
+	0x1f, 0xa7, 0xfd, 0x5e, 0x10, 0x4f, 0x0f, 0x7d, 0x36, 0xf2, 0xc3, 0x88, 0xb2, 0xc3, 0xe2, 0x88,
+	0xfa, 0x53, 0x7e, 0x14, 0x1b, 0xac, 0x7e, 0x53, 0xf2, 0x3e, 0xfd, 0x7a, 0x00, 0x0a, 0x5a, 0x6e,
+	0xe9, 0xf3, 0x17, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -846,6 +2647,15 @@ type NodeClient interface {
 	ShareChain(ctx context.Context, in *ShareChainRequest, opts ...grpc.CallOption) (*ShareChainResponse, error)
 	ShareTx(ctx context.Context, in *ShareTxRequest, opts ...grpc.CallOption) (*ShareTxResponse, error)
 	GetCredit(ctx context.Context, in *GetCreditRequest, opts ...grpc.CallOption) (*GetCreditResponse, error)
+	GetUnspent(ctx context.Context, in *GetUnspentRequest, opts ...grpc.CallOption) (*GetUnspentResponse, error)
+	GetTx(ctx context.Context, in *GetTxRequest, opts ...grpc.CallOption) (*GetTxResponse, error)
+	GetSupply(ctx context.Context, in *GetSupplyRequest, opts ...grpc.CallOption) (*GetSupplyResponse, error)
+	GetTxProof(ctx context.Context, in *GetTxProofRequest, opts ...grpc.CallOption) (*GetTxProofResponse, error)
+	GetHeaders(ctx context.Context, in *GetHeadersRequest, opts ...grpc.CallOption) (*GetHeadersResponse, error)
+	GetBlocks(ctx context.Context, in *GetBlocksRequest, opts ...grpc.CallOption) (*GetBlocksResponse, error)
+	ShareBlock(ctx context.Context, in *ShareBlockRequest, opts ...grpc.CallOption) (*ShareBlockResponse, error)
+	GetBlock(ctx context.Context, in *GetBlockRequest, opts ...grpc.CallOption) (*GetBlockResponse, error)
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
 }
 
 type nodeClient struct {
@@ -901,6 +2711,87 @@ func (c *nodeClient) GetCredit(ctx context.Context, in *GetCreditRequest, opts .
 	return out, nil
 }
 
+func (c *nodeClient) GetUnspent(ctx context.Context, in *GetUnspentRequest, opts ...grpc.CallOption) (*GetUnspentResponse, error) {
+	out := new(GetUnspentResponse)
+	err := c.cc.Invoke(ctx, "/blockchain.Node/GetUnspent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetTx(ctx context.Context, in *GetTxRequest, opts ...grpc.CallOption) (*GetTxResponse, error) {
+	out := new(GetTxResponse)
+	err := c.cc.Invoke(ctx, "/blockchain.Node/GetTx", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetSupply(ctx context.Context, in *GetSupplyRequest, opts ...grpc.CallOption) (*GetSupplyResponse, error) {
+	out := new(GetSupplyResponse)
+	err := c.cc.Invoke(ctx, "/blockchain.Node/GetSupply", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetTxProof(ctx context.Context, in *GetTxProofRequest, opts ...grpc.CallOption) (*GetTxProofResponse, error) {
+	out := new(GetTxProofResponse)
+	err := c.cc.Invoke(ctx, "/blockchain.Node/GetTxProof", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetHeaders(ctx context.Context, in *GetHeadersRequest, opts ...grpc.CallOption) (*GetHeadersResponse, error) {
+	out := new(GetHeadersResponse)
+	err := c.cc.Invoke(ctx, "/blockchain.Node/GetHeaders", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetBlocks(ctx context.Context, in *GetBlocksRequest, opts ...grpc.CallOption) (*GetBlocksResponse, error) {
+	out := new(GetBlocksResponse)
+	err := c.cc.Invoke(ctx, "/blockchain.Node/GetBlocks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) ShareBlock(ctx context.Context, in *ShareBlockRequest, opts ...grpc.CallOption) (*ShareBlockResponse, error) {
+	out := new(ShareBlockResponse)
+	err := c.cc.Invoke(ctx, "/blockchain.Node/ShareBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetBlock(ctx context.Context, in *GetBlockRequest, opts ...grpc.CallOption) (*GetBlockResponse, error) {
+	out := new(GetBlockResponse)
+	err := c.cc.Invoke(ctx, "/blockchain.Node/GetBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error) {
+	out := new(GetStatsResponse)
+	err := c.cc.Invoke(ctx, "/blockchain.Node/GetStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // NodeServer is the server API for Node service.
 type NodeServer interface {
 	Discover(context.Context, *DiscoverRequest) (*DiscoverResponse, error)
@@ -908,6 +2799,15 @@ type NodeServer interface {
 	ShareChain(context.Context, *ShareChainRequest) (*ShareChainResponse, error)
 	ShareTx(context.Context, *ShareTxRequest) (*ShareTxResponse, error)
 	GetCredit(context.Context, *GetCreditRequest) (*GetCreditResponse, error)
+	GetUnspent(context.Context, *GetUnspentRequest) (*GetUnspentResponse, error)
+	GetTx(context.Context, *GetTxRequest) (*GetTxResponse, error)
+	GetSupply(context.Context, *GetSupplyRequest) (*GetSupplyResponse, error)
+	GetTxProof(context.Context, *GetTxProofRequest) (*GetTxProofResponse, error)
+	GetHeaders(context.Context, *GetHeadersRequest) (*GetHeadersResponse, error)
+	GetBlocks(context.Context, *GetBlocksRequest) (*GetBlocksResponse, error)
+	ShareBlock(context.Context, *ShareBlockRequest) (*ShareBlockResponse, error)
+	GetBlock(context.Context, *GetBlockRequest) (*GetBlockResponse, error)
+	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
 }
 
 // UnimplementedNodeServer can be embedded to have forward compatible implementations.
@@ -929,6 +2829,33 @@ func (*UnimplementedNodeServer) ShareTx(ctx context.Context, req *ShareTxRequest
 func (*UnimplementedNodeServer) GetCredit(ctx context.Context, req *GetCreditRequest) (*GetCreditResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetCredit not implemented")
 }
+func (*UnimplementedNodeServer) GetUnspent(ctx context.Context, req *GetUnspentRequest) (*GetUnspentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUnspent not implemented")
+}
+func (*UnimplementedNodeServer) GetTx(ctx context.Context, req *GetTxRequest) (*GetTxResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTx not implemented")
+}
+func (*UnimplementedNodeServer) GetSupply(ctx context.Context, req *GetSupplyRequest) (*GetSupplyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSupply not implemented")
+}
+func (*UnimplementedNodeServer) GetTxProof(ctx context.Context, req *GetTxProofRequest) (*GetTxProofResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTxProof not implemented")
+}
+func (*UnimplementedNodeServer) GetHeaders(ctx context.Context, req *GetHeadersRequest) (*GetHeadersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHeaders not implemented")
+}
+func (*UnimplementedNodeServer) GetBlocks(ctx context.Context, req *GetBlocksRequest) (*GetBlocksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlocks not implemented")
+}
+func (*UnimplementedNodeServer) ShareBlock(ctx context.Context, req *ShareBlockRequest) (*ShareBlockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShareBlock not implemented")
+}
+func (*UnimplementedNodeServer) GetBlock(ctx context.Context, req *GetBlockRequest) (*GetBlockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlock not implemented")
+}
+func (*UnimplementedNodeServer) GetStats(ctx context.Context, req *GetStatsRequest) (*GetStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
 
 func RegisterNodeServer(s *grpc.Server, srv NodeServer) {
 	s.RegisterService(&_Node_serviceDesc, srv)
@@ -1024,6 +2951,168 @@ func _Node_GetCredit_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Node_GetUnspent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUnspentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetUnspent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blockchain.Node/GetUnspent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetUnspent(ctx, req.(*GetUnspentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_GetTx_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetTx(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blockchain.Node/GetTx",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetTx(ctx, req.(*GetTxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_GetSupply_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSupplyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetSupply(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blockchain.Node/GetSupply",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetSupply(ctx, req.(*GetSupplyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_GetTxProof_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTxProofRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetTxProof(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blockchain.Node/GetTxProof",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetTxProof(ctx, req.(*GetTxProofRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_GetHeaders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHeadersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetHeaders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blockchain.Node/GetHeaders",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetHeaders(ctx, req.(*GetHeadersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_GetBlocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlocksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetBlocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blockchain.Node/GetBlocks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetBlocks(ctx, req.(*GetBlocksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_ShareBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShareBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).ShareBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blockchain.Node/ShareBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).ShareBlock(ctx, req.(*ShareBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_GetBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blockchain.Node/GetBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetBlock(ctx, req.(*GetBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blockchain.Node/GetStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Node_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "blockchain.Node",
 	HandlerType: (*NodeServer)(nil),
@@ -1048,6 +3137,42 @@ var _Node_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetCredit",
 			Handler:    _Node_GetCredit_Handler,
 		},
+		{
+			MethodName: "GetUnspent",
+			Handler:    _Node_GetUnspent_Handler,
+		},
+		{
+			MethodName: "GetTx",
+			Handler:    _Node_GetTx_Handler,
+		},
+		{
+			MethodName: "GetSupply",
+			Handler:    _Node_GetSupply_Handler,
+		},
+		{
+			MethodName: "GetTxProof",
+			Handler:    _Node_GetTxProof_Handler,
+		},
+		{
+			MethodName: "GetHeaders",
+			Handler:    _Node_GetHeaders_Handler,
+		},
+		{
+			MethodName: "GetBlocks",
+			Handler:    _Node_GetBlocks_Handler,
+		},
+		{
+			MethodName: "ShareBlock",
+			Handler:    _Node_ShareBlock_Handler,
+		},
+		{
+			MethodName: "GetBlock",
+			Handler:    _Node_GetBlock_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _Node_GetStats_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/api.proto",
@@ -0,0 +1,98 @@
+package transactions
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/golang/protobuf/ptypes"
+)
+
+// ErrLegacyMismatch is returned when the base unit amounts of a legacy tx do not
+// match the whole coin amounts it was signed with
+var ErrLegacyMismatch = errors.New("amounts do not match legacy amounts")
+
+// ErrLegacyTx is returned for a tx of legacy form outside the blocks of a
+// chain stored before amounts were counted in base units
+var ErrLegacyTx = errors.New("legacy amounts are only accepted in migrated chains")
+
+// IsLegacy reports whether the tx was made before amounts were counted in base
+// units. Legacy txs keep hashing their whole coin amounts, so their original
+// signatures stay valid after migration.
+func IsLegacy(tx *pb.Tx) bool {
+	return tx.GetLegacyValue() != 0 || tx.GetLegacyFee() != 0
+}
+
+// legacyCoins formats a whole coin amount as the legacy hash covers it, to the
+// millionth of a coin
+func legacyCoins(coins float64) string {
+	return fmt.Sprintf("%f", coins)
+}
+
+// legacyAmount is the amount in base units of a whole coin amount as its
+// legacy hash covers it. Digits beyond those hashed are dropped, so nobody can
+// nudge the amount of a signed legacy tx by them.
+func legacyAmount(coins float64) uint64 {
+	hashed, err := strconv.ParseFloat(legacyCoins(coins), 64)
+	if err != nil {
+		return 0
+	}
+
+	return ToBaseUnits(hashed)
+}
+
+// ToBaseUnits converts an amount in whole coins to base units, rounding to the
+// nearest unit
+func ToBaseUnits(coins float64) uint64 {
+	return uint64(math.Round(coins * float64(Coin)))
+}
+
+// Migrate sets the base unit amounts of a legacy tx from its whole coin amounts.
+// It reports whether the tx needed migrating.
+func Migrate(tx *pb.Tx) bool {
+	if !IsLegacy(tx) || CheckLegacy(tx) == nil {
+		return false
+	}
+
+	tx.Value = legacyAmount(tx.GetLegacyValue())
+	tx.Fee = legacyAmount(tx.GetLegacyFee())
+
+	return true
+}
+
+// CheckLegacy ensures the base unit amounts of a legacy tx are those of the
+// whole coin amounts as covered by its hash. Non-legacy txs always pass.
+func CheckLegacy(tx *pb.Tx) error {
+	if !IsLegacy(tx) {
+		return nil
+	}
+
+	for _, coins := range []float64{tx.GetLegacyValue(), tx.GetLegacyFee()} {
+		if coins < 0 || math.IsNaN(coins) || math.IsInf(coins, 0) {
+			return fmt.Errorf("%w: invalid legacy amount %v", ErrLegacyMismatch, coins)
+		}
+	}
+
+	if tx.GetValue() != legacyAmount(tx.GetLegacyValue()) || tx.GetFee() != legacyAmount(tx.GetLegacyFee()) {
+		return ErrLegacyMismatch
+	}
+
+	return nil
+}
+
+// legacyHash is the hash of txs from before amounts were counted in base units
+func legacyHash(tx *pb.Tx) []byte {
+	payload := legacyCoins(tx.GetLegacyValue())
+	payload += fmt.Sprintf("%020d", tx.GetNonce())
+	payload += legacyCoins(tx.GetLegacyFee())
+	payload += ptypes.TimestampString(tx.GetTimestamp())
+	payload += tx.GetSender()
+	payload += tx.GetRecipient()
+	payload += tx.GetMessage()
+
+	h := sha256.Sum256([]byte(payload))
+	return h[:]
+}
@@ -0,0 +1,107 @@
+package transactions
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/asgaines/blockchain/address"
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+func TestMigrate(t *testing.T) {
+	priv, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A tx signed before amounts were counted in base units
+	newLegacyTx := func() *pb.Tx {
+		tx := &pb.Tx{
+			Timestamp: &timestamp.Timestamp{
+				Seconds: 646459200,
+			},
+			LegacyValue: 12.5,
+			LegacyFee:   0.25,
+			Recipient:   Address(other, address.Mainnet),
+		}
+		Sign(tx, priv, address.Mainnet)
+		return tx
+	}
+
+	cases := []struct {
+		name     string
+		tx       func() *pb.Tx
+		expected error
+	}{
+		{
+			name: "A migrated legacy tx keeps its signature valid",
+			tx: func() *pb.Tx {
+				tx := newLegacyTx()
+				Migrate(tx)
+				return tx
+			},
+			expected: nil,
+		},
+		{
+			name: "A legacy tx whose base unit value disagrees with its legacy value is not valid",
+			tx: func() *pb.Tx {
+				tx := newLegacyTx()
+				Migrate(tx)
+				tx.Value *= 2
+				return tx
+			},
+			expected: ErrLegacyMismatch,
+		},
+		{
+			name: "A legacy value nudged below the digits its hash covers cannot change the amount",
+			tx: func() *pb.Tx {
+				tx := newLegacyTx()
+				tx.LegacyValue += 0.0000004
+				tx.Value = ToBaseUnits(tx.LegacyValue)
+				tx.Fee = ToBaseUnits(tx.LegacyFee)
+				return tx
+			},
+			expected: ErrLegacyMismatch,
+		},
+		{
+			name:     "An unmigrated legacy tx is not valid",
+			tx:       newLegacyTx,
+			expected: ErrLegacyMismatch,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := Verify(c.tx(), address.Mainnet)
+
+			if !errors.Is(err, c.expected) {
+				t.Errorf("expected %v, got %v", c.expected, err)
+			}
+		})
+	}
+}
+
+func TestToBaseUnits(t *testing.T) {
+	cases := []struct {
+		coins    float64
+		expected uint64
+	}{
+		{coins: 0, expected: 0},
+		{coins: 1, expected: Coin},
+		{coins: 0.1, expected: 10000000},
+		{coins: 100, expected: 100 * Coin},
+		{coins: 0.00000001, expected: 1},
+	}
+
+	for _, c := range cases {
+		if got := ToBaseUnits(c.coins); got != c.expected {
+			t.Errorf("%v coins: expected %v, got %v", c.coins, c.expected, got)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package transactions
+
+import (
+	"time"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+)
+
+// LockThreshold divides the locks of txs: below it a lock is a block height,
+// at or above it a unix time in seconds
+const LockThreshold uint64 = 500000000
+
+// IsMature reports whether the tx can be included in the block at the height,
+// timestamped at t
+func IsMature(tx *pb.Tx, height uint64, t time.Time) bool {
+	lock := tx.GetLock()
+
+	switch {
+	case lock == 0:
+		return true
+	case lock < LockThreshold:
+		return height >= lock
+	default:
+		return t.Unix() >= 0 && uint64(t.Unix()) >= lock
+	}
+}
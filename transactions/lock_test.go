@@ -0,0 +1,83 @@
+package transactions
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+)
+
+func TestIsMature(t *testing.T) {
+	unlockTime := time.Date(2019, 5, 26, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		lock     uint64
+		height   uint64
+		t        time.Time
+		expected bool
+	}{
+		{
+			name:     "An unlocked tx is always mature",
+			lock:     0,
+			height:   0,
+			t:        time.Unix(0, 0),
+			expected: true,
+		},
+		{
+			name:     "A tx locked to a height is not mature before it",
+			lock:     10,
+			height:   9,
+			t:        unlockTime,
+			expected: false,
+		},
+		{
+			name:     "A tx locked to a height is mature at it",
+			lock:     10,
+			height:   10,
+			t:        unlockTime,
+			expected: true,
+		},
+		{
+			name:     "A lock below the threshold is a height, however late the time",
+			lock:     LockThreshold - 1,
+			height:   10,
+			t:        time.Unix(int64(LockThreshold), 0),
+			expected: false,
+		},
+		{
+			name:     "A tx locked to a time is not mature before it",
+			lock:     uint64(unlockTime.Unix()),
+			height:   LockThreshold,
+			t:        unlockTime.Add(-time.Second),
+			expected: false,
+		},
+		{
+			name:     "A tx locked to a time is mature at it",
+			lock:     uint64(unlockTime.Unix()),
+			height:   0,
+			t:        unlockTime,
+			expected: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := IsMature(&pb.Tx{Lock: c.lock}, c.height, c.t)
+
+			if got != c.expected {
+				t.Errorf("expected %v, got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestHashCoversLock(t *testing.T) {
+	tx := &pb.Tx{Sender: "Maeby", Value: 1}
+	unlocked := Hash(tx)
+
+	tx.Lock = 10
+	if string(Hash(tx)) == string(unlocked) {
+		t.Errorf("expected lock to change the hash, so it cannot be stripped")
+	}
+}
@@ -0,0 +1,127 @@
+package transactions
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	"github.com/asgaines/blockchain/address"
+	"github.com/asgaines/blockchain/canonical"
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+)
+
+var (
+	// ErrMultisigMismatch is returned when the keys carried by a tx are not
+	// those committed to by its multisig sender address
+	ErrMultisigMismatch = errors.New("tx multisig does not match sender address")
+	// ErrInsufficientSignatures is returned when fewer than the threshold of
+	// multisig keys have validly signed a tx
+	ErrInsufficientSignatures = errors.New("not enough valid multisig signatures")
+)
+
+// ProposeMultisig makes the multisig account the sender of the tx, sets the
+// version to that of the current encoding and hashes it, ready to be passed around its key holders for signing
+func ProposeMultisig(tx *pb.Tx, ms *pb.Multisig, net *address.Network) error {
+	sender, err := address.FromMultisig(int(ms.GetThreshold()), toPubkeys(ms), net)
+	if err != nil {
+		return err
+	}
+
+	tx.Sender = sender
+	tx.Multisig = ms
+	tx.Signatures = nil
+	tx.Version = canonical.TxVersion
+	SetHash(tx)
+
+	return nil
+}
+
+// SignMultisig adds a signature over the tx hash by one of the multisig keys
+func SignMultisig(tx *pb.Tx, priv ed25519.PrivateKey) error {
+	if !bytes.Equal(tx.GetHash(), Hash(tx)) {
+		return ErrHashMismatch
+	}
+
+	pubkey := priv.Public().(ed25519.PublicKey)
+	if !containsKey(tx.GetMultisig(), pubkey) {
+		return errors.New("key is not part of the tx multisig")
+	}
+
+	return AddSignature(tx, &pb.Signature{
+		Pubkey:    pubkey,
+		Signature: ed25519.Sign(priv, tx.GetHash()),
+	})
+}
+
+// AddSignature merges a signature collected from a multisig key holder into
+// the tx. A signature from a key which already signed replaces the previous one.
+func AddSignature(tx *pb.Tx, sig *pb.Signature) error {
+	if !containsKey(tx.GetMultisig(), sig.GetPubkey()) {
+		return fmt.Errorf("signing key %x is not part of the tx multisig", sig.GetPubkey())
+	}
+
+	for i, existing := range tx.GetSignatures() {
+		if bytes.Equal(existing.GetPubkey(), sig.GetPubkey()) {
+			tx.Signatures[i] = sig
+			return nil
+		}
+	}
+
+	tx.Signatures = append(tx.Signatures, sig)
+
+	return nil
+}
+
+func verifyMultisig(tx *pb.Tx, sender address.Address) error {
+	ms := tx.GetMultisig()
+
+	hash, err := address.MultisigHash(int(ms.GetThreshold()), toPubkeys(ms))
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrMultisigMismatch, err)
+	}
+
+	if !bytes.Equal(hash, sender.Payload) {
+		return ErrMultisigMismatch
+	}
+
+	signed := make(map[string]bool, len(tx.GetSignatures()))
+	for _, sig := range tx.GetSignatures() {
+		if !containsKey(ms, sig.GetPubkey()) {
+			continue
+		}
+
+		if ed25519.Verify(sig.GetPubkey(), tx.GetHash(), sig.GetSignature()) {
+			signed[string(sig.GetPubkey())] = true
+		}
+	}
+
+	if uint32(len(signed)) < ms.GetThreshold() {
+		return fmt.Errorf("%w: %d of %d", ErrInsufficientSignatures, len(signed), ms.GetThreshold())
+	}
+
+	return nil
+}
+
+func containsKey(ms *pb.Multisig, pubkey []byte) bool {
+	if len(pubkey) != ed25519.PublicKeySize {
+		return false
+	}
+
+	for _, k := range ms.GetPubkeys() {
+		if bytes.Equal(k, pubkey) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func toPubkeys(ms *pb.Multisig) []ed25519.PublicKey {
+	pubkeys := make([]ed25519.PublicKey, 0, len(ms.GetPubkeys()))
+	for _, k := range ms.GetPubkeys() {
+		pubkeys = append(pubkeys, k)
+	}
+
+	return pubkeys
+}
@@ -0,0 +1,175 @@
+package transactions
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/asgaines/blockchain/address"
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+func TestVerifyMultisig(t *testing.T) {
+	keys := make([]ed25519.PrivateKey, 4)
+	for i := range keys {
+		priv, err := GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = priv
+	}
+	outsider := keys[3]
+
+	// 2-of-3 between the first three keys
+	newProposal := func() *pb.Tx {
+		tx := &pb.Tx{
+			Timestamp: &timestamp.Timestamp{
+				Seconds: 646459200,
+			},
+			Value:     50,
+			Recipient: Address(outsider, address.Mainnet),
+		}
+
+		ms := &pb.Multisig{
+			Threshold: 2,
+			Pubkeys: [][]byte{
+				keys[0].Public().(ed25519.PublicKey),
+				keys[1].Public().(ed25519.PublicKey),
+				keys[2].Public().(ed25519.PublicKey),
+			},
+		}
+
+		if err := ProposeMultisig(tx, ms, address.Mainnet); err != nil {
+			t.Fatal(err)
+		}
+
+		return tx
+	}
+
+	sign := func(tx *pb.Tx, priv ed25519.PrivateKey) {
+		if err := SignMultisig(tx, priv); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		name     string
+		tx       func() *pb.Tx
+		expected error
+	}{
+		{
+			name: "Threshold of distinct signatures is valid",
+			tx: func() *pb.Tx {
+				tx := newProposal()
+				sign(tx, keys[0])
+				sign(tx, keys[2])
+				return tx
+			},
+			expected: nil,
+		},
+		{
+			name: "All signatures is valid",
+			tx: func() *pb.Tx {
+				tx := newProposal()
+				sign(tx, keys[0])
+				sign(tx, keys[1])
+				sign(tx, keys[2])
+				return tx
+			},
+			expected: nil,
+		},
+		{
+			name: "A single signature is insufficient",
+			tx: func() *pb.Tx {
+				tx := newProposal()
+				sign(tx, keys[1])
+				return tx
+			},
+			expected: ErrInsufficientSignatures,
+		},
+		{
+			name: "Repeating one key's signature does not count twice",
+			tx: func() *pb.Tx {
+				tx := newProposal()
+				sign(tx, keys[1])
+				tx.Signatures = append(tx.Signatures, tx.Signatures[0])
+				return tx
+			},
+			expected: ErrInsufficientSignatures,
+		},
+		{
+			name: "Signatures by keys outside the multisig are not counted",
+			tx: func() *pb.Tx {
+				tx := newProposal()
+				sign(tx, keys[1])
+				tx.Signatures = append(tx.Signatures, &pb.Signature{
+					Pubkey:    outsider.Public().(ed25519.PublicKey),
+					Signature: ed25519.Sign(outsider, tx.GetHash()),
+				})
+				return tx
+			},
+			expected: ErrInsufficientSignatures,
+		},
+		{
+			name: "Lowering the threshold after the fact does not match the address",
+			tx: func() *pb.Tx {
+				tx := newProposal()
+				sign(tx, keys[1])
+				tx.Multisig.Threshold = 1
+				return tx
+			},
+			expected: ErrMultisigMismatch,
+		},
+		{
+			name: "A missing multisig does not match the address",
+			tx: func() *pb.Tx {
+				tx := newProposal()
+				sign(tx, keys[0])
+				sign(tx, keys[1])
+				tx.Multisig = nil
+				return tx
+			},
+			expected: ErrMultisigMismatch,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := Verify(c.tx(), address.Mainnet)
+
+			if !errors.Is(got, c.expected) {
+				t.Errorf("expected %v, got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestSignMultisigOutsider(t *testing.T) {
+	member, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outsider, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := &pb.Tx{
+		Recipient: Address(outsider, address.Mainnet),
+	}
+
+	ms := &pb.Multisig{
+		Threshold: 1,
+		Pubkeys:   [][]byte{member.Public().(ed25519.PublicKey)},
+	}
+
+	if err := ProposeMultisig(tx, ms, address.Mainnet); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SignMultisig(tx, outsider); err == nil {
+		t.Error("expected error signing with a key outside the multisig")
+	}
+}
@@ -0,0 +1,117 @@
+package transactions
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/asgaines/blockchain/address"
+	"github.com/asgaines/blockchain/canonical"
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+)
+
+var (
+	// ErrHashMismatch is returned when the hash carried by a tx does not match
+	// the hash of its contents
+	ErrHashMismatch = errors.New("tx hash does not match its contents")
+	// ErrBadSignature is returned when a tx signature was not produced by the
+	// sender's private key
+	ErrBadSignature = errors.New("tx signature does not match sender")
+)
+
+// GenerateKey creates a new Ed25519 keypair for signing transactions
+func GenerateKey() (ed25519.PrivateKey, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	return priv, err
+}
+
+// KeyFromHex decodes a hex-encoded Ed25519 seed into a private key
+func KeyFromHex(s string) (ed25519.PrivateKey, error) {
+	seed, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode key: %w", err)
+	}
+
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", ed25519.SeedSize, len(seed))
+	}
+
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// KeyToHex encodes the seed of a private key as hex, the inverse of KeyFromHex
+func KeyToHex(priv ed25519.PrivateKey) string {
+	return hex.EncodeToString(priv.Seed())
+}
+
+// Pubkey returns the hex-encoded public key belonging to a private key
+func Pubkey(priv ed25519.PrivateKey) string {
+	return hex.EncodeToString(priv.Public().(ed25519.PublicKey))
+}
+
+// Address returns the address belonging to a private key on a network.
+// This is the value used as the sender and recipient of transactions.
+func Address(priv ed25519.PrivateKey, net *address.Network) string {
+	return address.FromPubkey(priv.Public().(ed25519.PublicKey), net)
+}
+
+// Sign sets the sender of the tx to the address of the private key and the
+// version to that of the current encoding, then hashes and signs it
+func Sign(tx *pb.Tx, priv ed25519.PrivateKey, net *address.Network) {
+	tx.Sender = Address(priv, net)
+	tx.Version = canonical.TxVersion
+	SetHash(tx)
+	tx.Signature = ed25519.Sign(priv, tx.GetHash())
+}
+
+// Verify checks that the sender and recipient, or the recipients of its outputs,
+// are well-formed addresses on the network, that the tx hash covers its contents
+// and that the signature over that hash was made by the sender
+func Verify(tx *pb.Tx, net *address.Network) error {
+	sender, err := address.Validate(tx.GetSender(), net)
+	if err != nil {
+		return fmt.Errorf("sender: %w", err)
+	}
+
+	if len(tx.GetOutputs()) == 0 {
+		if _, err := address.Validate(tx.GetRecipient(), net); err != nil {
+			return fmt.Errorf("recipient: %w", err)
+		}
+	}
+
+	for i, out := range tx.GetOutputs() {
+		if _, err := address.Validate(out.GetRecipient(), net); err != nil {
+			return fmt.Errorf("output %d recipient: %w", i, err)
+		}
+	}
+
+	if tx.GetVersion() > canonical.TxVersion {
+		return fmt.Errorf("tx version %d: %w", tx.GetVersion(), canonical.ErrUnknownVersion)
+	}
+
+	if !bytes.Equal(tx.GetHash(), Hash(tx)) {
+		return ErrHashMismatch
+	}
+
+	if err := CheckLegacy(tx); err != nil {
+		return err
+	}
+
+	if sender.Version == address.VersionMultisig {
+		return verifyMultisig(tx, sender)
+	}
+
+	pubkey, err := sender.Pubkey()
+	if err != nil {
+		return fmt.Errorf("sender: %w", err)
+	}
+
+	if !ed25519.Verify(pubkey, tx.GetHash(), tx.GetSignature()) {
+		return ErrBadSignature
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package transactions
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/asgaines/blockchain/address"
+	"github.com/asgaines/blockchain/canonical"
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+func TestVerify(t *testing.T) {
+	priv, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newTx := func() *pb.Tx {
+		return &pb.Tx{
+			Timestamp: &timestamp.Timestamp{
+				Seconds: 646459200,
+			},
+			Value:     1250000000,
+			Recipient: Address(other, address.Mainnet),
+			Message:   "There's always money in the banana stand",
+		}
+	}
+
+	cases := []struct {
+		name     string
+		tx       func() *pb.Tx
+		expected error
+	}{
+		{
+			name: "A tx signed by the sender is valid",
+			tx: func() *pb.Tx {
+				tx := newTx()
+				Sign(tx, priv, address.Mainnet)
+				return tx
+			},
+			expected: nil,
+		},
+		{
+			name: "A tx with its value changed after signing has a mismatched hash",
+			tx: func() *pb.Tx {
+				tx := newTx()
+				Sign(tx, priv, address.Mainnet)
+				tx.Value = 1000
+				return tx
+			},
+			expected: ErrHashMismatch,
+		},
+		{
+			name: "A tx rehashed after changing its value no longer matches the signature",
+			tx: func() *pb.Tx {
+				tx := newTx()
+				Sign(tx, priv, address.Mainnet)
+				tx.Value = 1000
+				SetHash(tx)
+				return tx
+			},
+			expected: ErrBadSignature,
+		},
+		{
+			name: "A tx to an address on another network is invalid",
+			tx: func() *pb.Tx {
+				tx := newTx()
+				tx.Recipient = Address(other, address.Testnet)
+				Sign(tx, priv, address.Mainnet)
+				return tx
+			},
+			expected: address.ErrWrongNetwork,
+		},
+		{
+			name: "A tx claiming to be from someone other than the signer is invalid",
+			tx: func() *pb.Tx {
+				tx := newTx()
+				Sign(tx, priv, address.Mainnet)
+				tx.Sender = Address(other, address.Mainnet)
+				SetHash(tx)
+				return tx
+			},
+			expected: ErrBadSignature,
+		},
+		{
+			name: "A tx signed before the canonical encoding is still valid",
+			tx: func() *pb.Tx {
+				tx := newTx()
+				Sign(tx, priv, address.Mainnet)
+				tx.Version = 0
+				SetHash(tx)
+				tx.Signature = ed25519.Sign(priv, tx.GetHash())
+				return tx
+			},
+			expected: nil,
+		},
+		{
+			name: "A tx of an unknown encoding version is invalid",
+			tx: func() *pb.Tx {
+				tx := newTx()
+				Sign(tx, priv, address.Mainnet)
+				tx.Version = canonical.TxVersion + 1
+				return tx
+			},
+			expected: canonical.ErrUnknownVersion,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := Verify(c.tx(), address.Mainnet)
+
+			if !errors.Is(got, c.expected) {
+				t.Errorf("expected %v, got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestVerifyMalformedSender(t *testing.T) {
+	tx := &pb.Tx{
+		Sender: "GeorgeSenior",
+	}
+
+	if err := Verify(tx, address.Mainnet); err == nil {
+		t.Error("expected error for sender that is not an address")
+	}
+}
+
+func TestKeyFromHex(t *testing.T) {
+	priv, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := KeyFromHex(KeyToHex(priv))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if Pubkey(got) != Pubkey(priv) {
+		t.Errorf("expected %s, got %s", Pubkey(priv), Pubkey(got))
+	}
+
+	if _, err := KeyFromHex("abc123"); err == nil {
+		t.Error("expected error for short key")
+	}
+}
@@ -2,20 +2,86 @@ package transactions
 
 import (
 	"crypto/sha256"
+	"errors"
 	"fmt"
 
+	"github.com/asgaines/blockchain/canonical"
 	pb "github.com/asgaines/blockchain/protogo/blockchain"
 	"github.com/golang/protobuf/ptypes"
 )
 
-func SetHash(tx *pb.Tx) {
-	payload := fmt.Sprintf("%f", tx.GetValue())
+// ErrUnversionedTx is returned for a tx of version 0 outside the blocks of a
+// stored chain. Its hash is of fields concatenated without separators, which
+// the canonical encoding replaced.
+var ErrUnversionedTx = errors.New("version 0 txs are only accepted in stored chains")
+
+// Coin is the number of base units making up one whole coin. All amounts are
+// counted in base units; whole coins are only for display.
+const Coin uint64 = 100000000
+
+// Hash computes the sha256 hash of the canonical encoding of the tx. The
+// signature is excluded, as it is made over this hash. Txs of version 0 keep
+// the hash they were signed with before the encoding existed, and txs of an
+// unknown version have no hash.
+func Hash(tx *pb.Tx) []byte {
+	if IsLegacy(tx) {
+		return legacyHash(tx)
+	}
+
+	if tx.GetVersion() == 0 {
+		return unversionedHash(tx)
+	}
+
+	payload, err := canonical.EncodeTx(tx)
+	if err != nil {
+		return nil
+	}
+
+	h := sha256.Sum256(payload)
+	return h[:]
+}
+
+// unversionedHash is the hash of txs from before the canonical encoding. Its
+// fields are concatenated without separators, so it is kept only to verify
+// the txs already signed with it.
+func unversionedHash(tx *pb.Tx) []byte {
+	payload := fmt.Sprintf("%020d", tx.GetValue())
+	payload += fmt.Sprintf("%020d", tx.GetNonce())
+	payload += fmt.Sprintf("%020d", tx.GetFee())
 	payload += ptypes.TimestampString(tx.GetTimestamp())
 	payload += tx.GetSender()
 	payload += tx.GetRecipient()
 	payload += tx.GetMessage()
 
+	// Account txs have neither inputs nor outputs, so keep the hash they had
+	// before the UTXO ledger existed
+	if len(tx.GetInputs()) > 0 || len(tx.GetOutputs()) > 0 {
+		payload += fmt.Sprintf("%05d", len(tx.GetInputs()))
+		for _, in := range tx.GetInputs() {
+			payload += fmt.Sprintf("%03d%x%010d", len(in.GetTxHash()), in.GetTxHash(), in.GetIndex())
+		}
+
+		payload += fmt.Sprintf("%05d", len(tx.GetOutputs()))
+		for _, out := range tx.GetOutputs() {
+			payload += fmt.Sprintf("%03d%s%020d", len(out.GetRecipient()), out.GetRecipient(), out.GetValue())
+		}
+	}
+
+	// Likewise, unlocked txs keep the hash they had before locks existed
+	if tx.GetLock() > 0 {
+		payload += fmt.Sprintf("%020d", tx.GetLock())
+	}
+
+	// Only block solve rewards carry a height
+	if tx.GetHeight() > 0 {
+		payload += fmt.Sprintf("h%020d", tx.GetHeight())
+	}
+
 	h := sha256.New()
 	h.Write([]byte(payload))
-	tx.Hash = h.Sum(nil)
+	return h.Sum(nil)
+}
+
+func SetHash(tx *pb.Tx) {
+	tx.Hash = Hash(tx)
 }
@@ -0,0 +1,54 @@
+package transactions
+
+import (
+	"encoding/hex"
+	"testing"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+func TestHash(t *testing.T) {
+	newTx := func(version uint32) *pb.Tx {
+		return &pb.Tx{
+			Version:   version,
+			Timestamp: &timestamp.Timestamp{Seconds: 646459200},
+			Sender:    "ab",
+			Recipient: "c",
+			Value:     100000000,
+			Fee:       2,
+			Nonce:     3,
+			Message:   "Her?",
+		}
+	}
+
+	cases := []struct {
+		name     string
+		tx       *pb.Tx
+		expected string
+	}{
+		{
+			name:     "A tx of version 0 keeps the hash of its concatenated fields",
+			tx:       newTx(0),
+			expected: "ddd3504faec749b47ab038cdc74326fe20beaedb77b88eb1dae073dca8161f31",
+		},
+		{
+			name:     "A tx of version 1 is hashed over its canonical encoding",
+			tx:       newTx(1),
+			expected: "eb7ba91e10b517fb6914caee285b77f135b528e5cf4989fe786dc9b2c5a165bc",
+		},
+		{
+			name:     "A tx of an unknown version has no hash",
+			tx:       newTx(2),
+			expected: "",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := hex.EncodeToString(Hash(c.tx)); got != c.expected {
+				t.Errorf("expected %s, got %s", c.expected, got)
+			}
+		})
+	}
+}
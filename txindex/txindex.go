@@ -0,0 +1,113 @@
+package txindex
+
+import (
+	"sync"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+)
+
+// DefaultMaxSeen is the number of txs seen pending which are remembered when
+// no other limit is given
+const DefaultMaxSeen = 10000
+
+// Index locates the txs of the chain by their hash, so they can be looked up
+// without rescanning every block. It also remembers the txs most recently seen
+// pending, so that a tx which has since been dropped can be told apart from
+// one never seen. It is safe for concurrent use.
+type Index interface {
+	// Height returns the height of the block including the tx with the hash
+	Height(hash []byte) (uint64, bool)
+	// AddBlock indexes the txs of the block at the height
+	AddBlock(block *pb.Block, height uint64)
+	// RemoveBlock forgets the txs of the block at the height, once orphaned
+	RemoveBlock(block *pb.Block, height uint64)
+	// Seen remembers a tx which has been pending
+	Seen(tx *pb.Tx)
+	// Recall returns the tx with the hash, if remembered as seen pending
+	Recall(hash []byte) (*pb.Tx, bool)
+	// Len returns the number of txs indexed in the chain
+	Len() int
+}
+
+// New instantiates an empty Index, remembering up to maxSeen txs seen pending
+func New(maxSeen int) Index {
+	idx := index{
+		heights: make(map[string]uint64),
+		seen:    make(map[string]*pb.Tx),
+		maxSeen: maxSeen,
+	}
+
+	return &idx
+}
+
+type index struct {
+	heights map[string]uint64
+	seen    map[string]*pb.Tx
+	// order holds the hashes of seen txs, oldest first, so the oldest can be
+	// forgotten once maxSeen are remembered
+	order   []string
+	maxSeen int
+	mu      sync.RWMutex
+}
+
+func (idx *index) Height(hash []byte) (uint64, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	height, ok := idx.heights[string(hash)]
+	return height, ok
+}
+
+func (idx *index) AddBlock(block *pb.Block, height uint64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for _, tx := range block.GetTxs() {
+		idx.heights[string(tx.GetHash())] = height
+	}
+}
+
+func (idx *index) RemoveBlock(block *pb.Block, height uint64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	// A tx also included in another block stays indexed at that one
+	for _, tx := range block.GetTxs() {
+		if h, ok := idx.heights[string(tx.GetHash())]; ok && h == height {
+			delete(idx.heights, string(tx.GetHash()))
+		}
+	}
+}
+
+func (idx *index) Seen(tx *pb.Tx) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	key := string(tx.GetHash())
+	if _, ok := idx.seen[key]; ok || idx.maxSeen <= 0 {
+		return
+	}
+
+	if len(idx.order) >= idx.maxSeen {
+		delete(idx.seen, idx.order[0])
+		idx.order = idx.order[1:]
+	}
+
+	idx.seen[key] = tx
+	idx.order = append(idx.order, key)
+}
+
+func (idx *index) Recall(hash []byte) (*pb.Tx, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	tx, ok := idx.seen[string(hash)]
+	return tx, ok
+}
+
+func (idx *index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return len(idx.heights)
+}
@@ -0,0 +1,97 @@
+package txindex
+
+import (
+	"testing"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+)
+
+func TestBlocks(t *testing.T) {
+	idx := New(DefaultMaxSeen)
+
+	reward := &pb.Tx{Hash: []byte("reward")}
+	spend := &pb.Tx{Hash: []byte("spend")}
+
+	first := &pb.Block{Txs: []*pb.Tx{reward}}
+	second := &pb.Block{Txs: []*pb.Tx{reward, spend}}
+
+	idx.AddBlock(first, 1)
+	idx.AddBlock(second, 2)
+
+	if height, ok := idx.Height(spend.GetHash()); !ok || height != 2 {
+		t.Errorf("expected tx at height 2, got %d (%v)", height, ok)
+	}
+
+	if idx.Len() != 2 {
+		t.Errorf("expected 2 txs indexed, got %d", idx.Len())
+	}
+
+	idx.RemoveBlock(first, 1)
+
+	if height, ok := idx.Height(reward.GetHash()); !ok || height != 2 {
+		t.Errorf("expected tx also in a later block to stay indexed, got %d (%v)", height, ok)
+	}
+
+	idx.RemoveBlock(second, 2)
+
+	if _, ok := idx.Height(spend.GetHash()); ok || idx.Len() != 0 {
+		t.Errorf("expected txs of orphaned blocks to be forgotten")
+	}
+}
+
+func TestSeen(t *testing.T) {
+	cases := []struct {
+		name     string
+		maxSeen  int
+		seen     []string
+		recalled []string
+		forgot   []string
+	}{
+		{
+			name:     "Txs seen are recalled",
+			maxSeen:  2,
+			seen:     []string{"Ann", "Egg"},
+			recalled: []string{"Ann", "Egg"},
+		},
+		{
+			name:     "The oldest tx seen is forgotten beyond the limit",
+			maxSeen:  2,
+			seen:     []string{"Ann", "Egg", "Her?"},
+			recalled: []string{"Egg", "Her?"},
+			forgot:   []string{"Ann"},
+		},
+		{
+			name:     "Seeing a tx again does not count against the limit",
+			maxSeen:  2,
+			seen:     []string{"Ann", "Ann", "Egg"},
+			recalled: []string{"Ann", "Egg"},
+		},
+		{
+			name:    "No txs are remembered without a limit",
+			maxSeen: 0,
+			seen:    []string{"Ann"},
+			forgot:  []string{"Ann"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			idx := New(c.maxSeen)
+			for _, hash := range c.seen {
+				idx.Seen(&pb.Tx{Hash: []byte(hash)})
+			}
+
+			for _, hash := range c.recalled {
+				if tx, ok := idx.Recall([]byte(hash)); !ok || string(tx.GetHash()) != hash {
+					t.Errorf("expected %s to be recalled", hash)
+				}
+			}
+
+			for _, hash := range c.forgot {
+				if _, ok := idx.Recall([]byte(hash)); ok {
+					t.Errorf("expected %s to be forgotten", hash)
+				}
+			}
+		})
+	}
+}
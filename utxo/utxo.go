@@ -0,0 +1,323 @@
+package utxo
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+)
+
+var (
+	// ErrNoInputs is returned for a tx which spends nothing
+	ErrNoInputs = errors.New("tx spends no inputs")
+	// ErrNoOutputs is returned for a tx which creates nothing
+	ErrNoOutputs = errors.New("tx creates no outputs")
+	// ErrAccountFields is returned for a tx setting the value or recipient of
+	// the account ledger, which outputs take the place of
+	ErrAccountFields = errors.New("tx sets account value or recipient")
+	// ErrMissingInput is returned when an input is unknown or already spent
+	ErrMissingInput = errors.New("input is unknown or already spent")
+	// ErrDuplicateInput is returned when a tx spends the same output twice
+	ErrDuplicateInput = errors.New("input spent twice by tx")
+	// ErrNotOwner is returned when an input is not owned by the tx sender
+	ErrNotOwner = errors.New("input is not owned by the sender")
+	// ErrZeroOutput is returned for an output holding no value
+	ErrZeroOutput = errors.New("output has no value")
+	// ErrValueMismatch is returned when the inputs of a tx do not add up to
+	// its outputs plus its fee
+	ErrValueMismatch = errors.New("inputs do not equal outputs plus fee")
+	// ErrOverflow is returned when the amounts of a tx cannot be summed
+	ErrOverflow = errors.New("tx amounts overflow")
+	// ErrImmature is returned when an input is the output of a block solve
+	// reward paid too few blocks ago to be spent
+	ErrImmature = errors.New("input is an immature block solve reward")
+)
+
+// Set indexes the outputs not yet spent in the chain, so that a tx spending
+// them can be checked without rescanning every block. It is safe for
+// concurrent use.
+type Set interface {
+	// Get returns the unspent output the outpoint refers to
+	Get(op *pb.OutPoint) (*pb.Unspent, bool)
+	// ForAddress returns the unspent outputs owned by the address, oldest first
+	ForAddress(addr string) []*pb.Unspent
+	// Balance returns the total value of the unspent outputs of the address
+	Balance(addr string) uint64
+	// CheckTx ensures the tx spends only unspent, mature outputs of its sender
+	// when included in the block at the height, and that they add up to its
+	// outputs plus fee. The set is left unchanged.
+	CheckTx(tx *pb.Tx, height uint64) error
+	// ApplyBlock spends the inputs and adds the outputs of every tx in the
+	// block, in order. Nothing is changed should any tx be invalid.
+	ApplyBlock(block *pb.Block, height uint64) (*Undo, error)
+	// UndoBlock reverts the changes of the block applied last
+	UndoBlock(undo *Undo)
+	// Len returns the number of unspent outputs
+	Len() int
+}
+
+// Undo records the changes made by applying a block, so they can be reverted
+// when the block is orphaned
+type Undo struct {
+	spent   []*pb.Unspent
+	created []string
+}
+
+// New instantiates an empty Set, in which the outputs of block solve rewards
+// can only be spent coinbaseMaturity blocks after the one paying them
+func New(coinbaseMaturity uint64) Set {
+	s := set{
+		unspent:          make(map[string]*pb.Unspent),
+		coinbaseMaturity: coinbaseMaturity,
+	}
+
+	return &s
+}
+
+type set struct {
+	unspent          map[string]*pb.Unspent
+	coinbaseMaturity uint64
+	mu               sync.RWMutex
+}
+
+// Key identifies the output the outpoint refers to
+func Key(op *pb.OutPoint) string {
+	return fmt.Sprintf("%x:%d", op.GetTxHash(), op.GetIndex())
+}
+
+// IsCoinbase reports whether the tx is a block solve reward, creating outputs
+// from thin air
+func IsCoinbase(tx *pb.Tx) bool {
+	return tx.GetSender() == ""
+}
+
+// OutputsTotal sums the value of the outputs of the tx
+func OutputsTotal(tx *pb.Tx) (uint64, error) {
+	var total uint64
+	for _, out := range tx.GetOutputs() {
+		if total+out.GetValue() < total {
+			return 0, ErrOverflow
+		}
+		total += out.GetValue()
+	}
+
+	return total, nil
+}
+
+func (s *set) Get(op *pb.OutPoint) (*pb.Unspent, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.unspent[Key(op)]
+	return u, ok
+}
+
+func (s *set) ForAddress(addr string) []*pb.Unspent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	owned := make([]*pb.Unspent, 0)
+	for _, u := range s.unspent {
+		if u.GetOutput().GetRecipient() == addr {
+			owned = append(owned, u)
+		}
+	}
+
+	sort.Slice(owned, func(i, j int) bool {
+		if owned[i].GetHeight() != owned[j].GetHeight() {
+			return owned[i].GetHeight() < owned[j].GetHeight()
+		}
+		return Key(owned[i].GetOutPoint()) < Key(owned[j].GetOutPoint())
+	})
+
+	return owned
+}
+
+func (s *set) Balance(addr string) uint64 {
+	var balance uint64
+	for _, u := range s.ForAddress(addr) {
+		balance += u.GetOutput().GetValue()
+	}
+
+	return balance
+}
+
+func (s *set) CheckTx(tx *pb.Tx, height uint64) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.checkTx(tx, height)
+}
+
+func (s *set) checkTx(tx *pb.Tx, height uint64) error {
+	if tx.GetValue() != 0 || tx.GetRecipient() != "" {
+		return ErrAccountFields
+	}
+
+	if len(tx.GetInputs()) == 0 {
+		return ErrNoInputs
+	}
+
+	if len(tx.GetOutputs()) == 0 {
+		return ErrNoOutputs
+	}
+
+	var in uint64
+	seen := make(map[string]bool, len(tx.GetInputs()))
+
+	for i, op := range tx.GetInputs() {
+		key := Key(op)
+		if seen[key] {
+			return fmt.Errorf("input %d: %w", i, ErrDuplicateInput)
+		}
+		seen[key] = true
+
+		u, ok := s.unspent[key]
+		if !ok {
+			return fmt.Errorf("input %d: %w", i, ErrMissingInput)
+		}
+
+		if u.GetOutput().GetRecipient() != tx.GetSender() {
+			return fmt.Errorf("input %d: %w", i, ErrNotOwner)
+		}
+
+		if u.GetCoinbase() && (height < u.GetHeight() || height-u.GetHeight() < s.coinbaseMaturity) {
+			return fmt.Errorf("input %d: %w: paid at height %d, spendable from %d", i, ErrImmature, u.GetHeight(), u.GetHeight()+s.coinbaseMaturity)
+		}
+
+		if in+u.GetOutput().GetValue() < in {
+			return ErrOverflow
+		}
+		in += u.GetOutput().GetValue()
+	}
+
+	if err := checkOutputs(tx); err != nil {
+		return err
+	}
+
+	out, err := OutputsTotal(tx)
+	if err != nil {
+		return err
+	}
+
+	if out+tx.GetFee() < out {
+		return ErrOverflow
+	}
+
+	if in != out+tx.GetFee() {
+		return fmt.Errorf("%w: %d in, %d out, %d fee", ErrValueMismatch, in, out, tx.GetFee())
+	}
+
+	return nil
+}
+
+func checkOutputs(tx *pb.Tx) error {
+	for i, out := range tx.GetOutputs() {
+		if out.GetValue() == 0 {
+			return fmt.Errorf("output %d: %w", i, ErrZeroOutput)
+		}
+	}
+
+	return nil
+}
+
+func (s *set) ApplyBlock(block *pb.Block, height uint64) (*Undo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	undo := &Undo{}
+
+	for _, tx := range block.GetTxs() {
+		if IsCoinbase(tx) {
+			if err := checkCoinbase(tx); err != nil {
+				s.undo(undo)
+				return nil, fmt.Errorf("tx %x: %w", tx.GetHash(), err)
+			}
+		} else if err := s.checkTx(tx, height); err != nil {
+			s.undo(undo)
+			return nil, fmt.Errorf("tx %x: %w", tx.GetHash(), err)
+		}
+
+		for _, op := range tx.GetInputs() {
+			key := Key(op)
+			undo.spent = append(undo.spent, s.unspent[key])
+			delete(s.unspent, key)
+		}
+
+		for i, out := range tx.GetOutputs() {
+			u := &pb.Unspent{
+				OutPoint: &pb.OutPoint{
+					TxHash: tx.GetHash(),
+					Index:  uint32(i),
+				},
+				Output:   out,
+				Height:   height,
+				Coinbase: IsCoinbase(tx),
+			}
+
+			key := Key(u.GetOutPoint())
+			if _, ok := s.unspent[key]; ok {
+				s.undo(undo)
+				return nil, fmt.Errorf("tx %x: output %d already exists", tx.GetHash(), i)
+			}
+
+			s.unspent[key] = u
+			undo.created = append(undo.created, key)
+		}
+	}
+
+	return undo, nil
+}
+
+// checkCoinbase ensures a block solve reward only creates outputs. Its value
+// is bounded by the node, which knows the subsidy.
+func checkCoinbase(tx *pb.Tx) error {
+	if tx.GetValue() != 0 || tx.GetRecipient() != "" {
+		return ErrAccountFields
+	}
+
+	if len(tx.GetInputs()) > 0 {
+		return errors.New("block solve reward cannot spend inputs")
+	}
+
+	if len(tx.GetOutputs()) == 0 {
+		return ErrNoOutputs
+	}
+
+	if _, err := OutputsTotal(tx); err != nil {
+		return err
+	}
+
+	return checkOutputs(tx)
+}
+
+func (s *set) UndoBlock(undo *Undo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.undo(undo)
+}
+
+func (s *set) undo(undo *Undo) {
+	// Outputs both created and spent within the block are left deleted
+	created := make(map[string]bool, len(undo.created))
+	for _, key := range undo.created {
+		created[key] = true
+		delete(s.unspent, key)
+	}
+
+	for _, u := range undo.spent {
+		if key := Key(u.GetOutPoint()); !created[key] {
+			s.unspent[key] = u
+		}
+	}
+}
+
+func (s *set) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.unspent)
+}
@@ -0,0 +1,286 @@
+package utxo
+
+import (
+	"errors"
+	"testing"
+
+	pb "github.com/asgaines/blockchain/protogo/blockchain"
+)
+
+func coinbase(hash string, outs ...*pb.TxOut) *pb.Tx {
+	return &pb.Tx{
+		Hash:    []byte(hash),
+		Outputs: outs,
+	}
+}
+
+func out(recipient string, value uint64) *pb.TxOut {
+	return &pb.TxOut{
+		Recipient: recipient,
+		Value:     value,
+	}
+}
+
+func in(hash string, index uint32) *pb.OutPoint {
+	return &pb.OutPoint{
+		TxHash: []byte(hash),
+		Index:  index,
+	}
+}
+
+// funded returns a set in which Gob owns outputs of 50 and 30, and Buster one of
+// 20, all paid by the reward of the block at height 1 and spendable from height 2
+func funded(t *testing.T) Set {
+	s := New(1)
+
+	block := &pb.Block{
+		Txs: []*pb.Tx{
+			coinbase("reward", out("Gob", 50), out("Gob", 30), out("Buster", 20)),
+		},
+	}
+
+	if _, err := s.ApplyBlock(block, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestCheckTx(t *testing.T) {
+	cases := []struct {
+		name string
+		tx   *pb.Tx
+		err  error
+	}{
+		{
+			name: "Spending owned outputs into outputs plus fee is valid",
+			tx: &pb.Tx{
+				Sender:  "Gob",
+				Inputs:  []*pb.OutPoint{in("reward", 0), in("reward", 1)},
+				Outputs: []*pb.TxOut{out("Michael", 70), out("Gob", 5)},
+				Fee:     5,
+			},
+			err: nil,
+		},
+		{
+			name: "Spending nothing is invalid",
+			tx: &pb.Tx{
+				Sender:  "Gob",
+				Outputs: []*pb.TxOut{out("Michael", 70)},
+			},
+			err: ErrNoInputs,
+		},
+		{
+			name: "Creating nothing is invalid",
+			tx: &pb.Tx{
+				Sender: "Gob",
+				Inputs: []*pb.OutPoint{in("reward", 0)},
+				Fee:    50,
+			},
+			err: ErrNoOutputs,
+		},
+		{
+			name: "Setting the account value is invalid",
+			tx: &pb.Tx{
+				Sender:  "Gob",
+				Value:   50,
+				Inputs:  []*pb.OutPoint{in("reward", 0)},
+				Outputs: []*pb.TxOut{out("Michael", 50)},
+			},
+			err: ErrAccountFields,
+		},
+		{
+			name: "Spending an unknown output is invalid",
+			tx: &pb.Tx{
+				Sender:  "Gob",
+				Inputs:  []*pb.OutPoint{in("reward", 3)},
+				Outputs: []*pb.TxOut{out("Michael", 50)},
+			},
+			err: ErrMissingInput,
+		},
+		{
+			name: "Spending the same output twice is invalid",
+			tx: &pb.Tx{
+				Sender:  "Gob",
+				Inputs:  []*pb.OutPoint{in("reward", 0), in("reward", 0)},
+				Outputs: []*pb.TxOut{out("Michael", 100)},
+			},
+			err: ErrDuplicateInput,
+		},
+		{
+			name: "Spending an output of somebody else is invalid",
+			tx: &pb.Tx{
+				Sender:  "Gob",
+				Inputs:  []*pb.OutPoint{in("reward", 2)},
+				Outputs: []*pb.TxOut{out("Michael", 20)},
+			},
+			err: ErrNotOwner,
+		},
+		{
+			name: "Creating an output of no value is invalid",
+			tx: &pb.Tx{
+				Sender:  "Gob",
+				Inputs:  []*pb.OutPoint{in("reward", 0)},
+				Outputs: []*pb.TxOut{out("Michael", 50), out("Lucille", 0)},
+			},
+			err: ErrZeroOutput,
+		},
+		{
+			name: "Creating more than is spent is invalid",
+			tx: &pb.Tx{
+				Sender:  "Gob",
+				Inputs:  []*pb.OutPoint{in("reward", 0)},
+				Outputs: []*pb.TxOut{out("Michael", 50)},
+				Fee:     1,
+			},
+			err: ErrValueMismatch,
+		},
+		{
+			name: "Leaving spent value unclaimed is invalid",
+			tx: &pb.Tx{
+				Sender:  "Gob",
+				Inputs:  []*pb.OutPoint{in("reward", 0)},
+				Outputs: []*pb.TxOut{out("Michael", 40)},
+			},
+			err: ErrValueMismatch,
+		},
+		{
+			name: "Outputs overflowing when summed are invalid",
+			tx: &pb.Tx{
+				Sender:  "Gob",
+				Inputs:  []*pb.OutPoint{in("reward", 0)},
+				Outputs: []*pb.TxOut{out("Michael", ^uint64(0)), out("Lucille", 51)},
+			},
+			err: ErrOverflow,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := funded(t)
+
+			err := s.CheckTx(c.tx, 2)
+
+			if !errors.Is(err, c.err) {
+				t.Errorf("expected %v, got %v", c.err, err)
+			}
+
+			if s.Len() != 3 {
+				t.Errorf("expected set to be unchanged, got %d outputs", s.Len())
+			}
+		})
+	}
+}
+
+func TestApplyBlock(t *testing.T) {
+	s := funded(t)
+
+	spend := &pb.Tx{
+		Hash:    []byte("bluth"),
+		Sender:  "Gob",
+		Inputs:  []*pb.OutPoint{in("reward", 0)},
+		Outputs: []*pb.TxOut{out("Michael", 45), out("Gob", 4)},
+		Fee:     1,
+	}
+	// Outputs can be spent by a later tx of the same block
+	respend := &pb.Tx{
+		Hash:    []byte("cornballer"),
+		Sender:  "Michael",
+		Inputs:  []*pb.OutPoint{in("bluth", 0)},
+		Outputs: []*pb.TxOut{out("George Michael", 45)},
+	}
+
+	block := &pb.Block{
+		Txs: []*pb.Tx{coinbase("reward2", out("Michael", 1)), spend, respend},
+	}
+
+	undo, err := s.ApplyBlock(block, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	balances := map[string]uint64{
+		"Gob":            34,
+		"Michael":        1,
+		"Buster":         20,
+		"George Michael": 45,
+	}
+	for addr, expected := range balances {
+		if got := s.Balance(addr); got != expected {
+			t.Errorf("expected %s to own %d, got %d", addr, expected, got)
+		}
+	}
+
+	if _, ok := s.Get(in("reward", 0)); ok {
+		t.Errorf("expected spent output to be removed")
+	}
+
+	if _, err := s.ApplyBlock(&pb.Block{Txs: []*pb.Tx{spend}}, 3); !errors.Is(err, ErrMissingInput) {
+		t.Errorf("expected double spend in a later block to be refused, got %v", err)
+	}
+
+	s.UndoBlock(undo)
+
+	if s.Len() != 3 || s.Balance("Gob") != 80 || s.Balance("Michael") != 0 || s.Balance("George Michael") != 0 {
+		t.Errorf("expected undo to restore the set, got %v", s.ForAddress("Gob"))
+	}
+}
+
+func TestApplyBlockInvalid(t *testing.T) {
+	s := funded(t)
+
+	block := &pb.Block{
+		Txs: []*pb.Tx{
+			{
+				Hash:    []byte("bluth"),
+				Sender:  "Gob",
+				Inputs:  []*pb.OutPoint{in("reward", 0)},
+				Outputs: []*pb.TxOut{out("Michael", 50)},
+			},
+			{
+				Hash:    []byte("hop-ons"),
+				Sender:  "Gob",
+				Inputs:  []*pb.OutPoint{in("reward", 0)},
+				Outputs: []*pb.TxOut{out("Lindsay", 50)},
+			},
+		},
+	}
+
+	if _, err := s.ApplyBlock(block, 2); !errors.Is(err, ErrMissingInput) {
+		t.Errorf("expected output spent twice in a block to be refused, got %v", err)
+	}
+
+	if s.Len() != 3 || s.Balance("Gob") != 80 || s.Balance("Michael") != 0 {
+		t.Errorf("expected set to be unchanged by invalid block")
+	}
+}
+
+func TestCheckTxMaturity(t *testing.T) {
+	s := funded(t)
+
+	spend := &pb.Tx{
+		Sender:  "Gob",
+		Inputs:  []*pb.OutPoint{in("reward", 0)},
+		Outputs: []*pb.TxOut{out("Michael", 50)},
+	}
+
+	if err := s.CheckTx(spend, 1); !errors.Is(err, ErrImmature) {
+		t.Errorf("expected reward to be immature in its own block, got %v", err)
+	}
+
+	if err := s.CheckTx(spend, 2); err != nil {
+		t.Errorf("expected reward to be mature in the next block, got %v", err)
+	}
+
+	if u, ok := s.Get(in("reward", 0)); !ok || !u.GetCoinbase() {
+		t.Errorf("expected output of reward to be marked as such, got %v", u)
+	}
+
+	if _, err := s.ApplyBlock(&pb.Block{Txs: []*pb.Tx{{Hash: []byte("bluth"), Sender: "Gob", Inputs: spend.GetInputs(), Outputs: spend.GetOutputs()}}}, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if u, ok := s.Get(in("bluth", 0)); !ok || u.GetCoinbase() {
+		t.Errorf("expected output of tx not to be marked as a reward, got %v", u)
+	}
+}
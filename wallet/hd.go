@@ -0,0 +1,163 @@
+package wallet
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/asgaines/blockchain/address"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// HardenedOffset is added to an index to derive a hardened child. Ed25519
+// only supports hardened derivation, so every index is hardened.
+const HardenedOffset uint32 = 1 << 31
+
+// Purpose and CoinType are the first two levels of every derivation path,
+// m/Purpose'/CoinType'/account'/index', following the BIP44 layout
+const (
+	Purpose  uint32 = 44
+	CoinType uint32 = 20403
+)
+
+// mnemonicEntropyBits yields a 24 word phrase
+const mnemonicEntropyBits = 256
+
+// masterSecret is the HMAC key for the master node as defined by SLIP-0010
+var masterSecret = []byte("ed25519 seed")
+
+// ErrInvalidMnemonic is returned for phrases with unknown words or a bad checksum
+var ErrInvalidMnemonic = errors.New("invalid mnemonic")
+
+// NewMnemonic generates a fresh seed phrase
+func NewMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(mnemonicEntropyBits)
+	if err != nil {
+		return "", err
+	}
+
+	return bip39.NewMnemonic(entropy)
+}
+
+// ExtendedKey is a node in the derivation tree: a private key plus the chain
+// code needed to derive its children, as in BIP32
+type ExtendedKey struct {
+	key       []byte
+	chainCode []byte
+	depth     uint8
+}
+
+// FromMnemonic returns the master key of the wallet backed up by the phrase.
+// The passphrase is optional, and yields a different wallet for each value.
+func FromMnemonic(mnemonic string, passphrase string) (*ExtendedKey, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, ErrInvalidMnemonic
+	}
+
+	return NewMaster(bip39.NewSeed(mnemonic, passphrase))
+}
+
+// NewMaster derives the master key from a seed according to SLIP-0010
+func NewMaster(seed []byte) (*ExtendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("seed must be between 16 and 64 bytes, got %d", len(seed))
+	}
+
+	mac := hmac.New(sha512.New, masterSecret)
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	return &ExtendedKey{
+		key:       sum[:32],
+		chainCode: sum[32:],
+	}, nil
+}
+
+// Child derives the hardened child at index
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	if index >= HardenedOffset {
+		return nil, fmt.Errorf("index %d out of range, must be below %d", index, HardenedOffset)
+	}
+
+	if k.depth == 255 {
+		return nil, errors.New("maximum derivation depth reached")
+	}
+
+	data := make([]byte, 0, 1+32+4)
+	data = append(data, 0)
+	data = append(data, k.key...)
+	data = append(data, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[len(data)-4:], index+HardenedOffset)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	return &ExtendedKey{
+		key:       sum[:32],
+		chainCode: sum[32:],
+		depth:     k.depth + 1,
+	}, nil
+}
+
+// Derive walks a path of indexes down from the key
+func (k *ExtendedKey) Derive(path []uint32) (*ExtendedKey, error) {
+	var err error
+	for _, index := range path {
+		if k, err = k.Child(index); err != nil {
+			return nil, err
+		}
+	}
+
+	return k, nil
+}
+
+// PrivateKey returns the signing key held at this node
+func (k *ExtendedKey) PrivateKey() ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(k.key)
+}
+
+// Account derives the keys and addresses of one account of an HD wallet
+type Account struct {
+	key     *ExtendedKey
+	network *address.Network
+}
+
+// NewAccount derives the account at m/Purpose'/CoinType'/account' from the
+// master key
+func NewAccount(master *ExtendedKey, account uint32, net *address.Network) (*Account, error) {
+	key, err := master.Derive([]uint32{Purpose, CoinType, account})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Account{
+		key:     key,
+		network: net,
+	}, nil
+}
+
+// Key returns the private key at index within the account
+func (a *Account) Key(index uint32) (ed25519.PrivateKey, error) {
+	child, err := a.key.Child(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return child.PrivateKey(), nil
+}
+
+// Address returns the address at index within the account
+func (a *Account) Address(index uint32) (string, error) {
+	priv, err := a.Key(index)
+	if err != nil {
+		return "", err
+	}
+
+	return address.FromPubkey(priv.Public().(ed25519.PublicKey), a.network), nil
+}
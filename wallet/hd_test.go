@@ -0,0 +1,140 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/asgaines/blockchain/address"
+)
+
+func TestDerive(t *testing.T) {
+	// Test vector 1 for ed25519 from SLIP-0010
+	seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name      string
+		path      []uint32
+		key       string
+		chainCode string
+	}{
+		{
+			name:      "Master key",
+			path:      []uint32{},
+			key:       "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+			chainCode: "90046a93de5380a72b5e45010748567d5ea02bbf6522f979e05c0d8d8ca9fffb",
+		},
+		{
+			name:      "m/0'",
+			path:      []uint32{0},
+			key:       "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3",
+			chainCode: "8b59aa11380b624e81507a27fedda59fea6d0b779a778918a2fd3590e16e9c69",
+		},
+		{
+			name:      "m/0'/1'",
+			path:      []uint32{0, 1},
+			key:       "b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2",
+			chainCode: "a320425f77d1b5c2505a6b1b27382b37368ee640e3557c315416801243552f14",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			master, err := NewMaster(seed)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := master.Derive(c.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if hex.EncodeToString(got.key) != c.key {
+				t.Errorf("expected key %s, got %x", c.key, got.key)
+			}
+
+			if hex.EncodeToString(got.chainCode) != c.chainCode {
+				t.Errorf("expected chain code %s, got %x", c.chainCode, got.chainCode)
+			}
+		})
+	}
+}
+
+func TestFromMnemonic(t *testing.T) {
+	mnemonic, err := NewMnemonic()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := FromMnemonic(mnemonic, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Recovery tolerates stray whitespace from copying the phrase around
+	recovered, err := FromMnemonic(" "+mnemonic+"\n", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a1, err := NewAccount(first, 0, address.Mainnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a2, err := NewAccount(recovered, 0, address.Mainnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := uint32(0); i < 3; i++ {
+		addr1, err := a1.Address(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		addr2, err := a2.Address(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if addr1 != addr2 {
+			t.Errorf("index %d: expected recovered address %s, got %s", i, addr1, addr2)
+		}
+	}
+
+	if _, err := FromMnemonic("banana stand "+mnemonic, ""); !errors.Is(err, ErrInvalidMnemonic) {
+		t.Errorf("expected %v, got %v", ErrInvalidMnemonic, err)
+	}
+}
+
+func TestAccountAddressesDiffer(t *testing.T) {
+	master, err := NewMaster(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[string]bool)
+	for account := uint32(0); account < 2; account++ {
+		a, err := NewAccount(master, account, address.Mainnet)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := uint32(0); i < 3; i++ {
+			addr, err := a.Address(i)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if seen[addr] {
+				t.Errorf("account %d index %d repeats address %s", account, i, addr)
+			}
+			seen[addr] = true
+		}
+	}
+}